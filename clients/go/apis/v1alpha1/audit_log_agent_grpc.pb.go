@@ -19,6 +19,10 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AuditLogAgentClient interface {
 	ProcessLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error)
+	// Processes a batch of audit log requests in a single round trip. A failure
+	// of an individual audit log request is reported in its result, while an
+	// RPC error applies to the whole batch.
+	ProcessLogs(ctx context.Context, in *ProcessLogsRequest, opts ...grpc.CallOption) (*ProcessLogsResponse, error)
 }
 
 type auditLogAgentClient struct {
@@ -38,11 +42,24 @@ func (c *auditLogAgentClient) ProcessLog(ctx context.Context, in *AuditLogReques
 	return out, nil
 }
 
+func (c *auditLogAgentClient) ProcessLogs(ctx context.Context, in *ProcessLogsRequest, opts ...grpc.CallOption) (*ProcessLogsResponse, error) {
+	out := new(ProcessLogsResponse)
+	err := c.cc.Invoke(ctx, "/abcxyz.lumberjack.AuditLogAgent/ProcessLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuditLogAgentServer is the server API for AuditLogAgent service.
 // All implementations must embed UnimplementedAuditLogAgentServer
 // for forward compatibility
 type AuditLogAgentServer interface {
 	ProcessLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
+	// Processes a batch of audit log requests in a single round trip. A failure
+	// of an individual audit log request is reported in its result, while an
+	// RPC error applies to the whole batch.
+	ProcessLogs(context.Context, *ProcessLogsRequest) (*ProcessLogsResponse, error)
 	mustEmbedUnimplementedAuditLogAgentServer()
 }
 
@@ -53,6 +70,9 @@ type UnimplementedAuditLogAgentServer struct {
 func (UnimplementedAuditLogAgentServer) ProcessLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessLog not implemented")
 }
+func (UnimplementedAuditLogAgentServer) ProcessLogs(context.Context, *ProcessLogsRequest) (*ProcessLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessLogs not implemented")
+}
 func (UnimplementedAuditLogAgentServer) mustEmbedUnimplementedAuditLogAgentServer() {}
 
 // UnsafeAuditLogAgentServer may be embedded to opt out of forward compatibility for this service.
@@ -84,6 +104,24 @@ func _AuditLogAgent_ProcessLog_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuditLogAgent_ProcessLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditLogAgentServer).ProcessLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/abcxyz.lumberjack.AuditLogAgent/ProcessLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditLogAgentServer).ProcessLogs(ctx, req.(*ProcessLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AuditLogAgent_ServiceDesc is the grpc.ServiceDesc for AuditLogAgent service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -95,6 +133,10 @@ var AuditLogAgent_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessLog",
 			Handler:    _AuditLogAgent_ProcessLog_Handler,
 		},
+		{
+			MethodName: "ProcessLogs",
+			Handler:    _AuditLogAgent_ProcessLogs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "audit_log_agent.proto",
@@ -14,7 +14,29 @@
 
 package v1alpha1
 
+import "google.golang.org/protobuf/reflect/protoreflect"
+
 // ShouldFailClose returns whether we should fail close on errors.
 func ShouldFailClose(logMode AuditLogRequest_LogMode) bool {
 	return logMode == AuditLogRequest_FAIL_CLOSE
 }
+
+// LogName obtains the Cloud Logging LogName by reading the proto annotation
+// of the AuditLogRequest.Type. It returns an empty string if the proto
+// annotation is missing, e.g. for an unknown log type.
+func LogName(t AuditLogRequest_LogType) string {
+	v := t.Descriptor().Values().ByNumber(t.Number())
+	if v == nil {
+		return ""
+	}
+	enumOpts := v.Options().ProtoReflect()
+	var logName string
+	enumOpts.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Name() == "log_name" {
+			logName = v.String()
+			return false
+		}
+		return true
+	})
+	return logName
+}
@@ -54,3 +54,39 @@ func TestShouldFailClose(t *testing.T) {
 		})
 	}
 }
+
+func TestLogName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		logType AuditLogRequest_LogType
+		want    string
+	}{
+		{
+			name:    "admin_activity",
+			logType: AuditLogRequest_ADMIN_ACTIVITY,
+			want:    "audit.abcxyz/activity",
+		},
+		{
+			name:    "data_access",
+			logType: AuditLogRequest_DATA_ACCESS,
+			want:    "audit.abcxyz/data_access",
+		},
+		{
+			name:    "unknown_log_type",
+			logType: AuditLogRequest_LogType(1000),
+			want:    "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := LogName(tc.logType); got != tc.want {
+				t.Errorf("LogName(%v) got %q, want %q", tc.logType, got, tc.want)
+			}
+		})
+	}
+}
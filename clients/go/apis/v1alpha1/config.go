@@ -17,7 +17,12 @@ package v1alpha1
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -28,6 +33,25 @@ const (
 	AuditRuleDirectiveDefault            = "AUDIT"
 	AuditRuleDirectiveRequestOnly        = "AUDIT_REQUEST_ONLY"
 	AuditRuleDirectiveRequestAndResponse = "AUDIT_REQUEST_AND_RESPONSE"
+	AuditRuleDirectiveStreamSummary      = "AUDIT_STREAM_SUMMARY"
+
+	// Sampling key options.
+	SamplingKeyRandom      = "RANDOM"
+	SamplingKeyPrincipal   = "PRINCIPAL"
+	SamplingKeyOperationID = "OPERATION_ID"
+
+	// OTLP backend protocol options.
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+
+	// Syslog backend network options.
+	SyslogNetworkUDP = "udp"
+	SyslogNetworkTCP = "tcp"
+	SyslogNetworkTLS = "tls"
+
+	// Remote backend load balancing policy options.
+	RemotePolicyPriorityFailover = "priority_failover"
+	RemotePolicyRoundRobin       = "round_robin"
 )
 
 // Config is the full audit client config.
@@ -36,7 +60,7 @@ type Config struct {
 	Version string `yaml:"version,omitempty" env:"VERSION,overwrite"`
 
 	// Backend specifies what remote backend to send audit logs to.
-	// If a remote backend config is nil, audit logs will be written to stdout.
+	// If no backend is set, audit logs will be written to stdout.
 	Backend *Backend `yaml:"backend,omitempty" env:",noinit"`
 
 	// Condition specifies the condition under which an incoming request should be
@@ -55,6 +79,12 @@ type Config struct {
 	// When auto audit logging is not used, setting this field has no effect.
 	Rules []*AuditRule `yaml:"rules,omitempty"`
 
+	// ClientRules specifies audit logging instructions per matching outbound
+	// gRPC method, for the calls that the service makes to other services,
+	// e.g. on behalf of its users. If empty, no outbound calls are audited.
+	// This config is only used for auto audit logging.
+	ClientRules []*AuditRule `yaml:"client_rules,omitempty"`
+
 	// Labels are additional labels that the calling code wants added to each
 	// audit log request. Each label will only be added if it is not already added
 	// in the audit log, and will not overwrite explicitly added labels.
@@ -67,6 +97,10 @@ type Config struct {
 
 	// Justification specifies the config used to integrate with JVS.
 	Justification *Justification `yaml:"justification,omitempty" env:",noinit"`
+
+	// Sampling specifies the fraction of audit log requests to keep, to reduce
+	// the volume of high-traffic methods. If nil, all requests are kept.
+	Sampling *Sampling `yaml:"sampling,omitempty" env:",noinit"`
 }
 
 // Validate checks if the config is valid.
@@ -96,6 +130,11 @@ func (cfg *Config) Validate() error {
 			merr = errors.Join(merr, err)
 		}
 	}
+	for _, r := range cfg.ClientRules {
+		if err := r.Validate(); err != nil {
+			merr = errors.Join(merr, fmt.Errorf("client rule: %w", err))
+		}
+	}
 
 	if cfg.LogMode != "" {
 		if _, ok := AuditLogRequest_LogMode_value[strings.ToUpper(cfg.LogMode)]; !ok {
@@ -109,6 +148,12 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Sampling != nil {
+		if err := cfg.Sampling.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
 	return merr
 }
 
@@ -123,16 +168,23 @@ func (cfg *Config) SetDefault() {
 		cfg.LogMode = AuditLogRequest_FAIL_CLOSE.String()
 	}
 
-	// TODO(#74): set default backend to logging to stdout.
-	if cfg.Backend != nil {
-		cfg.Backend.SetDefault()
+	if cfg.Backend == nil {
+		cfg.Backend = &Backend{}
 	}
+	cfg.Backend.SetDefault()
 
 	// TODO: set defaults for SecurityContext and Condition
 	// once we have any such logic.
 	for _, r := range cfg.Rules {
 		r.SetDefault()
 	}
+	for _, r := range cfg.ClientRules {
+		r.SetDefault()
+	}
+
+	if cfg.Sampling != nil {
+		cfg.Sampling.SetDefault()
+	}
 }
 
 // GetLogMode converts the LogMode string to a AuditLogRequest_LogMode.
@@ -144,13 +196,52 @@ func (cfg *Config) GetLogMode() AuditLogRequest_LogMode {
 type Backend struct {
 	Remote       *Remote       `yaml:"remote,omitempty" env:",noinit"`
 	CloudLogging *CloudLogging `yaml:"cloudlogging,omitempty" env:",noinit"`
+	Stdout       *Stdout       `yaml:"stdout,omitempty" env:",noinit"`
+	File         *File         `yaml:"file,omitempty" env:",noinit"`
+	Webhook      *Webhook      `yaml:"webhook,omitempty" env:",noinit"`
+	OTLP         *OTLP         `yaml:"otlp,omitempty" env:",noinit"`
+	Syslog       *Syslog       `yaml:"syslog,omitempty" env:",noinit"`
+	PubSub       *PubSub       `yaml:"pubsub,omitempty" env:",noinit"`
+
+	// Retry specifies how to retry transient failures of each backend.
+	// If nil, failed log requests are not retried.
+	Retry *Retry `yaml:"retry,omitempty" env:",noinit"`
+
+	// CircuitBreaker specifies when to stop calling a failing backend.
+	// If nil, backends are always called. Each backend has its own breaker.
+	CircuitBreaker *CircuitBreaker `yaml:"circuit_breaker,omitempty" env:",noinit"`
 }
 
-// SetDefault sets default for the Backend.
+// SetDefault sets default for the Backend. If no backend is set, audit logs
+// are written to stdout.
 func (b *Backend) SetDefault() {
+	if b.Remote == nil && b.CloudLogging == nil && b.Stdout == nil && b.File == nil && b.Webhook == nil && b.OTLP == nil && b.Syslog == nil && b.PubSub == nil {
+		b.Stdout = &Stdout{}
+	}
+	if b.Remote != nil {
+		b.Remote.SetDefault()
+	}
 	if b.CloudLogging != nil {
 		b.CloudLogging.SetDefault()
 	}
+	if b.File != nil {
+		b.File.SetDefault()
+	}
+	if b.Webhook != nil {
+		b.Webhook.SetDefault()
+	}
+	if b.OTLP != nil {
+		b.OTLP.SetDefault()
+	}
+	if b.Syslog != nil {
+		b.Syslog.SetDefault()
+	}
+	if b.Retry != nil {
+		b.Retry.SetDefault()
+	}
+	if b.CircuitBreaker != nil {
+		b.CircuitBreaker.SetDefault()
+	}
 }
 
 // Validate validates the Backend.
@@ -172,19 +263,167 @@ func (b *Backend) Validate() error {
 		}
 	}
 
+	if b.Stdout != nil {
+		backendSet = true
+	}
+
+	if b.File != nil {
+		backendSet = true
+		if err := b.File.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
+	if b.Webhook != nil {
+		backendSet = true
+		if err := b.Webhook.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
+	if b.OTLP != nil {
+		backendSet = true
+		if err := b.OTLP.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
+	if b.Syslog != nil {
+		backendSet = true
+		if err := b.Syslog.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
+	if b.PubSub != nil {
+		backendSet = true
+		if err := b.PubSub.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
 	if !backendSet {
 		merr = errors.Join(merr, fmt.Errorf("no backend is set"))
 	}
 
+	if b.Retry != nil {
+		if err := b.Retry.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
+	if b.CircuitBreaker != nil {
+		if err := b.CircuitBreaker.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+
+	return merr
+}
+
+// Retry specifies the exponential backoff used to retry backend failures
+// with a retryable gRPC code, e.g. UNAVAILABLE.
+type Retry struct {
+	// MaxAttempts is how many times a log request is sent to a backend,
+	// including the first attempt. The default is 3.
+	MaxAttempts uint64 `yaml:"max_attempts,omitempty" env:"BACKEND_RETRY_MAX_ATTEMPTS,overwrite"`
+
+	// InitialBackoff is the delay before the first retry. The delay doubles
+	// after every retry. The default is 100ms.
+	InitialBackoff time.Duration `yaml:"initial_backoff,omitempty" env:"BACKEND_RETRY_INITIAL_BACKOFF,overwrite"`
+
+	// MaxBackoff caps the delay between retries. The default is 2s.
+	MaxBackoff time.Duration `yaml:"max_backoff,omitempty" env:"BACKEND_RETRY_MAX_BACKOFF,overwrite"`
+
+	// JitterPercent randomly varies each delay by up to this percentage.
+	// The default is 10.
+	JitterPercent uint64 `yaml:"jitter_percent,omitempty" env:"BACKEND_RETRY_JITTER_PERCENT,overwrite"`
+}
+
+// SetDefault sets default for the Retry.
+func (r *Retry) SetDefault() {
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = 3
+	}
+	if r.InitialBackoff == 0 {
+		r.InitialBackoff = 100 * time.Millisecond
+	}
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = max(2*time.Second, r.InitialBackoff)
+	}
+	if r.JitterPercent == 0 {
+		r.JitterPercent = 10
+	}
+}
+
+// Validate validates the Retry.
+func (r *Retry) Validate() error {
+	var merr error
+	if r.InitialBackoff < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend retry initial_backoff must not be negative"))
+	}
+	if r.MaxBackoff < r.InitialBackoff {
+		merr = errors.Join(merr, fmt.Errorf("backend retry max_backoff %s is less than initial_backoff %s", r.MaxBackoff, r.InitialBackoff))
+	}
+	if r.JitterPercent > 100 {
+		merr = errors.Join(merr, fmt.Errorf("backend retry jitter_percent must be at most 100"))
+	}
 	return merr
 }
 
+// CircuitBreaker specifies a circuit breaker that stops calling a backend
+// after consecutive failures, and lets a trial log request through after the
+// open timeout.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed log requests that
+	// opens the breaker. The default is 5.
+	FailureThreshold uint64 `yaml:"failure_threshold,omitempty" env:"BACKEND_CIRCUIT_BREAKER_FAILURE_THRESHOLD,overwrite"`
+
+	// OpenTimeout is how long the breaker stays open before half-opening.
+	// The default is 30s.
+	OpenTimeout time.Duration `yaml:"open_timeout,omitempty" env:"BACKEND_CIRCUIT_BREAKER_OPEN_TIMEOUT,overwrite"`
+}
+
+// SetDefault sets default for the CircuitBreaker.
+func (cb *CircuitBreaker) SetDefault() {
+	if cb.FailureThreshold == 0 {
+		cb.FailureThreshold = 5
+	}
+	if cb.OpenTimeout == 0 {
+		cb.OpenTimeout = 30 * time.Second
+	}
+}
+
+// Validate validates the CircuitBreaker.
+func (cb *CircuitBreaker) Validate() error {
+	if cb.OpenTimeout < 0 {
+		return fmt.Errorf("backend circuit_breaker open_timeout must not be negative")
+	}
+	return nil
+}
+
 // Remote is the remote backend service to send audit logs to.
 // The backend must be a gRPC service that implements protos/v1alpha1/audit_log_agent.proto.
 type Remote struct {
-	// Address is the remote backend address. It must be set.
+	// Address is the remote backend address, either "host:port" or a gRPC
+	// resolver target such as "dns:///agent.example.com:443". Address or
+	// Addresses must be set.
 	Address string `yaml:"address,omitempty" env:"BACKEND_REMOTE_ADDRESS,overwrite"`
 
+	// Addresses are more remote backend addresses, after Address if set, e.g.
+	// the agents of other regions to fail over to.
+	Addresses []string `yaml:"addresses,omitempty" env:"BACKEND_REMOTE_ADDRESSES,overwrite"`
+
+	// LoadBalancingPolicy is how audit logs are spread over the addresses,
+	// and over the backends of a resolver target: "priority_failover" (the
+	// default) sends them to the first healthy address, "round_robin" spreads
+	// them evenly over the healthy addresses.
+	LoadBalancingPolicy string `yaml:"load_balancing_policy,omitempty" env:"BACKEND_REMOTE_LOAD_BALANCING_POLICY,overwrite"`
+
+	// EjectionDuration is how long an unavailable address is skipped. The
+	// default is 30s.
+	EjectionDuration time.Duration `yaml:"ejection_duration,omitempty" env:"BACKEND_REMOTE_EJECTION_DURATION,overwrite"`
+
 	// InsecureEnabled indicates whether to insecurely connect to the backend.
 	// This should be set to false for production usage.
 	InsecureEnabled bool `yaml:"insecure_enabled,omitempty" env:"BACKEND_REMOTE_INSECURE_ENABLED,overwrite"`
@@ -192,14 +431,72 @@ type Remote struct {
 	// ImpersonateAccount specifies which service account to impersonate to call the backend.
 	// If empty, there will be no impersonation.
 	ImpersonateAccount string `yaml:"impersonate_account,omitempty" env:"BACKEND_REMOTE_IMPERSONATE_ACCOUNT,overwrite"`
+
+	// IDTokenDisabled disables the ID token auth, e.g. when the backend
+	// authenticates clients with their TLS certificate instead.
+	IDTokenDisabled bool `yaml:"id_token_disabled,omitempty" env:"BACKEND_REMOTE_ID_TOKEN_DISABLED,overwrite"`
+
+	// CAFile is the PEM file of the CA certificates to verify the backend
+	// with. If empty, the system cert pool is used.
+	CAFile string `yaml:"ca_file,omitempty" env:"BACKEND_REMOTE_CA_FILE,overwrite"`
+
+	// CertFile and KeyFile are the PEM files of the client certificate and
+	// key for mutual TLS. Both or neither must be set.
+	CertFile string `yaml:"cert_file,omitempty" env:"BACKEND_REMOTE_CERT_FILE,overwrite"`
+	KeyFile  string `yaml:"key_file,omitempty" env:"BACKEND_REMOTE_KEY_FILE,overwrite"`
+
+	// ServerName overrides the server name used for SNI and to verify the
+	// backend certificate. If empty, it's the host of the address.
+	ServerName string `yaml:"server_name,omitempty" env:"BACKEND_REMOTE_SERVER_NAME,overwrite"`
+
+	// MaxBatchSize is the maximum number of audit logs sent in a single call
+	// to the backend. If greater than 1, concurrent audit logs are batched.
+	// By default, audit logs are not batched.
+	MaxBatchSize uint64 `yaml:"max_batch_size,omitempty" env:"BACKEND_REMOTE_MAX_BATCH_SIZE,overwrite"`
+
+	// BatchDelay is how long a batch waits for more audit logs before it's
+	// sent. The default is 100ms when batching.
+	BatchDelay time.Duration `yaml:"batch_delay,omitempty" env:"BACKEND_REMOTE_BATCH_DELAY,overwrite"`
+}
+
+// SetDefault sets default for the Remote.
+func (b *Remote) SetDefault() {
+	if b.MaxBatchSize > 1 && b.BatchDelay == 0 {
+		b.BatchDelay = 100 * time.Millisecond
+	}
 }
 
 // Validate validates the backend.
 func (b *Remote) Validate() error {
-	if b.Address == "" {
-		return fmt.Errorf("backend address is nil")
+	var merr error
+	if b.Address == "" && len(b.Addresses) == 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend address is nil"))
 	}
-	return nil
+	if slices.Contains(b.Addresses, "") {
+		merr = errors.Join(merr, fmt.Errorf("backend remote addresses must not be empty"))
+	}
+	switch b.LoadBalancingPolicy {
+	case "", RemotePolicyPriorityFailover, RemotePolicyRoundRobin:
+	default:
+		merr = errors.Join(merr, fmt.Errorf("backend remote load_balancing_policy %q must be one of %q, %q",
+			b.LoadBalancingPolicy, RemotePolicyPriorityFailover, RemotePolicyRoundRobin))
+	}
+	if b.EjectionDuration < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend remote ejection_duration must not be negative"))
+	}
+	if b.BatchDelay < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend remote batch_delay must not be negative"))
+	}
+	if (b.CertFile == "") != (b.KeyFile == "") {
+		merr = errors.Join(merr, fmt.Errorf("backend remote cert_file and key_file must be set together"))
+	}
+	if b.InsecureEnabled && (b.CAFile != "" || b.CertFile != "" || b.ServerName != "") {
+		merr = errors.Join(merr, fmt.Errorf("backend remote TLS settings can't be used with insecure_enabled"))
+	}
+	if b.IDTokenDisabled && b.ImpersonateAccount != "" {
+		merr = errors.Join(merr, fmt.Errorf("backend remote impersonate_account can't be used with id_token_disabled"))
+	}
+	return merr
 }
 
 // CloudLogging is the GCP cloud logging backend to send audit logs to.
@@ -210,6 +507,40 @@ type CloudLogging struct {
 	// Project allows overriding the project where to send the audit logs.
 	// The client must be run with a service account that has log writer role on the project.
 	Project string `yaml:"project,omitempty" env:"BACKEND_CLOUDLOGGING_PROJECT,overwrite"`
+
+	// LogNamePrefix replaces the "audit.abcxyz/" prefix of the default log
+	// names, e.g. "audit.example.com/" for "audit.example.com/data_access".
+	LogNamePrefix string `yaml:"log_name_prefix,omitempty" env:"BACKEND_CLOUDLOGGING_LOG_NAME_PREFIX,overwrite"`
+
+	// LogNames maps log types, e.g. "DATA_ACCESS", to the log names to use
+	// instead of the default ones.
+	LogNames map[string]string `yaml:"log_names,omitempty" env:"BACKEND_CLOUDLOGGING_LOG_NAMES,overwrite"`
+
+	// Routes send the matching audit logs to another project or log name.
+	// The first matching route applies.
+	Routes []*CloudLoggingRoute `yaml:"routes,omitempty"`
+}
+
+// CloudLoggingRoute sends the matching audit logs to another project or log
+// name. An audit log matches if it matches all the set criteria.
+type CloudLoggingRoute struct {
+	// Labels matches the audit logs with all of these labels.
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// ServiceName matches the audit logs of this service.
+	ServiceName string `yaml:"service_name,omitempty"`
+
+	// LogTypes matches the audit logs of any of these log types, e.g.
+	// "DATA_ACCESS".
+	LogTypes []string `yaml:"log_types,omitempty"`
+
+	// Project is the project to send the matching audit logs to. If empty,
+	// it's the project of the backend.
+	Project string `yaml:"project,omitempty"`
+
+	// LogName is the log name of the matching audit logs. If empty, it's the
+	// log name of their log type.
+	LogName string `yaml:"log_name,omitempty"`
 }
 
 // SetDefault sets default on the CloudLogging backend.
@@ -221,11 +552,269 @@ func (cl *CloudLogging) SetDefault() {
 
 // Validate validates the CloudLogging backend.
 func (cl *CloudLogging) Validate() error {
+	var merr error
 	if cl.Project == "" && !cl.DefaultProject {
-		return fmt.Errorf("backend cloudlogging no project or using default project is set")
+		merr = errors.Join(merr, fmt.Errorf("backend cloudlogging no project or using default project is set"))
 	}
 	if cl.Project != "" && cl.DefaultProject {
-		return fmt.Errorf("backend cloudlogging project is set while using default project")
+		merr = errors.Join(merr, fmt.Errorf("backend cloudlogging project is set while using default project"))
+	}
+	for _, logType := range slices.Sorted(maps.Keys(cl.LogNames)) {
+		if _, ok := AuditLogRequest_LogType_value[logType]; !ok {
+			merr = errors.Join(merr, fmt.Errorf("backend cloudlogging log_names has unexpected log type %q", logType))
+		}
+		if cl.LogNames[logType] == "" {
+			merr = errors.Join(merr, fmt.Errorf("backend cloudlogging log_names has empty log name for %q", logType))
+		}
+	}
+	for i, r := range cl.Routes {
+		if r.Project == "" && r.LogName == "" {
+			merr = errors.Join(merr, fmt.Errorf("backend cloudlogging route %d must set a project or a log_name", i))
+		}
+		for _, logType := range r.LogTypes {
+			if _, ok := AuditLogRequest_LogType_value[logType]; !ok {
+				merr = errors.Join(merr, fmt.Errorf("backend cloudlogging route %d has unexpected log type %q", i, logType))
+			}
+		}
+	}
+	return merr
+}
+
+// Stdout is the backend writing audit logs as JSON lines in the Cloud Logging
+// LogEntry shape, e.g. for the Cloud Run or GKE logging agents to collect.
+type Stdout struct {
+	// Stderr indicates whether to write to stderr instead of stdout.
+	Stderr bool `yaml:"stderr,omitempty" env:"BACKEND_STDOUT_STDERR,overwrite"`
+}
+
+// File is the backend writing audit logs to local files as JSON lines in the
+// Cloud Logging LogEntry shape, e.g. for a SIEM forwarder to ship.
+type File struct {
+	// Path is the path of the file to write to.
+	Path string `yaml:"path,omitempty" env:"BACKEND_FILE_PATH,overwrite"`
+
+	// MaxSizeBytes is the size after which the file is rotated. Zero disables
+	// size based rotation. The default is 100MiB.
+	MaxSizeBytes int64 `yaml:"max_size_bytes,omitempty" env:"BACKEND_FILE_MAX_SIZE_BYTES,overwrite"`
+
+	// RotationInterval is how long a file is written to before it's rotated.
+	// Zero, the default, disables time based rotation.
+	RotationInterval time.Duration `yaml:"rotation_interval,omitempty" env:"BACKEND_FILE_ROTATION_INTERVAL,overwrite"`
+
+	// Compress indicates whether to gzip the rotated files.
+	Compress bool `yaml:"compress,omitempty" env:"BACKEND_FILE_COMPRESS,overwrite"`
+
+	// Permissions are the octal permissions of the files, e.g. "0640". The
+	// default is "0600".
+	Permissions string `yaml:"permissions,omitempty" env:"BACKEND_FILE_PERMISSIONS,overwrite"`
+}
+
+// SetDefault sets default for the File.
+func (f *File) SetDefault() {
+	if f.MaxSizeBytes == 0 {
+		f.MaxSizeBytes = 100 << 20
+	}
+	if f.Permissions == "" {
+		f.Permissions = "0600"
+	}
+}
+
+// Validate validates the File.
+func (f *File) Validate() error {
+	var merr error
+	if f.Path == "" {
+		merr = errors.Join(merr, fmt.Errorf("backend file path is not set"))
+	}
+	if f.MaxSizeBytes < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend file max_size_bytes must not be negative"))
+	}
+	if f.RotationInterval < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend file rotation_interval must not be negative"))
+	}
+	if m, err := strconv.ParseUint(f.Permissions, 8, 32); err != nil || m > 0o777 {
+		merr = errors.Join(merr, fmt.Errorf("backend file permissions %q must be octal permission bits, e.g. \"0600\"", f.Permissions))
+	}
+	return merr
+}
+
+// GetPermissions converts the Permissions string to a file mode.
+func (f *File) GetPermissions() os.FileMode {
+	m, _ := strconv.ParseUint(f.Permissions, 8, 32)
+	return os.FileMode(m) //nolint:gosec // Bounded by Validate.
+}
+
+// Webhook is the backend sending audit logs to an HTTPS endpoint as a JSON
+// array of Cloud Logging LogEntry objects.
+type Webhook struct {
+	// URL is the HTTPS URL to POST audit logs to.
+	URL string `yaml:"url,omitempty" env:"BACKEND_WEBHOOK_URL,overwrite"`
+
+	// Headers are added to every webhook request, e.g. for authorization.
+	Headers map[string]string `yaml:"headers,omitempty" env:"BACKEND_WEBHOOK_HEADERS,overwrite"`
+
+	// HMACSecret, if set, signs every webhook request with HMAC-SHA256.
+	HMACSecret string `yaml:"hmac_secret,omitempty" env:"BACKEND_WEBHOOK_HMAC_SECRET,overwrite"`
+
+	// Timeout is the timeout of a webhook request. The default is 10s.
+	Timeout time.Duration `yaml:"timeout,omitempty" env:"BACKEND_WEBHOOK_TIMEOUT,overwrite"`
+
+	// MaxBatchSize is the maximum number of audit logs sent in a single
+	// webhook request. If greater than 1, concurrent audit logs are batched.
+	// The default is 1.
+	MaxBatchSize uint64 `yaml:"max_batch_size,omitempty" env:"BACKEND_WEBHOOK_MAX_BATCH_SIZE,overwrite"`
+
+	// BatchDelay is how long a batch waits for more audit logs before it's
+	// sent. The default is 100ms.
+	BatchDelay time.Duration `yaml:"batch_delay,omitempty" env:"BACKEND_WEBHOOK_BATCH_DELAY,overwrite"`
+
+	// InsecureEnabled allows a plain HTTP URL. This should only be used for
+	// testing.
+	InsecureEnabled bool `yaml:"insecure_enabled,omitempty" env:"BACKEND_WEBHOOK_INSECURE_ENABLED,overwrite"`
+}
+
+// SetDefault sets default for the Webhook.
+func (w *Webhook) SetDefault() {
+	if w.Timeout == 0 {
+		w.Timeout = 10 * time.Second
+	}
+	if w.MaxBatchSize == 0 {
+		w.MaxBatchSize = 1
+	}
+	if w.BatchDelay == 0 {
+		w.BatchDelay = 100 * time.Millisecond
+	}
+}
+
+// Validate validates the Webhook.
+func (w *Webhook) Validate() error {
+	var merr error
+	if w.URL == "" {
+		merr = errors.Join(merr, fmt.Errorf("backend webhook url is not set"))
+	}
+	if w.Timeout < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend webhook timeout must not be negative"))
+	}
+	if w.BatchDelay < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend webhook batch_delay must not be negative"))
+	}
+	return merr
+}
+
+// OTLP is the backend exporting audit logs as OpenTelemetry log records over
+// OTLP, e.g. to an OpenTelemetry Collector.
+type OTLP struct {
+	// Protocol is the OTLP transport protocol, "grpc" or "http". The default
+	// is "grpc".
+	Protocol string `yaml:"protocol,omitempty" env:"BACKEND_OTLP_PROTOCOL,overwrite"`
+
+	// Endpoint is the URL of the OTLP endpoint, e.g. "http://localhost:4317".
+	// If not set, the standard OTEL_EXPORTER_OTLP_* environment variables
+	// apply.
+	Endpoint string `yaml:"endpoint,omitempty" env:"BACKEND_OTLP_ENDPOINT,overwrite"`
+
+	// Headers are added to every export request, e.g. for authorization.
+	Headers map[string]string `yaml:"headers,omitempty" env:"BACKEND_OTLP_HEADERS,overwrite"`
+
+	// Timeout is the timeout of an export request.
+	Timeout time.Duration `yaml:"timeout,omitempty" env:"BACKEND_OTLP_TIMEOUT,overwrite"`
+}
+
+// SetDefault sets default for the OTLP.
+func (o *OTLP) SetDefault() {
+	if o.Protocol == "" {
+		o.Protocol = OTLPProtocolGRPC
+	}
+}
+
+// Validate validates the OTLP.
+func (o *OTLP) Validate() error {
+	var merr error
+	if o.Protocol != OTLPProtocolGRPC && o.Protocol != OTLPProtocolHTTP {
+		merr = errors.Join(merr, fmt.Errorf("unexpected backend otlp protocol %q want %q or %q", o.Protocol, OTLPProtocolGRPC, OTLPProtocolHTTP))
+	}
+	if o.Timeout < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend otlp timeout must not be negative"))
+	}
+	return merr
+}
+
+// Syslog is the backend sending audit logs as RFC 5424 syslog messages.
+type Syslog struct {
+	// Network is the transport, "udp", "tcp" or "tls". The default is "udp".
+	Network string `yaml:"network,omitempty" env:"BACKEND_SYSLOG_NETWORK,overwrite"`
+
+	// Address is the "host:port" address of the syslog server.
+	Address string `yaml:"address,omitempty" env:"BACKEND_SYSLOG_ADDRESS,overwrite"`
+
+	// Facility is the syslog facility name, e.g. "local0". The default is
+	// "auth".
+	Facility string `yaml:"facility,omitempty" env:"BACKEND_SYSLOG_FACILITY,overwrite"`
+
+	// AppName is the APP-NAME of the messages. The default is "lumberjack".
+	AppName string `yaml:"app_name,omitempty" env:"BACKEND_SYSLOG_APP_NAME,overwrite"`
+
+	// CAFile is the path of a PEM file with the CA certificates to trust for
+	// the "tls" network. If not set, the system roots are trusted.
+	CAFile string `yaml:"ca_file,omitempty" env:"BACKEND_SYSLOG_CA_FILE,overwrite"`
+
+	// Timeout is the timeout of connecting and of sending a message. The
+	// default is 10s.
+	Timeout time.Duration `yaml:"timeout,omitempty" env:"BACKEND_SYSLOG_TIMEOUT,overwrite"`
+}
+
+// SetDefault sets default for the Syslog.
+func (s *Syslog) SetDefault() {
+	if s.Network == "" {
+		s.Network = SyslogNetworkUDP
+	}
+	if s.Facility == "" {
+		s.Facility = "auth"
+	}
+	if s.AppName == "" {
+		s.AppName = "lumberjack"
+	}
+	if s.Timeout == 0 {
+		s.Timeout = 10 * time.Second
+	}
+}
+
+// Validate validates the Syslog.
+func (s *Syslog) Validate() error {
+	var merr error
+	switch s.Network {
+	case SyslogNetworkUDP, SyslogNetworkTCP, SyslogNetworkTLS:
+	default:
+		merr = errors.Join(merr, fmt.Errorf("unexpected backend syslog network %q want %q, %q or %q", s.Network, SyslogNetworkUDP, SyslogNetworkTCP, SyslogNetworkTLS))
+	}
+	if s.Address == "" {
+		merr = errors.Join(merr, fmt.Errorf("backend syslog address is not set"))
+	}
+	if s.CAFile != "" && s.Network != SyslogNetworkTLS {
+		merr = errors.Join(merr, fmt.Errorf("backend syslog ca_file requires the %q network", SyslogNetworkTLS))
+	}
+	if s.Timeout < 0 {
+		merr = errors.Join(merr, fmt.Errorf("backend syslog timeout must not be negative"))
+	}
+	return merr
+}
+
+// PubSub is the backend publishing audit logs to a Cloud Pub/Sub topic.
+type PubSub struct {
+	// Topic is the topic to publish to, either a topic ID in the project
+	// where the client runs or a full "projects/<project>/topics/<topic>"
+	// name.
+	Topic string `yaml:"topic,omitempty" env:"BACKEND_PUBSUB_TOPIC,overwrite"`
+
+	// OrderingEnabled publishes the audit logs with their operation ID as the
+	// ordering key, so that the audit logs of an operation, e.g. of a stream,
+	// are delivered in order.
+	OrderingEnabled bool `yaml:"ordering_enabled,omitempty" env:"BACKEND_PUBSUB_ORDERING_ENABLED,overwrite"`
+}
+
+// Validate validates the PubSub.
+func (ps *PubSub) Validate() error {
+	if ps.Topic == "" {
+		return fmt.Errorf("backend pubsub topic is not set")
 	}
 	return nil
 }
@@ -304,6 +893,8 @@ type JWKs struct {
 type AuditRule struct {
 	// Selector is a string to match request methods/paths.
 	// In gRPC, this is in the format of "/[service_name].[method_name]".
+	// In HTTP, this is either "[method] [path]" or "[path]" to match any
+	// method, e.g. "GET /v1/users/*".
 	Selector string `yaml:"selector,omitempty"`
 
 	// Directive specifies what audit action to take for the matching requests.
@@ -311,6 +902,9 @@ type AuditRule struct {
 	// "AUDIT" - write audit log without request/response.
 	// "AUDIT_REQUEST_ONLY" - write audit log with only request.
 	// "AUDIT_REQUEST_AND_RESPONSE" - write audit log with request and response.
+	// "AUDIT_STREAM_SUMMARY" - write a single audit log per server stream, when
+	// it closes, with the message counts and the first and last requests. Other
+	// calls are audited as with "AUDIT_REQUEST_ONLY".
 	Directive string `yaml:"directive,omitempty"`
 
 	// LogType specifies the audit log type for the matching requests.
@@ -336,9 +930,10 @@ func (r *AuditRule) Validate() error {
 	case AuditRuleDirectiveDefault:
 	case AuditRuleDirectiveRequestOnly:
 	case AuditRuleDirectiveRequestAndResponse:
+	case AuditRuleDirectiveStreamSummary:
 	default:
-		return fmt.Errorf("unexpected rule.Directive %q want one of [%q, %q, %q]",
-			r.Directive, AuditRuleDirectiveDefault, AuditRuleDirectiveRequestOnly, AuditRuleDirectiveRequestAndResponse)
+		return fmt.Errorf("unexpected rule.Directive %q want one of [%q, %q, %q, %q]",
+			r.Directive, AuditRuleDirectiveDefault, AuditRuleDirectiveRequestOnly, AuditRuleDirectiveRequestAndResponse, AuditRuleDirectiveStreamSummary)
 	}
 	switch r.LogType {
 	case AuditLogRequest_ADMIN_ACTIVITY.String():
@@ -387,3 +982,82 @@ func (justification *Justification) Validate() error {
 	}
 	return nil
 }
+
+// Sampling specifies sampling rates, between 0 and 1, for audit log requests.
+// The rate of a request is the rate of the most relevant rule matching its
+// method, or else the rate of its log type. Requests without a rate are all
+// kept, and ADMIN_ACTIVITY requests are never sampled.
+type Sampling struct {
+	// Key specifies what the sampling decision is keyed on.
+	// Allowed values are:
+	// "RANDOM" - each request is sampled independently.
+	// "PRINCIPAL" - all the requests of a principal are kept or dropped together.
+	// "OPERATION_ID" - all the requests of an operation are kept or dropped together.
+	// If empty, the default value is "RANDOM".
+	Key string `yaml:"key,omitempty" env:"SAMPLING_KEY,overwrite"`
+
+	// LogTypes specifies the sampling rate per log type, e.g. "DATA_ACCESS".
+	LogTypes map[string]float64 `yaml:"log_types,omitempty"`
+
+	// Rules specifies the sampling rate per matching request method.
+	Rules []*SamplingRule `yaml:"rules,omitempty"`
+}
+
+// SetDefault sets default for the Sampling.
+func (s *Sampling) SetDefault() {
+	if s.Key == "" {
+		s.Key = SamplingKeyRandom
+	}
+}
+
+// Validate validates the Sampling.
+func (s *Sampling) Validate() error {
+	var merr error
+	switch s.Key {
+	case SamplingKeyRandom, SamplingKeyPrincipal, SamplingKeyOperationID:
+	default:
+		merr = errors.Join(merr, fmt.Errorf("unexpected sampling key %q want one of [%q, %q, %q]",
+			s.Key, SamplingKeyRandom, SamplingKeyPrincipal, SamplingKeyOperationID))
+	}
+	for _, logType := range slices.Sorted(maps.Keys(s.LogTypes)) {
+		rate := s.LogTypes[logType]
+		switch logType {
+		case AuditLogRequest_DATA_ACCESS.String():
+		case AuditLogRequest_ADMIN_ACTIVITY.String():
+			merr = errors.Join(merr, fmt.Errorf("sampling log type %q is never sampled", logType))
+		default:
+			merr = errors.Join(merr, fmt.Errorf("unexpected sampling log type %q want %q",
+				logType, AuditLogRequest_DATA_ACCESS.String()))
+		}
+		if rate < 0 || rate > 1 {
+			merr = errors.Join(merr, fmt.Errorf("sampling rate %v for log type %q must be between 0 and 1", rate, logType))
+		}
+	}
+	for _, r := range s.Rules {
+		if err := r.Validate(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+	return merr
+}
+
+// SamplingRule is the sampling rate of the selected methods.
+type SamplingRule struct {
+	// Selector is a string to match request methods, in the same format as
+	// AuditRule.Selector.
+	Selector string `yaml:"selector,omitempty"`
+
+	// Rate is the fraction of the matching requests to keep, between 0 and 1.
+	Rate float64 `yaml:"rate"`
+}
+
+// Validate validates the SamplingRule.
+func (r *SamplingRule) Validate() error {
+	if r.Selector == "" {
+		return fmt.Errorf("sampling rule selector is empty")
+	}
+	if r.Rate < 0 || r.Rate > 1 {
+		return fmt.Errorf("sampling rate %v for selector %q must be between 0 and 1", r.Rate, r.Selector)
+	}
+	return nil
+}
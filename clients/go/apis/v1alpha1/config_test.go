@@ -17,6 +17,7 @@ package v1alpha1
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"gopkg.in/yaml.v2"
@@ -179,6 +180,10 @@ func TestValidate(t *testing.T) {
 					Selector:  "*",
 					Directive: "AUDIT_REQUEST_ONLY",
 					LogType:   "DATA_ACCESS",
+				}, {
+					Selector:  "/com.example.Chat/*",
+					Directive: "AUDIT_STREAM_SUMMARY",
+					LogType:   "DATA_ACCESS",
 				}},
 				Justification: &Justification{
 					PublicKeysEndpoint: "example.com",
@@ -250,6 +255,18 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: `unexpected rule.LogType "random" want one of ["ADMIN_ACTIVITY", "DATA_ACCESS"]`,
 		},
+		{
+			name: "invalid_client_rule_directive",
+			cfg: &Config{
+				Version: "v1alpha1",
+				ClientRules: []*AuditRule{{
+					Selector:  "*",
+					Directive: "random",
+					LogType:   "DATA_ACCESS",
+				}},
+			},
+			wantErr: `client rule: unexpected rule.Directive "random"`,
+		},
 		{
 			name: "combination_of_errors",
 			cfg: &Config{
@@ -318,6 +335,29 @@ audit rule selector is empty`,
 			},
 			wantErr: `backend cloudlogging project is set while using default project`,
 		},
+		{
+			name: "invalid_backend_cloudlogging_routing",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					CloudLogging: &CloudLogging{
+						DefaultProject: true,
+						LogNames: map[string]string{
+							"DATA_ACCESS": "",
+							"UNKNOWN":     "unknown-audit",
+						},
+						Routes: []*CloudLoggingRoute{
+							{Project: "eu-project", LogTypes: []string{"DATA_ACCESS"}},
+							{ServiceName: "billing", LogTypes: []string{"BILLING"}},
+						},
+					},
+				},
+			},
+			wantErr: `backend cloudlogging log_names has empty log name for "DATA_ACCESS"
+backend cloudlogging log_names has unexpected log type "UNKNOWN"
+backend cloudlogging route 1 must set a project or a log_name
+backend cloudlogging route 1 has unexpected log type "BILLING"`,
+		},
 		{
 			name: "invalid_justification",
 			cfg: &Config{
@@ -349,6 +389,178 @@ audit rule selector is empty`,
 			},
 			wantErr: `public_keys_endpoint must be specified when justification is enabled`,
 		},
+		{
+			name: "invalid_backend_retry",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{
+						Address: "foo",
+					},
+					Retry: &Retry{
+						InitialBackoff: time.Second,
+						MaxBackoff:     time.Millisecond,
+						JitterPercent:  200,
+					},
+				},
+			},
+			wantErr: `backend retry max_backoff 1ms is less than initial_backoff 1s
+backend retry jitter_percent must be at most 100`,
+		},
+		{
+			name: "invalid_backend_circuit_breaker",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{
+						Address: "foo",
+					},
+					CircuitBreaker: &CircuitBreaker{
+						OpenTimeout: -time.Second,
+					},
+				},
+			},
+			wantErr: `backend circuit_breaker open_timeout must not be negative`,
+		},
+		{
+			name: "invalid_sampling",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{
+						Address: "foo",
+					},
+				},
+				Sampling: &Sampling{
+					Key: "METHOD",
+					LogTypes: map[string]float64{
+						"ADMIN_ACTIVITY": 0.5,
+						"DATA_ACCESS":    2,
+					},
+					Rules: []*SamplingRule{{Rate: 0.5}},
+				},
+			},
+			wantErr: `unexpected sampling key "METHOD" want one of ["RANDOM", "PRINCIPAL", "OPERATION_ID"]
+sampling log type "ADMIN_ACTIVITY" is never sampled
+sampling rate 2 for log type "DATA_ACCESS" must be between 0 and 1
+sampling rule selector is empty`,
+		},
+		{
+			name: "invalid_backend_file",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					File: &File{
+						MaxSizeBytes:     -1,
+						RotationInterval: -time.Second,
+						Permissions:      "0999",
+					},
+				},
+			},
+			wantErr: `backend file path is not set
+backend file max_size_bytes must not be negative
+backend file rotation_interval must not be negative
+backend file permissions "0999" must be octal permission bits, e.g. "0600"`,
+		},
+		{
+			name: "invalid_backend_webhook",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Webhook: &Webhook{
+						BatchDelay: -time.Second,
+					},
+				},
+			},
+			wantErr: `backend webhook url is not set
+backend webhook batch_delay must not be negative`,
+		},
+		{
+			name: "invalid_backend_otlp",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					OTLP: &OTLP{
+						Protocol: "udp",
+						Timeout:  -time.Second,
+					},
+				},
+			},
+			wantErr: `unexpected backend otlp protocol "udp" want "grpc" or "http"
+backend otlp timeout must not be negative`,
+		},
+		{
+			name: "invalid_backend_syslog",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Syslog: &Syslog{
+						Network: "unix",
+						CAFile:  "/etc/ca.pem",
+					},
+				},
+			},
+			wantErr: `unexpected backend syslog network "unix" want "udp", "tcp" or "tls"
+backend syslog address is not set
+backend syslog ca_file requires the "tls" network`,
+		},
+		{
+			name: "invalid_backend_pubsub",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					PubSub: &PubSub{OrderingEnabled: true},
+				},
+			},
+			wantErr: "backend pubsub topic is not set",
+		},
+		{
+			name: "invalid_backend_remote_batch_delay",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{Address: "foo:443", MaxBatchSize: 10, BatchDelay: -time.Second},
+				},
+			},
+			wantErr: "backend remote batch_delay must not be negative",
+		},
+		{
+			name: "invalid_backend_remote_tls",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{Address: "foo:443", InsecureEnabled: true, CertFile: "cert.pem"},
+				},
+			},
+			wantErr: `backend remote cert_file and key_file must be set together
+backend remote TLS settings can't be used with insecure_enabled`,
+		},
+		{
+			name: "invalid_backend_remote_id_token_disabled",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{Address: "foo:443", IDTokenDisabled: true, ImpersonateAccount: "sa@example.iam.gserviceaccount.com"},
+				},
+			},
+			wantErr: "backend remote impersonate_account can't be used with id_token_disabled",
+		},
+		{
+			name: "invalid_backend_remote_load_balancing",
+			cfg: &Config{
+				Version: "v1alpha1",
+				Backend: &Backend{
+					Remote: &Remote{
+						Addresses:           []string{"us.example.com:443", ""},
+						LoadBalancingPolicy: "random",
+						EjectionDuration:    -time.Second,
+					},
+				},
+			},
+			wantErr: `backend remote addresses must not be empty
+backend remote load_balancing_policy "random" must be one of "priority_failover", "round_robin"
+backend remote ejection_duration must not be negative`,
+		},
 	}
 
 	for _, tc := range cases {
@@ -377,6 +589,9 @@ func TestSetDefault(t *testing.T) {
 		},
 		wantCfg: &Config{
 			Version: "v1alpha1",
+			Backend: &Backend{
+				Stdout: &Stdout{},
+			},
 			LogMode: "BEST_EFFORT",
 		},
 	}, {
@@ -390,6 +605,9 @@ func TestSetDefault(t *testing.T) {
 		},
 		wantCfg: &Config{
 			Version: "v1alpha1",
+			Backend: &Backend{
+				Stdout: &Stdout{},
+			},
 			LogMode: "BEST_EFFORT",
 			Rules: []*AuditRule{{
 				Selector:  "*",
@@ -415,6 +633,153 @@ func TestSetDefault(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "default_backend_retry_and_circuit_breaker",
+		cfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Retry: &Retry{
+					InitialBackoff: 5 * time.Second,
+				},
+				CircuitBreaker: &CircuitBreaker{},
+			},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Stdout: &Stdout{},
+				Retry: &Retry{
+					MaxAttempts:    3,
+					InitialBackoff: 5 * time.Second,
+					MaxBackoff:     5 * time.Second,
+					JitterPercent:  10,
+				},
+				CircuitBreaker: &CircuitBreaker{
+					FailureThreshold: 5,
+					OpenTimeout:      30 * time.Second,
+				},
+			},
+		},
+	}, {
+		name: "default_backend_file",
+		cfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				File: &File{
+					Path: "/var/log/audit.log",
+				},
+			},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				File: &File{
+					Path:         "/var/log/audit.log",
+					MaxSizeBytes: 100 << 20,
+					Permissions:  "0600",
+				},
+			},
+		},
+	}, {
+		name: "default_backend_webhook",
+		cfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Webhook: &Webhook{
+					URL: "https://collector.example.com/audit",
+				},
+			},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Webhook: &Webhook{
+					URL:          "https://collector.example.com/audit",
+					Timeout:      10 * time.Second,
+					MaxBatchSize: 1,
+					BatchDelay:   100 * time.Millisecond,
+				},
+			},
+		},
+	}, {
+		name: "default_backend_otlp",
+		cfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				OTLP: &OTLP{},
+			},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				OTLP: &OTLP{
+					Protocol: "grpc",
+				},
+			},
+		},
+	}, {
+		name: "default_backend_syslog",
+		cfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Syslog: &Syslog{Address: "localhost:514"},
+			},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Syslog: &Syslog{
+					Network:  "udp",
+					Address:  "localhost:514",
+					Facility: "auth",
+					AppName:  "lumberjack",
+					Timeout:  10 * time.Second,
+				},
+			},
+		},
+	}, {
+		name: "default_backend_remote_batching",
+		cfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Remote: &Remote{Address: "foo:443", MaxBatchSize: 10},
+			},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			LogMode: "BEST_EFFORT",
+			Backend: &Backend{
+				Remote: &Remote{Address: "foo:443", MaxBatchSize: 10, BatchDelay: 100 * time.Millisecond},
+			},
+		},
+	}, {
+		name: "default_sampling_key",
+		cfg: &Config{
+			Version:  "v1alpha1",
+			LogMode:  "BEST_EFFORT",
+			Sampling: &Sampling{},
+		},
+		wantCfg: &Config{
+			Version: "v1alpha1",
+			Backend: &Backend{
+				Stdout: &Stdout{},
+			},
+			LogMode: "BEST_EFFORT",
+			Sampling: &Sampling{
+				Key: "RANDOM",
+			},
+		},
 	}, {
 		name: "default_fail_close_log_mode",
 		cfg: &Config{
@@ -422,6 +787,9 @@ func TestSetDefault(t *testing.T) {
 		},
 		wantCfg: &Config{
 			Version: "v1alpha1",
+			Backend: &Backend{
+				Stdout: &Stdout{},
+			},
 			LogMode: AuditLogRequest_FAIL_CLOSE.String(),
 		},
 	}}
@@ -0,0 +1,198 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+)
+
+// QueueFullPolicy specifies what an asynchronous client does with a log
+// request when its queue is full.
+type QueueFullPolicy int
+
+const (
+	// QueueFullBlock blocks the caller until there is room in the queue or the
+	// caller's context is done. This is the default policy.
+	QueueFullBlock QueueFullPolicy = iota
+
+	// QueueFullDrop drops the log request immediately and reports
+	// auditerrors.ErrQueueFull.
+	QueueFullDrop
+)
+
+// WithAsync makes the client process log requests asynchronously. Log
+// requests are put into a queue that holds up to queueSize requests, and
+// are processed by the given number of background workers. Calling Stop or
+// StopContext drains the queue before stopping the processors.
+//
+// In asynchronous mode, Log returns as soon as a BEST_EFFORT request is
+// queued, while a FAIL_CLOSE request still waits for its result. Use LogAsync
+// to get a LogResult for the request instead of waiting.
+func WithAsync(queueSize, workers int) Option {
+	return func(ctx context.Context, o *Client) error {
+		if queueSize < 1 {
+			return fmt.Errorf("async queue size must be positive, got %d", queueSize)
+		}
+		if workers < 1 {
+			return fmt.Errorf("async workers must be positive, got %d", workers)
+		}
+		o.queueSize = queueSize
+		o.workers = workers
+		return nil
+	}
+}
+
+// WithQueueFullPolicy sets what an asynchronous client does when its queue is
+// full. The default is QueueFullBlock. This option has no effect unless
+// WithAsync is also provided.
+func WithQueueFullPolicy(p QueueFullPolicy) Option {
+	return func(ctx context.Context, o *Client) error {
+		o.queueFullPolicy = p
+		return nil
+	}
+}
+
+// LogResult is the eventual result of processing a log request.
+type LogResult struct {
+	done chan struct{}
+	err  error
+}
+
+func newLogResult() *LogResult {
+	return &LogResult{done: make(chan struct{})}
+}
+
+// completedLogResult returns a LogResult that is already done with the given
+// error.
+func completedLogResult(err error) *LogResult {
+	r := newLogResult()
+	r.complete(err)
+	return r
+}
+
+func (r *LogResult) complete(err error) {
+	r.err = err
+	close(r.done)
+}
+
+// Done returns a channel that is closed once the log request has been
+// processed.
+func (r *LogResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns the error of processing the log request. It must only be called
+// after Done is closed. Like Log, it only returns an error when the request
+// should fail close.
+func (r *LogResult) Err() error {
+	return r.err
+}
+
+// Wait blocks until the log request has been processed or the given context
+// is done, and returns the result.
+func (r *LogResult) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for audit log result: %w", ctx.Err())
+	}
+}
+
+// queuedLog is a log request waiting to be processed by a worker.
+type queuedLog struct {
+	ctx    context.Context //nolint:containedctx // Carried to the worker.
+	logReq *api.AuditLogRequest
+	result *LogResult
+}
+
+// logQueue is a bounded queue of log requests drained by background workers.
+type logQueue struct {
+	policy QueueFullPolicy
+	ch     chan *queuedLog
+	wg     sync.WaitGroup
+
+	// mu guards closing ch. Senders hold the read lock so that ch is never
+	// closed while a send is in flight.
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// newLogQueue creates a queue and starts its workers, each of which calls
+// process for every dequeued log request.
+func newLogQueue(size, workers int, policy QueueFullPolicy, process func(context.Context, *api.AuditLogRequest) error) *logQueue {
+	q := &logQueue{
+		policy: policy,
+		ch:     make(chan *queuedLog, size),
+	}
+	q.wg.Add(workers)
+	for range workers {
+		go func() {
+			defer q.wg.Done()
+			for l := range q.ch {
+				l.result.complete(process(l.ctx, l.logReq))
+			}
+		}()
+	}
+	return q
+}
+
+// enqueue adds the log request to the queue according to the queue full
+// policy.
+func (q *logQueue) enqueue(ctx context.Context, l *queuedLog) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.stopped {
+		return auditerrors.ErrClientStopped
+	}
+
+	if q.policy == QueueFullDrop {
+		select {
+		case q.ch <- l:
+			return nil
+		default:
+			return auditerrors.ErrQueueFull
+		}
+	}
+
+	select {
+	case q.ch <- l:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to enqueue log request: %w", ctx.Err())
+	}
+}
+
+// stop stops accepting new log requests and waits for the workers to drain
+// the queue, or for the context to be done.
+func (q *logQueue) stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	if err := waitContext(ctx, q.wg.Wait); err != nil {
+		return fmt.Errorf("failed to drain audit log queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// blockingProcessor counts the processed requests. If release is set, it
+// blocks until release is closed, and signals started when it begins.
+type blockingProcessor struct {
+	started   chan struct{}
+	release   chan struct{}
+	returnErr error
+	count     atomic.Int64
+}
+
+func (p *blockingProcessor) Process(ctx context.Context, _ *api.AuditLogRequest) error {
+	if p.release != nil {
+		select {
+		case p.started <- struct{}{}:
+		default:
+		}
+		<-p.release
+	}
+	p.count.Add(1)
+	return p.returnErr
+}
+
+func TestNewClient_Async(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "valid",
+			opts: []Option{WithAsync(10, 2), WithQueueFullPolicy(QueueFullDrop)},
+		},
+		{
+			name:          "zero_queue_size",
+			opts:          []Option{WithAsync(0, 2)},
+			wantErrSubstr: "async queue size must be positive",
+		},
+		{
+			name:          "zero_workers",
+			opts:          []Option{WithAsync(10, 0)},
+			wantErrSubstr: "async workers must be positive",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewClient(ctx, tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewClient() got unexpected error substring: %v", diff)
+			}
+			if err == nil {
+				if err := c.Stop(); err != nil {
+					t.Errorf("failed to stop client: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLog_Async(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	cases := []struct {
+		name          string
+		logMode       api.AuditLogRequest_LogMode
+		backendErr    error
+		wantErrSubstr string
+	}{
+		{
+			name:    "fail_close_success",
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+		},
+		{
+			name:          "fail_close_waits_for_error",
+			logMode:       api.AuditLogRequest_FAIL_CLOSE,
+			backendErr:    fmt.Errorf("fake error"),
+			wantErrSubstr: "failed to execute backend",
+		},
+		{
+			name:       "best_effort_swallows_error",
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			backendErr: fmt.Errorf("fake error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &blockingProcessor{returnErr: tc.backendErr}
+			c, err := NewClient(ctx, WithAsync(1, 1), WithBackend(b), WithLogMode(tc.logMode))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.Log(ctx, testutil.NewRequest())
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("Log() got unexpected error substring: %v", diff)
+			}
+
+			if err := c.Stop(); err != nil {
+				t.Errorf("failed to stop client: %v", err)
+			}
+			if got, want := b.count.Load(), int64(1); got != want {
+				t.Errorf("backend processed %d requests, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestLogAsync(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	b := &blockingProcessor{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	c, err := NewClient(ctx,
+		WithAsync(1, 1),
+		WithQueueFullPolicy(QueueFullDrop),
+		WithBackend(b),
+		WithLogMode(api.AuditLogRequest_FAIL_CLOSE))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first request is picked up by the only worker, which then blocks.
+	first := c.LogAsync(ctx, testutil.NewRequest())
+	<-b.started
+
+	// The second request fills the queue, and the third one is dropped.
+	second := c.LogAsync(ctx, testutil.NewRequest())
+	third := c.LogAsync(ctx, testutil.NewRequest())
+	select {
+	case <-third.Done():
+	default:
+		t.Fatalf("LogAsync() expected dropped request to be done")
+	}
+	if err := third.Err(); !errors.Is(err, auditerrors.ErrQueueFull) {
+		t.Errorf("LogAsync() got error %v, want %v", err, auditerrors.ErrQueueFull)
+	}
+
+	close(b.release)
+	for _, r := range []*LogResult{first, second} {
+		if err := r.Wait(ctx); err != nil {
+			t.Errorf("LogResult.Wait() unexpected error: %v", err)
+		}
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Errorf("failed to stop client: %v", err)
+	}
+	if got, want := b.count.Load(), int64(2); got != want {
+		t.Errorf("backend processed %d requests, want %d", got, want)
+	}
+
+	// Requests after Stop are rejected.
+	if err := c.LogAsync(ctx, testutil.NewRequest()).Wait(ctx); !errors.Is(err, auditerrors.ErrClientStopped) {
+		t.Errorf("LogAsync() after Stop got error %v, want %v", err, auditerrors.ErrClientStopped)
+	}
+}
+
+func TestStop_Async_Drains(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	b := &blockingProcessor{}
+	c, err := NewClient(ctx,
+		WithAsync(100, 4),
+		WithBackend(b),
+		WithLogMode(api.AuditLogRequest_BEST_EFFORT))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 50
+	for range n {
+		if err := c.Log(ctx, testutil.NewRequest()); err != nil {
+			t.Errorf("Log() unexpected error: %v", err)
+		}
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Errorf("failed to stop client: %v", err)
+	}
+	if got, want := b.count.Load(), int64(n); got != want {
+		t.Errorf("backend processed %d requests, want %d", got, want)
+	}
+}
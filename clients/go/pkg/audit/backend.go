@@ -0,0 +1,343 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/pkg/logging"
+)
+
+// BackendOption is a configuration option for a backend added with
+// WithBackend.
+type BackendOption func(b *backend)
+
+// WithBackendName sets the name used to report the backend's results. The
+// default name is the backend's type.
+func WithBackendName(name string) BackendOption {
+	return func(b *backend) {
+		b.name = name
+	}
+}
+
+// WithBackendOptional marks the backend as optional. A failure of an
+// optional backend is logged, but doesn't fail the log request unless no
+// backend succeeded at all. Backends are required by default.
+func WithBackendOptional() BackendOption {
+	return func(b *backend) {
+		b.optional = true
+	}
+}
+
+// WithBackendTimeout limits how long the backend may take to process a single
+// log request, or a whole batch for a BatchLogProcessor backend. By default, a
+// backend is only bound by the caller's context.
+func WithBackendTimeout(d time.Duration) BackendOption {
+	return func(b *backend) {
+		b.timeout = d
+	}
+}
+
+// WithParallelBackends makes the client run all backends concurrently rather
+// than in the order they were added. Each backend receives its own copy of
+// the log request, so changes made by one backend, e.g. the result merged by
+// the remote processor, are not seen by the others or by the caller.
+func WithParallelBackends() Option {
+	return func(ctx context.Context, o *Client) error {
+		o.parallelBackends = true
+		return nil
+	}
+}
+
+// backend is a log processor used as a logging backend, along with its
+// failure policy.
+type backend struct {
+	processor LogProcessor
+	name      string
+	optional  bool
+	timeout   time.Duration
+}
+
+func newBackend(p LogProcessor, opts ...BackendOption) *backend {
+	b := &backend{
+		processor: p,
+		name:      fmt.Sprintf("%T", p),
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// process runs the backend processor within the backend timeout.
+func (b *backend) process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	return b.processor.Process(ctx, logReq)
+}
+
+// processBatch runs the batch backend processor within the backend timeout.
+func (b *backend) processBatch(ctx context.Context, bp BatchLogProcessor, logReqs []*api.AuditLogRequest) error {
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	return bp.ProcessBatch(ctx, logReqs)
+}
+
+// processDiagnostics runs the diagnostic backend processor within the backend
+// timeout.
+func (b *backend) processDiagnostics(ctx context.Context, dp DiagnosticLogProcessor, logReqs []*api.AuditLogRequest) ([][]*api.Diagnostic, error) {
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	return dp.ProcessDiagnostics(ctx, logReqs)
+}
+
+// processBackend runs the backend and records its metrics.
+func (c *Client) processBackend(ctx context.Context, b *backend, logReq *api.AuditLogRequest) error {
+	start := time.Now()
+	err := b.process(ctx, logReq)
+	c.metrics.recordProcessor(ctx, logReq, stageBackend, b.name, start, err)
+	return err
+}
+
+// BackendResult is the outcome of a single backend for a log request.
+type BackendResult struct {
+	// Name is the name of the backend, see WithBackendName.
+	Name string
+
+	// Required reports whether the backend's failure fails the log request.
+	Required bool
+
+	// Err is the error returned by the backend, or nil if it succeeded or was
+	// skipped.
+	Err error
+
+	// Skipped reports whether the backend precondition failed, in which case
+	// the backend neither failed nor succeeded.
+	Skipped bool
+}
+
+// BackendError is the error returned when the backends failed to process a
+// log request, either because a required backend failed or because no
+// backend succeeded. It holds the results of all the backends that ran.
+type BackendError struct {
+	Results []*BackendResult
+}
+
+// Error satisfies the error interface.
+func (e *BackendError) Error() string {
+	var msgs []string
+	for _, r := range e.Results {
+		if r.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("failed to execute backend %s: %v", r.Name, r.Err))
+		}
+	}
+	msg := strings.Join(msgs, "; ")
+	if succeeded := e.Succeeded(); len(succeeded) > 0 {
+		msg = fmt.Sprintf("%s (succeeded backends: [%s])", msg, strings.Join(succeeded, ", "))
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed backends.
+func (e *BackendError) Unwrap() []error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// Succeeded returns the names of the backends that succeeded.
+func (e *BackendError) Succeeded() []string {
+	var names []string
+	for _, r := range e.Results {
+		if r.Err == nil && !r.Skipped {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// runBackends runs the client backends on the given log request. It returns
+// false if a backend precondition failed and processing stopped, and a
+// *BackendError if the log request failed.
+func (c *Client) runBackends(ctx context.Context, logReq *api.AuditLogRequest) (bool, error) {
+	results, processed := c.runBackendsBatch(ctx, []*api.AuditLogRequest{logReq})
+	if !processed[0] {
+		return false, nil
+	}
+	return true, backendsError(ctx, results[0])
+}
+
+// runBackendsBatch runs the client backends on the given log requests and
+// returns the backend results of each log request, and whether it was
+// processed, i.e. no backend precondition failed for it.
+func (c *Client) runBackendsBatch(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*BackendResult, []bool) {
+	if c.parallelBackends {
+		results := c.runBackendsParallel(ctx, logReqs)
+		// A log request is processed unless all the backends skipped it.
+		processed := make([]bool, len(logReqs))
+		for i, rs := range results {
+			for _, r := range rs {
+				processed[i] = processed[i] || !r.Skipped
+			}
+		}
+		return results, processed
+	}
+	return c.runBackendsSequential(ctx, logReqs)
+}
+
+// backendsError returns a *BackendError if the backend results fail the log
+// request, i.e. a required backend failed or no backend succeeded. Failures
+// of optional backends are logged.
+func backendsError(ctx context.Context, results []*BackendResult) error {
+	var requiredFailed, succeeded bool
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+		case r.Err == nil:
+			succeeded = true
+		case r.Required:
+			requiredFailed = true
+		default:
+			logging.FromContext(ctx).WarnContext(ctx, "optional audit log backend failed",
+				"backend", r.Name,
+				"error", r.Err)
+		}
+	}
+	if requiredFailed || (len(results) > 0 && !succeeded) {
+		return &BackendError{Results: results}
+	}
+	return nil
+}
+
+// runBackendsSequential runs the backends in order on the shared log requests.
+// A log request is not passed to the next backends once a required backend
+// failed for it, or once a backend precondition failed for it, in which case
+// it's reported as not processed and does not fail.
+func (c *Client) runBackendsSequential(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*BackendResult, []bool) {
+	results := make([][]*BackendResult, len(logReqs))
+	processed := make([]bool, len(logReqs))
+	active := make([]bool, len(logReqs))
+	for i := range logReqs {
+		processed[i], active[i] = true, true
+	}
+
+	for _, b := range c.backends {
+		var idx []int
+		var reqs []*api.AuditLogRequest
+		for i, logReq := range logReqs {
+			if active[i] {
+				idx = append(idx, i)
+				reqs = append(reqs, logReq)
+			}
+		}
+		if len(reqs) == 0 {
+			break
+		}
+
+		errs, diags := c.processBackendBatch(ctx, b, reqs)
+		reportDiagnostics(ctx, reqs, diags)
+		for j, err := range errs {
+			i := idx[j]
+			if errors.Is(err, auditerrors.ErrPreconditionFailed) {
+				logging.FromContext(ctx).WarnContext(ctx, "stopped log request processing as backend precondition failed",
+					"backend", b.name,
+					"error", err)
+				results[i], processed[i], active[i] = nil, false, false
+				continue
+			}
+			results[i] = append(results[i], &BackendResult{Name: b.name, Required: !b.optional, Err: err})
+			if err != nil && !b.optional {
+				active[i] = false
+			}
+		}
+	}
+	return results, processed
+}
+
+// runBackendsParallel runs all the backends concurrently, each on its own copy
+// of the log requests. A backend precondition failure only skips that backend,
+// which is reported as skipped.
+func (c *Client) runBackendsParallel(ctx context.Context, logReqs []*api.AuditLogRequest) [][]*BackendResult {
+	results := make([][]*BackendResult, len(logReqs))
+	for i := range results {
+		results[i] = make([]*BackendResult, len(c.backends))
+	}
+
+	var wg sync.WaitGroup
+	for bi, b := range c.backends {
+		reqs, err := cloneRequests(logReqs)
+		if err != nil {
+			for i := range results {
+				results[i][bi] = &BackendResult{Name: b.name, Required: !b.optional, Err: err}
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs, diags := c.processBackendBatch(ctx, b, reqs)
+			// The backend processed copies, so report the diagnostics on the
+			// log requests.
+			reportDiagnostics(ctx, logReqs, diags)
+			for i, err := range errs {
+				if errors.Is(err, auditerrors.ErrPreconditionFailed) {
+					logging.FromContext(ctx).WarnContext(ctx, "skipped backend as backend precondition failed",
+						"backend", b.name,
+						"error", err)
+					results[i][bi] = &BackendResult{Name: b.name, Required: !b.optional, Skipped: true}
+					continue
+				}
+				results[i][bi] = &BackendResult{Name: b.name, Required: !b.optional, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func cloneRequests(logReqs []*api.AuditLogRequest) ([]*api.AuditLogRequest, error) {
+	reqs := make([]*api.AuditLogRequest, 0, len(logReqs))
+	for _, logReq := range logReqs {
+		req, ok := proto.Clone(logReq).(*api.AuditLogRequest)
+		if !ok {
+			return nil, fmt.Errorf("expected *api.AuditLogRequest")
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
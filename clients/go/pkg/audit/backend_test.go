@@ -0,0 +1,234 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// countingProcessor counts how many times it's called and returns the given
+// error.
+type countingProcessor struct {
+	returnErr error
+	count     atomic.Int64
+}
+
+func (p *countingProcessor) Process(_ context.Context, _ *api.AuditLogRequest) error {
+	p.count.Add(1)
+	return p.returnErr
+}
+
+// hangingProcessor blocks until the context is done.
+type hangingProcessor struct{}
+
+func (p *hangingProcessor) Process(ctx context.Context, _ *api.AuditLogRequest) error {
+	<-ctx.Done()
+	return fmt.Errorf("hanging processor: %w", ctx.Err())
+}
+
+func TestLog_Backends(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	fakeErr := fmt.Errorf("fake error")
+
+	cases := []struct {
+		name          string
+		parallel      bool
+		backends      []*countingProcessor
+		backendOpts   [][]BackendOption
+		wantCounts    []int64
+		wantErrSubstr string
+		wantSucceeded []string
+	}{
+		{
+			name:       "sequential_all_succeed",
+			backends:   []*countingProcessor{{}, {}},
+			wantCounts: []int64{1, 1},
+		},
+		{
+			name:          "sequential_required_failure_stops",
+			backends:      []*countingProcessor{{returnErr: fakeErr}, {}},
+			backendOpts:   [][]BackendOption{{WithBackendName("primary")}, {WithBackendName("secondary")}},
+			wantCounts:    []int64{1, 0},
+			wantErrSubstr: "failed to execute backend primary: fake error",
+		},
+		{
+			name:        "sequential_optional_failure_continues",
+			backends:    []*countingProcessor{{returnErr: fakeErr}, {}},
+			backendOpts: [][]BackendOption{{WithBackendOptional()}, nil},
+			wantCounts:  []int64{1, 1},
+		},
+		{
+			name:          "sequential_all_optional_failed",
+			backends:      []*countingProcessor{{returnErr: fakeErr}, {returnErr: fakeErr}},
+			backendOpts:   [][]BackendOption{{WithBackendOptional()}, {WithBackendOptional()}},
+			wantCounts:    []int64{1, 1},
+			wantErrSubstr: "failed to execute backend",
+		},
+		{
+			name:       "parallel_all_succeed",
+			parallel:   true,
+			backends:   []*countingProcessor{{}, {}, {}},
+			wantCounts: []int64{1, 1, 1},
+		},
+		{
+			name:          "parallel_required_failure_runs_others",
+			parallel:      true,
+			backends:      []*countingProcessor{{returnErr: fakeErr}, {}},
+			backendOpts:   [][]BackendOption{{WithBackendName("primary")}, {WithBackendName("secondary")}},
+			wantCounts:    []int64{1, 1},
+			wantErrSubstr: "failed to execute backend primary: fake error (succeeded backends: [secondary])",
+			wantSucceeded: []string{"secondary"},
+		},
+		{
+			name:        "parallel_optional_failure",
+			parallel:    true,
+			backends:    []*countingProcessor{{}, {returnErr: fakeErr}},
+			backendOpts: [][]BackendOption{nil, {WithBackendOptional()}},
+			wantCounts:  []int64{1, 1},
+		},
+		{
+			name:     "parallel_precondition_failure_skips_backend",
+			parallel: true,
+			backends: []*countingProcessor{
+				{returnErr: fmt.Errorf("skip: %w", auditerrors.ErrPreconditionFailed)},
+				{},
+			},
+			wantCounts: []int64{1, 1},
+		},
+		{
+			name:     "parallel_precondition_failure_does_not_succeed",
+			parallel: true,
+			backends: []*countingProcessor{
+				{returnErr: fmt.Errorf("skip: %w", auditerrors.ErrPreconditionFailed)},
+				{returnErr: fakeErr},
+			},
+			backendOpts:   [][]BackendOption{{WithBackendName("skipped")}, {WithBackendName("secondary"), WithBackendOptional()}},
+			wantCounts:    []int64{1, 1},
+			wantErrSubstr: "failed to execute backend secondary: fake error",
+		},
+		{
+			name:     "parallel_all_backends_skipped",
+			parallel: true,
+			backends: []*countingProcessor{
+				{returnErr: fmt.Errorf("skip: %w", auditerrors.ErrPreconditionFailed)},
+				{returnErr: fmt.Errorf("skip: %w", auditerrors.ErrPreconditionFailed)},
+			},
+			wantCounts: []int64{1, 1},
+		},
+		{
+			name: "sequential_precondition_failure_stops",
+			backends: []*countingProcessor{
+				{returnErr: fmt.Errorf("skip: %w", auditerrors.ErrPreconditionFailed)},
+				{},
+			},
+			wantCounts: []int64{1, 0},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []Option{WithLogMode(api.AuditLogRequest_FAIL_CLOSE)}
+			if tc.parallel {
+				opts = append(opts, WithParallelBackends())
+			}
+			for i, b := range tc.backends {
+				var bopts []BackendOption
+				if i < len(tc.backendOpts) {
+					bopts = tc.backendOpts[i]
+				}
+				opts = append(opts, WithBackend(b, bopts...))
+			}
+
+			c, err := NewClient(ctx, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if err := c.Stop(); err != nil {
+					t.Errorf("failed to stop client: %v", err)
+				}
+			})
+
+			err = c.Log(ctx, testutil.NewRequest())
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("Log() got unexpected error substring: %v", diff)
+			}
+
+			if tc.wantErrSubstr != "" {
+				var berr *BackendError
+				if !errors.As(err, &berr) {
+					t.Fatalf("Log() got error %v, want *BackendError", err)
+				}
+				if diff := cmp.Diff(tc.wantSucceeded, berr.Succeeded()); diff != "" {
+					t.Errorf("BackendError.Succeeded() got diff (-want, +got): %v", diff)
+				}
+			}
+
+			gotCounts := make([]int64, 0, len(tc.backends))
+			for _, b := range tc.backends {
+				gotCounts = append(gotCounts, b.count.Load())
+			}
+			if diff := cmp.Diff(tc.wantCounts, gotCounts); diff != "" {
+				t.Errorf("backend calls got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestLog_BackendTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	secondary := &countingProcessor{}
+	c, err := NewClient(ctx,
+		WithLogMode(api.AuditLogRequest_FAIL_CLOSE),
+		WithParallelBackends(),
+		WithBackend(&hangingProcessor{}, WithBackendName("hanging"), WithBackendTimeout(10*time.Millisecond)),
+		WithBackend(secondary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := c.Stop(); err != nil {
+			t.Errorf("failed to stop client: %v", err)
+		}
+	})
+
+	err = c.Log(ctx, testutil.NewRequest())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Log() got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got, want := secondary.count.Load(), int64(1); got != want {
+		t.Errorf("secondary backend processed %d requests, want %d", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/diagnostics"
+)
+
+// BatchLogProcessor is the interface to backends that can process several
+// audit log requests at once, e.g. with a single write call. Client.LogBatch
+// hands such backends the whole batch, and falls back to one Process call per
+// log request for the other backends.
+type BatchLogProcessor interface {
+	LogProcessor
+
+	// ProcessBatch processes the given log requests. It returns a *BatchError
+	// to report the result of each log request; any other error applies to
+	// all of them.
+	ProcessBatch(context.Context, []*api.AuditLogRequest) error
+}
+
+// DiagnosticLogProcessor is the interface to batch backends that return the
+// diagnostics of the log requests they process, e.g. the remote processor
+// relaying the diagnostics of an AuditLogAgent. The client reports them on the
+// log requests it was given, see diagnostics.NewContext, even when the backend
+// processed copies of them, see WithParallelBackends. The client hands such
+// backends every batch, including batches of a single log request.
+type DiagnosticLogProcessor interface {
+	BatchLogProcessor
+
+	// ProcessDiagnostics processes the given log requests as ProcessBatch
+	// does, and returns the diagnostics of each log request, in order.
+	ProcessDiagnostics(context.Context, []*api.AuditLogRequest) ([][]*api.Diagnostic, error)
+}
+
+// BatchError is returned by BatchLogProcessor.ProcessBatch to report the
+// result of each log request of a batch, see auditerrors.BatchError.
+type BatchError = auditerrors.BatchError
+
+// LogBatch runs the client processors on the given AuditLogRequests and
+// returns the result of each of them, in order, as Log would. The validators
+// and mutators run on each log request, then every backend processes the
+// remaining log requests at once if it implements BatchLogProcessor, or one at
+// a time otherwise. LogBatch processes the log requests before returning, even
+// if the client is asynchronous, and fails them all with
+// auditerrors.ErrClientStopped once the client is stopped.
+func (c *Client) LogBatch(ctx context.Context, logReqs []*api.AuditLogRequest) []error {
+	errs := make([]error, len(logReqs))
+	if !c.begin() {
+		for i, logReq := range logReqs {
+			c.setDefaultMode(logReq)
+			errs[i] = c.handleFailure(ctx, logReq, time.Time{}, auditerrors.ErrClientStopped)
+		}
+		return errs
+	}
+	defer c.inflight.Done()
+
+	starts := make([]time.Time, len(logReqs))
+
+	// The indexes and the log requests that reach the backends.
+	var idx []int
+	var reqs []*api.AuditLogRequest
+	for i, logReq := range logReqs {
+		c.setDefaultMode(logReq)
+		setTraceContext(ctx, logReq)
+		starts[i] = time.Now()
+		ok, err := c.runProcessors(ctx, logReq)
+		if !ok || err != nil {
+			errs[i] = c.finish(ctx, logReq, starts[i], ok, err)
+			continue
+		}
+		idx = append(idx, i)
+		reqs = append(reqs, logReq)
+	}
+	if len(reqs) == 0 {
+		return errs
+	}
+
+	results, processed := c.runBackendsBatch(ctx, reqs)
+	for j, i := range idx {
+		var err error
+		if processed[j] {
+			err = backendsError(ctx, results[j])
+		}
+		errs[i] = c.finish(ctx, logReqs[i], starts[i], processed[j], err)
+	}
+	return errs
+}
+
+// processBackendBatch runs the backend on the given log requests, records its
+// metrics and returns the error and the diagnostics of each log request. A
+// batch of a single log request is processed with Process, unless the backend
+// is a DiagnosticLogProcessor.
+func (c *Client) processBackendBatch(ctx context.Context, b *backend, logReqs []*api.AuditLogRequest) ([]error, [][]*api.Diagnostic) {
+	errs := make([]error, len(logReqs))
+
+	dp, withDiags := b.processor.(DiagnosticLogProcessor)
+	bp, ok := b.processor.(BatchLogProcessor)
+	if !withDiags && (!ok || len(logReqs) == 1) {
+		for i, logReq := range logReqs {
+			errs[i] = c.processBackend(ctx, b, logReq)
+		}
+		return errs, nil
+	}
+
+	start := time.Now()
+	var diags [][]*api.Diagnostic
+	var err error
+	if withDiags {
+		diags, err = b.processDiagnostics(ctx, dp, logReqs)
+		if len(diags) != len(logReqs) {
+			// Don't report diagnostics on the wrong log requests.
+			diags = nil
+		}
+	} else {
+		err = b.processBatch(ctx, bp, logReqs)
+	}
+
+	var batchErr *BatchError
+	switch {
+	case errors.As(err, &batchErr) && len(batchErr.Errs) == len(logReqs):
+		copy(errs, batchErr.Errs)
+	case batchErr != nil:
+		err = fmt.Errorf("batch backend returned %d results for %d log requests: %w", len(batchErr.Errs), len(logReqs), err)
+		fallthrough
+	default:
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+
+	for i, logReq := range logReqs {
+		c.metrics.recordProcessor(ctx, logReq, stageBackend, b.name, start, errs[i])
+	}
+	return errs, diags
+}
+
+// reportDiagnostics reports the diagnostics that a backend returned for the
+// log requests on the log requests that the client was given, which the
+// backend may have processed copies of.
+func reportDiagnostics(ctx context.Context, logReqs []*api.AuditLogRequest, diags [][]*api.Diagnostic) {
+	for i, ds := range diags {
+		for _, d := range ds {
+			diagnostics.Add(ctx, logReqs[i], d)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// batchProcessor records the size of the batches it processes. It fails the
+// log requests whose method is in failMethods, or the whole batch with
+// batchErr.
+type batchProcessor struct {
+	failMethods map[string]error
+	batchErr    error
+
+	mu         sync.Mutex
+	batchSizes []int
+	calls      int
+}
+
+func (p *batchProcessor) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	return p.failMethods[logReq.GetPayload().GetMethodName()]
+}
+
+func (p *batchProcessor) ProcessBatch(_ context.Context, logReqs []*api.AuditLogRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batchSizes = append(p.batchSizes, len(logReqs))
+	if p.batchErr != nil {
+		return p.batchErr
+	}
+
+	errs := make([]error, len(logReqs))
+	var failed bool
+	for i, logReq := range logReqs {
+		if err := p.failMethods[logReq.GetPayload().GetMethodName()]; err != nil {
+			errs[i] = err
+			failed = true
+		}
+	}
+	if failed {
+		return &BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// diagnosticProcessor is a batchProcessor that returns its diagnostics for
+// every log request.
+type diagnosticProcessor struct {
+	batchProcessor
+
+	diagnostics []*api.Diagnostic
+}
+
+func (p *diagnosticProcessor) ProcessDiagnostics(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*api.Diagnostic, error) {
+	diags := make([][]*api.Diagnostic, len(logReqs))
+	for i := range diags {
+		diags[i] = p.diagnostics
+	}
+	return diags, p.ProcessBatch(ctx, logReqs)
+}
+
+func TestLogBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	fakeErr := fmt.Errorf("fake error")
+
+	cases := []struct {
+		name           string
+		methods        []string
+		validator      LogProcessor
+		batchBackend   *batchProcessor
+		otherBackend   *countingProcessor
+		parallel       bool
+		wantErrSubstrs []string
+		wantBatchSizes []int
+		wantCalls      int
+		wantOtherCount int64
+	}{
+		{
+			name:           "all_succeed",
+			methods:        []string{"m1", "m2", "m3"},
+			batchBackend:   &batchProcessor{},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{"", "", ""},
+			wantBatchSizes: []int{3},
+			wantOtherCount: 3,
+		},
+		{
+			name:    "per_entry_failure",
+			methods: []string{"m1", "m2", "m3"},
+			batchBackend: &batchProcessor{failMethods: map[string]error{
+				"m2": fakeErr,
+			}},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{"", "fake error", ""},
+			wantBatchSizes: []int{3},
+			// The required batch backend failed m2, so only m1 and m3 reach
+			// the next backend.
+			wantOtherCount: 2,
+		},
+		{
+			name:           "whole_batch_failure",
+			methods:        []string{"m1", "m2"},
+			batchBackend:   &batchProcessor{batchErr: fakeErr},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{"fake error", "fake error"},
+			wantBatchSizes: []int{2},
+		},
+		{
+			name:           "result_count_mismatch",
+			methods:        []string{"m1", "m2"},
+			batchBackend:   &batchProcessor{batchErr: &BatchError{Errs: []error{nil}}},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{"1 results for 2 log requests", "1 results for 2 log requests"},
+			wantBatchSizes: []int{2},
+		},
+		{
+			name:    "backend_precondition_skips_entry",
+			methods: []string{"m1", "m2"},
+			batchBackend: &batchProcessor{failMethods: map[string]error{
+				"m1": auditerrors.ErrPreconditionFailed,
+			}},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{"", ""},
+			wantBatchSizes: []int{2},
+			wantOtherCount: 1,
+		},
+		{
+			name:           "validator_failure_excludes_entry",
+			methods:        []string{"m1", "m2", "m3"},
+			validator:      &methodValidator{failMethod: "m1"},
+			batchBackend:   &batchProcessor{},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{"failed to execute validator", "", ""},
+			wantBatchSizes: []int{2},
+			wantOtherCount: 2,
+		},
+		{
+			name:           "single_entry_uses_process",
+			methods:        []string{"m1"},
+			batchBackend:   &batchProcessor{},
+			otherBackend:   &countingProcessor{},
+			wantErrSubstrs: []string{""},
+			wantCalls:      1,
+			wantOtherCount: 1,
+		},
+		{
+			name:    "parallel",
+			methods: []string{"m1", "m2"},
+			batchBackend: &batchProcessor{failMethods: map[string]error{
+				"m2": fakeErr,
+			}},
+			otherBackend:   &countingProcessor{},
+			parallel:       true,
+			wantErrSubstrs: []string{"", "fake error"},
+			wantBatchSizes: []int{2},
+			wantOtherCount: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []Option{
+				WithLogMode(api.AuditLogRequest_FAIL_CLOSE),
+				WithBackend(tc.batchBackend, WithBackendName("batch")),
+				WithBackend(tc.otherBackend, WithBackendName("other")),
+			}
+			if tc.validator != nil {
+				opts = append(opts, WithValidator(tc.validator))
+			}
+			if tc.parallel {
+				opts = append(opts, WithParallelBackends())
+			}
+			c, err := NewClient(ctx, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reqs := make([]*api.AuditLogRequest, 0, len(tc.methods))
+			for _, m := range tc.methods {
+				reqs = append(reqs, testutil.NewRequest(testutil.WithMethodName(m)))
+			}
+
+			errs := c.LogBatch(ctx, reqs)
+			if got, want := len(errs), len(tc.methods); got != want {
+				t.Fatalf("LogBatch() got %d errors, want %d", got, want)
+			}
+			for i, err := range errs {
+				if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstrs[i]); diff != "" {
+					t.Errorf("LogBatch() entry %d: %s", i, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.wantBatchSizes, tc.batchBackend.batchSizes); diff != "" {
+				t.Errorf("batch sizes got diff (-want, +got): %v", diff)
+			}
+			if got, want := tc.batchBackend.calls, tc.wantCalls; got != want {
+				t.Errorf("batch backend Process calls got %d, want %d", got, want)
+			}
+			if got, want := tc.otherBackend.count.Load(), tc.wantOtherCount; got != want {
+				t.Errorf("other backend calls got %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+// methodValidator fails the log requests of the given method.
+type methodValidator struct {
+	failMethod string
+}
+
+func (v *methodValidator) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	if logReq.GetPayload().GetMethodName() == v.failMethod {
+		return fmt.Errorf("invalid method %q", v.failMethod)
+	}
+	return nil
+}
@@ -19,9 +19,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/diagnostics"
 	"github.com/abcxyz/pkg/logging"
 )
 
@@ -29,8 +35,30 @@ import (
 type Client struct {
 	validators []LogProcessor
 	mutators   []LogProcessor
-	backends   []LogProcessor
+	backends   []*backend
 	logMode    api.AuditLogRequest_LogMode
+
+	// parallelBackends runs the backends concurrently, see
+	// WithParallelBackends.
+	parallelBackends bool
+
+	// Asynchronous processing settings, see WithAsync.
+	queueSize       int
+	workers         int
+	queueFullPolicy QueueFullPolicy
+	queue           *logQueue
+
+	meterProvider metric.MeterProvider
+	metrics       *clientMetrics
+
+	// errorHandler is called on swallowed errors, see WithErrorHandler.
+	errorHandler ErrorHandler
+
+	// stopMu guards stopped, so that no log call starts once StopContext
+	// waits for the in-flight ones.
+	stopMu   sync.RWMutex
+	stopped  bool
+	inflight sync.WaitGroup
 }
 
 // LogProcessor is the interface we use to process an AuditLogRequest.
@@ -83,13 +111,15 @@ func WithRuntimeInfo() Option {
 
 // WithBackend adds the given log processor as a logging backend. Log
 // backend processors are executed in the order provided with this
-// option and after any other audit log processing.
+// option and after any other audit log processing, unless
+// WithParallelBackends is used. The backend options set the backend's
+// failure policy and timeout.
 // Examples of logging backends are:
 //   - The Cloud Logging GCP service
 //   - The custom Lumberjack gRPC service
-func WithBackend(p LogProcessor) Option {
+func WithBackend(p LogProcessor, opts ...BackendOption) Option {
 	return func(ctx context.Context, o *Client) error {
-		o.backends = append(o.backends, p)
+		o.backends = append(o.backends, newBackend(p, opts...))
 		return nil
 	}
 }
@@ -116,88 +146,210 @@ func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
 			return nil, fmt.Errorf("failed to apply client options: %w", err)
 		}
 	}
+	m, err := newClientMetrics(client.meterProvider)
+	if err != nil {
+		return nil, err
+	}
+	client.metrics = m
+	if client.queueSize > 0 {
+		client.queue = newLogQueue(client.queueSize, client.workers, client.queueFullPolicy, client.process)
+	}
 	return client, nil
 }
 
-// Stop stops the client.
+// Stop stops the client without a deadline, see StopContext.
 func (c *Client) Stop() error {
-	var merr error
-	for _, ps := range [][]LogProcessor{c.validators, c.backends} {
-		for _, p := range ps {
-			if stoppable, ok := p.(StoppableProcessor); ok {
-				if err := stoppable.Stop(); err != nil {
-					merr = errors.Join(merr, err)
-				}
-			}
-		}
-	}
-
-	return merr
+	return c.StopContext(context.Background())
 }
 
 // Log runs the client processors sequentially on the given AuditLogRequest.
+// If the client is asynchronous, the request is queued instead, and Log only
+// waits for the result if the request should fail close.
 func (c *Client) Log(ctx context.Context, logReq *api.AuditLogRequest) error {
-	logger := logging.FromContext(ctx)
+	c.setDefaultMode(logReq)
+	setTraceContext(ctx, logReq)
+	if c.queue == nil {
+		return c.processSync(ctx, logReq)
+	}
+
+	// Read the mode before queueing, as the request is owned by the workers
+	// afterwards.
+	mode := logReq.GetMode()
+	r := c.LogAsync(ctx, logReq)
+	if !api.ShouldFailClose(mode) {
+		return nil
+	}
+	return r.Wait(ctx)
+}
+
+// LogAsync queues the given AuditLogRequest and returns a LogResult to wait
+// for the outcome. The request must not be modified until the result is done.
+// If the client is not asynchronous, the request is processed before LogAsync
+// returns.
+func (c *Client) LogAsync(ctx context.Context, logReq *api.AuditLogRequest) *LogResult {
+	c.setDefaultMode(logReq)
+	setTraceContext(ctx, logReq)
+	if c.queue == nil {
+		return completedLogResult(c.processSync(ctx, logReq))
+	}
 
+	r := newLogResult()
+	// The request outlives the caller, so detach it from the caller's
+	// cancellation while keeping the context values, e.g. the logger.
+	l := &queuedLog{ctx: context.WithoutCancel(ctx), logReq: logReq, result: r}
+	if err := c.queue.enqueue(ctx, l); err != nil {
+		return completedLogResult(c.handleFailure(ctx, logReq, time.Time{}, fmt.Errorf("failed to queue log request: %w", err)))
+	}
+	return r
+}
+
+func (c *Client) setDefaultMode(logReq *api.AuditLogRequest) {
 	if logReq.GetMode() == api.AuditLogRequest_LOG_MODE_UNSPECIFIED {
 		logReq.Mode = c.logMode
 	}
+}
+
+// setTraceContext sets the trace and the span of the log request from the
+// active span of the context, unless the log request already belongs to a
+// trace, e.g. when it was forwarded by another client.
+func setTraceContext(ctx context.Context, logReq *api.AuditLogRequest) {
+	if logReq.GetTrace() != "" {
+		return
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	logReq.Trace = sc.TraceID().String()
+	logReq.SpanId = sc.SpanID().String()
+	logReq.TraceSampled = sc.IsSampled()
+}
+
+// processSync processes the given AuditLogRequest on the caller's goroutine,
+// unless the client is stopped.
+func (c *Client) processSync(ctx context.Context, logReq *api.AuditLogRequest) error {
+	if !c.begin() {
+		return c.handleFailure(ctx, logReq, time.Time{}, auditerrors.ErrClientStopped)
+	}
+	defer c.inflight.Done()
+	return c.process(ctx, logReq)
+}
+
+// begin registers an in-flight log call, which must be ended with
+// c.inflight.Done. It returns false if the client is stopped.
+func (c *Client) begin() bool {
+	c.stopMu.RLock()
+	defer c.stopMu.RUnlock()
+	if c.stopped {
+		return false
+	}
+	c.inflight.Add(1)
+	return true
+}
+
+// process runs the client processors sequentially on the given AuditLogRequest.
+func (c *Client) process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	start := time.Now()
+	ok, err := c.runProcessors(ctx, logReq)
+	if ok && err == nil {
+		ok, err = c.runBackends(ctx, logReq)
+	}
+	return c.finish(ctx, logReq, start, ok, err)
+}
+
+// runProcessors runs the validators and the mutators on the given
+// AuditLogRequest. It returns false if processing must stop, either because
+// a processor precondition failed or because of the returned error.
+func (c *Client) runProcessors(ctx context.Context, logReq *api.AuditLogRequest) (bool, error) {
+	logger := logging.FromContext(ctx)
 
 	for _, p := range c.validators {
-		if err := p.Process(ctx, logReq); err != nil {
+		pstart := time.Now()
+		err := p.Process(ctx, logReq)
+		c.metrics.recordProcessor(ctx, logReq, stageValidator, fmt.Sprintf("%T", p), pstart, err)
+		if err != nil {
 			if errors.Is(err, auditerrors.ErrPreconditionFailed) {
 				logger.WarnContext(ctx, "stopped log request processing as validator precondition failed",
 					"validator", p,
 					"error", err)
-				return nil
+				diagnostics.Add(ctx, logReq, &api.Diagnostic{
+					Severity: api.Diagnostic_INFO,
+					Message:  fmt.Sprintf("stopped log request processing as validator precondition failed: %v", err),
+				})
+				return false, nil
 			}
-			return c.handleReturn(ctx, fmt.Errorf("failed to execute validator %T: %w", p, err), logReq.GetMode())
+			return false, newProcessorError(stageValidator, p, err)
 		}
 	}
 
 	for _, p := range c.mutators {
-		if err := p.Process(ctx, logReq); err != nil {
+		pstart := time.Now()
+		err := p.Process(ctx, logReq)
+		c.metrics.recordProcessor(ctx, logReq, stageMutator, fmt.Sprintf("%T", p), pstart, err)
+		if err != nil {
 			if errors.Is(err, auditerrors.ErrPreconditionFailed) {
 				logger.WarnContext(ctx, "stopped log request processing as mutator precondition failed",
 					"validator", p,
 					"error", err)
-				return nil
+				diagnostics.Add(ctx, logReq, &api.Diagnostic{
+					Severity: api.Diagnostic_INFO,
+					Message:  fmt.Sprintf("stopped log request processing as mutator precondition failed: %v", err),
+				})
+				return false, nil
 			}
-			return c.handleReturn(ctx, fmt.Errorf("failed to execute mutator %T: %w", p, err), logReq.GetMode())
+			return false, newProcessorError(stageMutator, p, err)
 		}
 	}
 
-	for _, p := range c.backends {
-		if err := p.Process(ctx, logReq); err != nil {
-			if errors.Is(err, auditerrors.ErrPreconditionFailed) {
-				logger.WarnContext(ctx, "stopped log request processing as backend precondition failed",
-					"validator", p,
-					"error", err)
-				return nil
-			}
-			return c.handleReturn(ctx, fmt.Errorf("failed to execute backend %T: %w", p, err), logReq.GetMode())
-		}
-	}
+	return true, nil
+}
 
+// finish records the outcome of a log request that started processing at
+// the given time, and applies the log mode to the error. A log request that
+// was not processed without an error was skipped.
+func (c *Client) finish(ctx context.Context, logReq *api.AuditLogRequest, start time.Time, processed bool, err error) error {
+	switch {
+	case err != nil:
+		return c.handleFailure(ctx, logReq, start, err)
+	case !processed:
+		c.metrics.recordLog(ctx, logReq, outcomeSkipped, start)
+	default:
+		c.metrics.recordLog(ctx, logReq, outcomeSuccess, start)
+	}
 	return nil
 }
 
+// handleFailure records the failed log request and applies the log mode to
+// the error, see handleReturn. The start time is zero if processing didn't
+// start.
+func (c *Client) handleFailure(ctx context.Context, logReq *api.AuditLogRequest, start time.Time, err error) error {
+	outcome := outcomeDropped
+	if api.ShouldFailClose(logReq.GetMode()) {
+		outcome = outcomeFailed
+	}
+	c.metrics.recordLog(ctx, logReq, outcome, start)
+	return c.handleReturn(ctx, logReq, err)
+}
+
 // handleReturn is intended to be a wrapper that handles the LogMode correctly, and returns errors or
 // nil depending on whether the config and request have specified that they want to fail close.
-func (c *Client) handleReturn(ctx context.Context, err error, requestedLogMode api.AuditLogRequest_LogMode) error {
+func (c *Client) handleReturn(ctx context.Context, logReq *api.AuditLogRequest, err error) error {
 	// If there is no error, just return nil.
 	if err == nil {
 		return nil
 	}
 
 	// If there is an error, and we should fail close, return that error.
-	if api.ShouldFailClose(requestedLogMode) {
+	if api.ShouldFailClose(logReq.GetMode()) {
 		return err
 	}
 
-	// If there is an error, and we shouldn't fail close, log and return nil.
+	// If there is an error, and we shouldn't fail close, log, report it as a
+	// warning, notify the error handler and return nil.
 	logger := logging.FromContext(ctx)
 	logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
 		"error", err)
+	diagnostics.Warn(ctx, logReq, fmt.Sprintf("failed to audit log; continuing without audit logging: %v", err))
+	c.handleError(ctx, logReq, err)
 	return nil
 }
@@ -0,0 +1,423 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/google/uuid"
+	capi "google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/pkg/logging"
+)
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a context whose outbound calls are audited
+// with the given principal, instead of the principal of the incoming request,
+// e.g. for the calls of a background job.
+func ContextWithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// UnaryClientInterceptor is a gRPC unary client interceptor that automatically
+// emits application audit logs for outbound calls, e.g. the calls a service
+// makes on behalf of its users. The client audit rules select the methods to
+// audit. The principal is the one set with ContextWithPrincipal, or else the
+// principal of the incoming request, and the resource is the target of the
+// client connection. A callee error is recorded as the audit log status.
+func (i *Interceptor) UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	logger := logging.FromContext(ctx)
+	r := mostRelevantRule(method, i.clientRules)
+	if r == nil {
+		logger.DebugContext(ctx, "no client audit rule matching the method name",
+			"method_name", method,
+			"audit_rules", i.clientRules)
+		i.metrics.recordCall(ctx, method, "", outcomeSkipped)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	outcome := outcomeSuccess
+	defer func() {
+		i.metrics.recordCall(ctx, method, r.LogType, outcome)
+	}()
+
+	logReq, err := i.outboundLogReq(ctx, method, cc, r)
+	if err == nil && shouldLogReq(r) {
+		err = setReq(logReq, req)
+	}
+	if err != nil {
+		outcome = i.failureOutcome()
+		if err := i.handleReturnClient(ctx, logReq, err); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	if callErr := invoker(ctx, method, req, reply, cc, opts...); callErr != nil {
+		i.setErrorStatus(callErr, logReq)
+
+		// Best effort log the error.
+		if err := i.Log(ctx, logReq); err != nil {
+			outcome = outcomeDropped
+			logger.ErrorContext(ctx, "unable to audit log error", "error", err)
+			i.handleError(ctx, logReq, err)
+		}
+		return callErr
+	}
+
+	// Autofill `Payload.Response`.
+	if shouldLogResp(r) {
+		if err := setResp(logReq, reply); err != nil {
+			outcome = i.failureOutcome()
+			return i.handleReturnClient(ctx, logReq, err)
+		}
+	}
+
+	if err := i.Log(ctx, logReq); err != nil {
+		outcome = i.failureOutcome()
+		return i.handleReturnClient(ctx, logReq,
+			auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err)))
+	}
+	return nil
+}
+
+// StreamClientInterceptor is a gRPC stream client interceptor that
+// automatically emits application audit logs for outbound streams, see
+// UnaryClientInterceptor. As with StreamInterceptor, the audit logs of a
+// stream share an operation, and each request is logged with the next
+// response, if any.
+func (i *Interceptor) StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	logger := logging.FromContext(ctx)
+	r := mostRelevantRule(method, i.clientRules)
+	if r == nil {
+		logger.DebugContext(ctx, "no client audit rule matching the method name",
+			"method_name", method,
+			"audit_rules", i.clientRules)
+		i.metrics.recordCall(ctx, method, "", outcomeSkipped)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	logReq, err := i.outboundLogReq(ctx, method, cc, r)
+	if err != nil {
+		i.metrics.recordCall(ctx, method, r.LogType, i.failureOutcome())
+		if err := i.handleReturnClient(ctx, logReq, err); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	// Set operation to associate logs from the same stream.
+	logReq.Operation = &loggingpb.LogEntryOperation{
+		Producer: method,
+		Id:       uuid.New().String(),
+	}
+
+	cs, callErr := streamer(ctx, desc, cc, method, opts...)
+	if callErr != nil {
+		outcome := outcomeSuccess
+		i.setErrorStatus(callErr, logReq)
+
+		// Best effort log the error.
+		if err := i.Log(ctx, logReq); err != nil {
+			outcome = outcomeDropped
+			logger.ErrorContext(ctx, "unable to audit log error", "error", err)
+			i.handleError(ctx, logReq, err)
+		}
+		i.metrics.recordCall(ctx, method, r.LogType, outcome)
+		return nil, callErr
+	}
+
+	w := &clientStreamWrapper{
+		ClientStream:   cs,
+		i:              i,
+		ctx:            ctx,
+		desc:           desc,
+		baselineLogReq: logReq,
+		rule:           r,
+		outcome:        outcomeSuccess,
+	}
+	// A stream canceled by its context may never get another RecvMsg, so
+	// its end is audited once the context is done.
+	w.stopWatch = context.AfterFunc(ctx, w.abort)
+	return w, nil
+}
+
+// outboundLogReq returns the baseline log request of an outbound call. The
+// log request is returned even on error, for the error handler.
+func (i *Interceptor) outboundLogReq(ctx context.Context, method string, cc *grpc.ClientConn, r *api.AuditRule) (*api.AuditLogRequest, error) {
+	logReq := &api.AuditLogRequest{
+		Type: api.AuditLogRequest_UNSPECIFIED,
+		Payload: &capi.AuditLog{
+			MethodName:   method,
+			ResourceName: cc.Target(),
+		},
+		Mode:      i.logMode,
+		Timestamp: timestamppb.New(time.Now().UTC()),
+	}
+	if t, ok := api.AuditLogRequest_LogType_value[r.LogType]; ok {
+		logReq.Type = api.AuditLogRequest_LogType(t)
+	}
+
+	serviceName, err := serviceName(method)
+	if err != nil {
+		return logReq, auditerrors.InterceptorError(status.Error(codes.FailedPrecondition, err.Error()))
+	}
+	logReq.Payload.ServiceName = serviceName
+
+	// The call is made on behalf of the incoming request, so is its
+	// justification.
+	fillJVSToken(ctx, logReq)
+
+	principal, err := i.outboundPrincipal(ctx)
+	if err != nil {
+		logger := logging.FromContext(ctx)
+		logger.ErrorContext(ctx, "audit interceptor failed to get outbound call principal",
+			"security_context", i.sc,
+			"error", err)
+		return logReq, auditerrors.InterceptorError(status.Errorf(codes.FailedPrecondition, "failed to get request principal"))
+	}
+	logReq.Payload.AuthenticationInfo = &capi.AuthenticationInfo{PrincipalEmail: principal}
+	return logReq, nil
+}
+
+// outboundPrincipal returns the principal set with ContextWithPrincipal, or
+// else the principal of the incoming request.
+func (i *Interceptor) outboundPrincipal(ctx context.Context) (string, error) {
+	if p, ok := ctx.Value(principalKey{}).(string); ok {
+		return p, nil
+	}
+	if i.sc == nil {
+		return "", fmt.Errorf("no principal in the context and no security context")
+	}
+	return i.sc.RequestPrincipal(ctx) //nolint:wrapcheck // Want passthrough
+}
+
+// handleReturnClient handles a failure to audit log an outbound call: it
+// returns the error in fail-close mode, and logs it and returns nil
+// otherwise.
+func (i *Interceptor) handleReturnClient(ctx context.Context, logReq *api.AuditLogRequest, err error) error {
+	if api.ShouldFailClose(i.logMode) {
+		return err
+	}
+	// There was an error, but we are failing open.
+	logger := logging.FromContext(ctx)
+	logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
+		"error", err)
+	i.handleError(ctx, logReq, err)
+	return nil
+}
+
+// clientStreamWrapper audits the messages of an outbound stream. A request is
+// logged with the next response, or alone if another request is sent first or
+// the stream ends.
+type clientStreamWrapper struct {
+	grpc.ClientStream
+
+	i    *Interceptor
+	ctx  context.Context //nolint:containedctx // The context of the stream.
+	desc *grpc.StreamDesc
+
+	baselineLogReq *api.AuditLogRequest
+	rule           *api.AuditRule
+
+	// stopWatch stops auditing the end of the stream when its context is
+	// done, see abort.
+	stopWatch func() bool
+
+	// mu guards the last sent request, the outcome, and the end of the
+	// stream, as SendMsg, RecvMsg, and abort can be called concurrently.
+	mu       sync.Mutex
+	lastReq  any
+	outcome  string
+	ended    bool
+	finished bool
+}
+
+func (cs *clientStreamWrapper) swapLastReq(m any) any {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	old := cs.lastReq
+	cs.lastReq = m
+	return old
+}
+
+func (cs *clientStreamWrapper) popLastReq() any {
+	return cs.swapLastReq(nil)
+}
+
+// SendMsg wraps the original ClientStream.SendMsg to keep the request, to be
+// logged with the next response. The previous request is logged alone if it's
+// still pending.
+func (cs *clientStreamWrapper) SendMsg(m any) error {
+	if err := cs.ClientStream.SendMsg(m); err != nil {
+		// The error is io.EOF if the stream ended, which callers check.
+		return err //nolint:wrapcheck // Want passthrough
+	}
+	if !shouldLogReq(cs.rule) {
+		return nil
+	}
+	if lr := cs.swapLastReq(m); lr != nil {
+		return cs.log(lr, nil)
+	}
+	return nil
+}
+
+// RecvMsg wraps the original ClientStream.RecvMsg to log the received
+// response with the last request, if any. Once the stream ends, the pending
+// request is logged alone, and a callee error is logged as the status.
+func (cs *clientStreamWrapper) RecvMsg(m any) error {
+	err := cs.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		defer cs.finish()
+		cs.end()
+		if lr := cs.popLastReq(); lr != nil {
+			if err := cs.log(lr, nil); err != nil {
+				return err
+			}
+		}
+		return err //nolint:wrapcheck // Callers check io.EOF.
+	case err != nil:
+		defer cs.finish()
+		if cs.end() {
+			cs.logError(err)
+		}
+		return err //nolint:wrapcheck // Want passthrough
+	}
+
+	if !cs.desc.ServerStreams {
+		// The single response ends the stream.
+		defer cs.finish()
+		cs.end()
+	}
+	var resp any
+	if shouldLogResp(cs.rule) {
+		resp = m
+	}
+	return cs.log(cs.popLastReq(), resp)
+}
+
+// log audit logs the request and the response, if not nil. It returns the
+// error in fail-close mode.
+func (cs *clientStreamWrapper) log(req, resp any) error {
+	logReq, ok := proto.Clone(cs.baselineLogReq).(*api.AuditLogRequest)
+	if !ok {
+		return fmt.Errorf("expected *api.AuditLogRequest")
+	}
+	err := cs.setPayload(logReq, req, resp)
+	if err == nil {
+		if lerr := cs.i.Log(cs.ctx, logReq); lerr != nil {
+			err = auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", lerr))
+		}
+	}
+	if err != nil {
+		cs.setOutcome(cs.i.failureOutcome())
+		return cs.i.handleReturnClient(cs.ctx, logReq, err)
+	}
+	return nil
+}
+
+// abort audits the end of a stream whose context is done before RecvMsg
+// returned its end: the pending request, if any, is logged with the context
+// error as the status, and the outcome is recorded.
+func (cs *clientStreamWrapper) abort() {
+	if !cs.end() {
+		return
+	}
+	cs.logError(status.FromContextError(cs.ctx.Err()).Err())
+	cs.finish()
+}
+
+// end marks the stream as ended, and reports whether it wasn't already, so
+// that the end of the stream is audited once.
+func (cs *clientStreamWrapper) end() bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.ended {
+		return false
+	}
+	cs.ended = true
+	return true
+}
+
+// logError best effort audit logs the callee error, with the pending request
+// if any. The stream may be canceled, so the log request is emitted without
+// the cancellation of the stream context.
+func (cs *clientStreamWrapper) logError(callErr error) {
+	logReq, ok := proto.Clone(cs.baselineLogReq).(*api.AuditLogRequest)
+	if !ok {
+		return
+	}
+	if lr := cs.popLastReq(); lr != nil {
+		// The request is best effort too.
+		_ = setReq(logReq, lr)
+	}
+	cs.i.setErrorStatus(callErr, logReq)
+	if err := cs.i.Log(context.WithoutCancel(cs.ctx), logReq); err != nil {
+		cs.setOutcome(outcomeDropped)
+		logger := logging.FromContext(cs.ctx)
+		logger.ErrorContext(cs.ctx, "unable to audit log error", "error", err)
+		cs.i.handleError(cs.ctx, logReq, err)
+	}
+}
+
+func (cs *clientStreamWrapper) setPayload(logReq *api.AuditLogRequest, req, resp any) error {
+	if req != nil {
+		if err := setReq(logReq, req); err != nil {
+			return err
+		}
+	}
+	if resp != nil {
+		if err := setResp(logReq, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *clientStreamWrapper) setOutcome(outcome string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.outcome = outcome
+}
+
+// finish records the outcome of the stream, once.
+func (cs *clientStreamWrapper) finish() {
+	cs.mu.Lock()
+	if cs.finished {
+		cs.mu.Unlock()
+		return
+	}
+	cs.finished = true
+	outcome := cs.outcome
+	cs.mu.Unlock()
+
+	cs.stopWatch()
+
+	cs.i.metrics.recordCall(cs.ctx, cs.baselineLogReq.GetPayload().GetMethodName(), cs.rule.LogType, outcome)
+}
@@ -0,0 +1,517 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	capi "google.golang.org/genproto/googleapis/cloud/audit"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/security"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+const testTarget = "passthrough:///downstream.example.com:443"
+
+// newClientTestInterceptor returns an interceptor auditing outbound calls
+// with the rules into the processor, and a connection to testTarget. The
+// options are applied last.
+func newClientTestInterceptor(tb testing.TB, rules []*api.AuditRule, logMode api.AuditLogRequest_LogMode, p LogProcessor, opts ...InterceptorOption) (*Interceptor, *grpc.ClientConn) {
+	tb.Helper()
+
+	ctx := logging.WithLogger(tb.Context(), logging.TestLogger(tb))
+	c, err := NewClient(ctx, WithBackend(p))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := c.Stop(); err != nil {
+			tb.Errorf("failed to stop client: %v", err)
+		}
+	})
+
+	i, err := NewInterceptor(ctx, append([]InterceptorOption{
+		WithAuditClient(c),
+		WithClientAuditRules(rules...),
+		WithInterceptorLogMode(logMode),
+		WithSecurityContext(&security.FromRawJWT{
+			FromRawJWT: []*api.FromRawJWT{{
+				Key:    "authorization",
+				Prefix: "Bearer ",
+			}},
+		}),
+	}, opts...)...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	// The connection is never used, as the invokers are fake.
+	cc, err := grpc.NewClient(testTarget, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := cc.Close(); err != nil {
+			tb.Errorf("failed to close connection: %v", err)
+		}
+	})
+	return i, cc
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	incomingCtx := metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+		"authorization": "Bearer " + testutil.JWTFromClaims(t, map[string]interface{}{
+			"email": "user@example.com",
+		}),
+		"justification-token": "justification",
+	}))
+
+	req := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"name": structpb.NewStringValue("alice"),
+	}}
+	reply := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"id": structpb.NewStringValue("123"),
+	}}
+	okInvoker := func(ctx context.Context, method string, req, r any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		proto.Merge(r.(*structpb.Struct), reply) //nolint:forcetypeassert // Only for testing
+		return nil
+	}
+	wantLogReq := func(principal string, opts ...func(*api.AuditLogRequest)) *api.AuditLogRequest {
+		r := &api.AuditLogRequest{
+			Type: api.AuditLogRequest_DATA_ACCESS,
+			Payload: &capi.AuditLog{
+				ServiceName:  "downstream.v1.Users",
+				MethodName:   "/downstream.v1.Users/Create",
+				ResourceName: testTarget,
+				AuthenticationInfo: &capi.AuthenticationInfo{
+					PrincipalEmail: principal,
+				},
+			},
+			Mode: api.AuditLogRequest_FAIL_CLOSE,
+		}
+		for _, o := range opts {
+			o(r)
+		}
+		return r
+	}
+	rules := []*api.AuditRule{{
+		Selector:  "/downstream.v1.Users/*",
+		Directive: api.AuditRuleDirectiveDefault,
+		LogType:   "DATA_ACCESS",
+	}}
+
+	cases := []struct {
+		name          string
+		ctx           context.Context //nolint:containedctx // Only for testing
+		rules         []*api.AuditRule
+		logMode       api.AuditLogRequest_LogMode
+		invoker       grpc.UnaryInvoker
+		backendErr    error
+		wantInvoked   bool
+		wantLogReq    *api.AuditLogRequest
+		wantErrSubstr string
+	}{
+		{
+			name: "incoming_principal_request_and_response",
+			ctx:  incomingCtx,
+			rules: []*api.AuditRule{{
+				Selector:  "/downstream.v1.Users/Create",
+				Directive: api.AuditRuleDirectiveRequestAndResponse,
+				LogType:   "ADMIN_ACTIVITY",
+			}},
+			logMode:     api.AuditLogRequest_FAIL_CLOSE,
+			invoker:     okInvoker,
+			wantInvoked: true,
+			wantLogReq: wantLogReq("user@example.com", func(r *api.AuditLogRequest) {
+				r.Type = api.AuditLogRequest_ADMIN_ACTIVITY
+				r.JustificationToken = "justification"
+				r.Payload.Request = req
+				r.Payload.Response = reply
+			}),
+		},
+		{
+			name:        "principal_override",
+			ctx:         ContextWithPrincipal(incomingCtx, "job@example.com"),
+			rules:       rules,
+			logMode:     api.AuditLogRequest_FAIL_CLOSE,
+			invoker:     okInvoker,
+			wantInvoked: true,
+			wantLogReq: wantLogReq("job@example.com", func(r *api.AuditLogRequest) {
+				r.JustificationToken = "justification"
+			}),
+		},
+		{
+			name: "no_matching_rule",
+			ctx:  ctx,
+			rules: []*api.AuditRule{{
+				Selector:  "/other.v1.Service/*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:     api.AuditLogRequest_FAIL_CLOSE,
+			invoker:     okInvoker,
+			wantInvoked: true,
+		},
+		{
+			name:    "callee_error",
+			ctx:     ContextWithPrincipal(ctx, "job@example.com"),
+			rules:   rules,
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+			invoker: func(ctx context.Context, method string, req, r any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return grpcstatus.Error(codes.PermissionDenied, "no access")
+			},
+			wantInvoked: true,
+			wantLogReq: wantLogReq("job@example.com", func(r *api.AuditLogRequest) {
+				r.Payload.Status = &rpcstatus.Status{
+					Code:    int32(codes.PermissionDenied),
+					Message: "no access",
+				}
+			}),
+			wantErrSubstr: "no access",
+		},
+		{
+			name:          "principal_failure_fail_close",
+			ctx:           ctx,
+			rules:         rules,
+			logMode:       api.AuditLogRequest_FAIL_CLOSE,
+			invoker:       okInvoker,
+			wantErrSubstr: "failed to get request principal",
+		},
+		{
+			name:        "principal_failure_best_effort",
+			ctx:         ctx,
+			rules:       rules,
+			logMode:     api.AuditLogRequest_BEST_EFFORT,
+			invoker:     okInvoker,
+			wantInvoked: true,
+		},
+		{
+			name:          "log_failure_fail_close",
+			ctx:           ContextWithPrincipal(ctx, "job@example.com"),
+			rules:         rules,
+			logMode:       api.AuditLogRequest_FAIL_CLOSE,
+			invoker:       okInvoker,
+			backendErr:    fmt.Errorf("injected error"),
+			wantInvoked:   true,
+			wantLogReq:    wantLogReq("job@example.com"),
+			wantErrSubstr: "failed to emit log",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &recordingProcessor{returnErr: tc.backendErr}
+			i, cc := newClientTestInterceptor(t, tc.rules, tc.logMode, p)
+
+			var invoked bool
+			invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				invoked = true
+				return tc.invoker(ctx, method, req, reply, cc, opts...)
+			}
+			gotErr := i.UnaryClientInterceptor(tc.ctx, "/downstream.v1.Users/Create", req, &structpb.Struct{}, cc, invoker)
+			if diff := pkgtestutil.DiffErrString(gotErr, tc.wantErrSubstr); diff != "" {
+				t.Errorf("UnaryClientInterceptor(...) got unexpected error substring: %v", diff)
+			}
+			if got, want := invoked, tc.wantInvoked; got != want {
+				t.Errorf("UnaryClientInterceptor(...) invoked got %t, want %t", got, want)
+			}
+
+			var gotLogReq *api.AuditLogRequest
+			if len(p.logReqs) > 0 {
+				gotLogReq = p.logReqs[0]
+			}
+			if diff := cmp.Diff(tc.wantLogReq, gotLogReq, protocmp.Transform(), protocmp.IgnoreFields(&api.AuditLogRequest{}, "timestamp")); diff != "" {
+				t.Errorf("UnaryClientInterceptor(...) got diff in automatically emitted LogReq (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+// fakeClientStream replays the given responses, then returns recvErr, or
+// io.EOF if nil.
+type fakeClientStream struct {
+	grpc.ClientStream
+
+	resps   []*structpb.Struct
+	recvErr error
+	sent    []any
+}
+
+func (cs *fakeClientStream) SendMsg(m any) error {
+	cs.sent = append(cs.sent, m)
+	return nil
+}
+
+func (cs *fakeClientStream) RecvMsg(m any) error {
+	if len(cs.resps) == 0 {
+		if cs.recvErr != nil {
+			return cs.recvErr
+		}
+		return io.EOF
+	}
+	proto.Merge(m.(*structpb.Struct), cs.resps[0]) //nolint:forcetypeassert // Only for testing
+	cs.resps = cs.resps[1:]
+	return nil
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx := ContextWithPrincipal(logging.WithLogger(t.Context(), logging.TestLogger(t)), "job@example.com")
+
+	msg := func(k, v string) *structpb.Struct {
+		return &structpb.Struct{Fields: map[string]*structpb.Value{k: structpb.NewStringValue(v)}}
+	}
+	// recvAll receives until the stream ends.
+	recvAll := func(cs grpc.ClientStream) error {
+		for {
+			if err := cs.RecvMsg(&structpb.Struct{}); err != nil {
+				return err
+			}
+		}
+	}
+	rules := []*api.AuditRule{{
+		Selector:  "/downstream.v1.Users/*",
+		Directive: api.AuditRuleDirectiveRequestAndResponse,
+		LogType:   "DATA_ACCESS",
+	}}
+
+	cases := []struct {
+		name          string
+		rules         []*api.AuditRule
+		stream        *fakeClientStream
+		streamErr     error
+		run           func(cs grpc.ClientStream) error
+		wantPayload   []*capi.AuditLog
+		wantErrSubstr string
+	}{
+		{
+			name:   "pairs_requests_and_responses",
+			rules:  rules,
+			stream: &fakeClientStream{resps: []*structpb.Struct{msg("resp", "1")}},
+			run: func(cs grpc.ClientStream) error {
+				for _, m := range []*structpb.Struct{msg("req", "1"), msg("req", "2")} {
+					if err := cs.SendMsg(m); err != nil {
+						return err
+					}
+				}
+				if err := cs.RecvMsg(&structpb.Struct{}); err != nil {
+					return err
+				}
+				if err := cs.SendMsg(msg("req", "3")); err != nil {
+					return err
+				}
+				return recvAll(cs)
+			},
+			wantPayload: []*capi.AuditLog{
+				// The second request is sent before the first response.
+				{Request: msg("req", "1")},
+				{Request: msg("req", "2"), Response: msg("resp", "1")},
+				// The last request is logged when the stream ends.
+				{Request: msg("req", "3")},
+			},
+			wantErrSubstr: "EOF",
+		},
+		{
+			name: "audit_directive_logs_responses",
+			rules: []*api.AuditRule{{
+				Selector:  "/downstream.v1.Users/*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			stream: &fakeClientStream{resps: []*structpb.Struct{msg("resp", "1"), msg("resp", "2")}},
+			run: func(cs grpc.ClientStream) error {
+				if err := cs.SendMsg(msg("req", "1")); err != nil {
+					return err
+				}
+				return recvAll(cs)
+			},
+			wantPayload:   []*capi.AuditLog{{}, {}},
+			wantErrSubstr: "EOF",
+		},
+		{
+			name:  "callee_error",
+			rules: rules,
+			stream: &fakeClientStream{
+				recvErr: grpcstatus.Error(codes.Unavailable, "downstream unavailable"),
+			},
+			run: func(cs grpc.ClientStream) error {
+				if err := cs.SendMsg(msg("req", "1")); err != nil {
+					return err
+				}
+				return recvAll(cs)
+			},
+			wantPayload: []*capi.AuditLog{{
+				Request: msg("req", "1"),
+				Status: &rpcstatus.Status{
+					Code:    int32(codes.Unavailable),
+					Message: "downstream unavailable",
+				},
+			}},
+			wantErrSubstr: "downstream unavailable",
+		},
+		{
+			name:      "stream_error",
+			rules:     rules,
+			streamErr: grpcstatus.Error(codes.PermissionDenied, "no access"),
+			wantPayload: []*capi.AuditLog{{
+				Status: &rpcstatus.Status{
+					Code:    int32(codes.PermissionDenied),
+					Message: "no access",
+				},
+			}},
+			wantErrSubstr: "no access",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &recordingProcessor{}
+			i, cc := newClientTestInterceptor(t, tc.rules, api.AuditLogRequest_FAIL_CLOSE, p)
+
+			streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				if tc.streamErr != nil {
+					return nil, tc.streamErr
+				}
+				return tc.stream, nil
+			}
+			desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+			cs, err := i.StreamClientInterceptor(ctx, desc, cc, "/downstream.v1.Users/Sync", streamer)
+			if err == nil {
+				err = tc.run(cs)
+			}
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("StreamClientInterceptor(...) got unexpected error substring: %v", diff)
+			}
+
+			gotPayload := make([]*capi.AuditLog, 0, len(p.logReqs))
+			var opID string
+			for _, r := range p.logReqs {
+				if opID == "" {
+					opID = r.GetOperation().GetId()
+				}
+				if got := r.GetOperation().GetId(); got == "" || got != opID {
+					t.Errorf("log request operation ID got %q, want %q", got, opID)
+				}
+				// Keep only the message fields, the rest is the baseline.
+				gotPayload = append(gotPayload, &capi.AuditLog{
+					Request:  r.GetPayload().GetRequest(),
+					Response: r.GetPayload().GetResponse(),
+					Status:   r.GetPayload().GetStatus(),
+				})
+			}
+			if diff := cmp.Diff(tc.wantPayload, gotPayload, protocmp.Transform()); diff != "" {
+				t.Errorf("StreamClientInterceptor(...) got diff in emitted payloads (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestStreamClientInterceptor_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx := ContextWithPrincipal(logging.WithLogger(t.Context(), logging.TestLogger(t)), "job@example.com")
+	rules := []*api.AuditRule{{
+		Selector:  "/downstream.v1.Users/*",
+		Directive: api.AuditRuleDirectiveRequestOnly,
+		LogType:   "DATA_ACCESS",
+	}}
+	req := &structpb.Struct{Fields: map[string]*structpb.Value{"req": structpb.NewStringValue("1")}}
+
+	p := &recordingProcessor{}
+	reader := sdkmetric.NewManualReader()
+	i, cc := newClientTestInterceptor(t, rules, api.AuditLogRequest_FAIL_CLOSE, p,
+		WithInterceptorMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+	stream := &fakeClientStream{recvErr: grpcstatus.Error(codes.Canceled, "context canceled")}
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return stream, nil
+	}
+	callCtx, cancel := context.WithCancel(ctx)
+	desc := &grpc.StreamDesc{ClientStreams: true}
+	cs, err := i.StreamClientInterceptor(callCtx, desc, cc, "/downstream.v1.Users/Upload", streamer)
+	if err != nil {
+		t.Fatalf("StreamClientInterceptor(...) unexpected error: %v", err)
+	}
+	if err := cs.SendMsg(req); err != nil {
+		t.Fatalf("SendMsg() unexpected error: %v", err)
+	}
+	// The call is canceled without receiving its end.
+	cancel()
+
+	want := map[string]int64{
+		"lumberjack.audit.interceptor.calls{log_type=DATA_ACCESS,method=/downstream.v1.Users/Upload,outcome=success}": 1,
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !cmp.Equal(want, collectMetrics(t, reader)) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the stream outcome, got metrics %v", collectMetrics(t, reader))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A late RecvMsg doesn't audit the end of the stream again.
+	if err := cs.RecvMsg(&structpb.Struct{}); grpcstatus.Code(err) != codes.Canceled {
+		t.Errorf("RecvMsg() got error %v, want code %v", err, codes.Canceled)
+	}
+	if diff := cmp.Diff(want, collectMetrics(t, reader)); diff != "" {
+		t.Errorf("metrics got diff (-want, +got): %v", diff)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if got, want := len(p.logReqs), 1; got != want {
+		t.Fatalf("got %d log requests, want %d", got, want)
+	}
+	wantPayload := &capi.AuditLog{
+		Request: req,
+		Status: &rpcstatus.Status{
+			Code:    int32(codes.Canceled),
+			Message: context.Canceled.Error(),
+		},
+	}
+	gotPayload := &capi.AuditLog{
+		Request: p.logReqs[0].GetPayload().GetRequest(),
+		Status:  p.logReqs[0].GetPayload().GetStatus(),
+	}
+	if diff := cmp.Diff(wantPayload, gotPayload, protocmp.Transform()); diff != "" {
+		t.Errorf("log request payload got diff (-want, +got): %v", diff)
+	}
+}
@@ -21,10 +21,12 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/testing/protocmp"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/diagnostics"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
 	"github.com/abcxyz/pkg/logging"
 	pkgtestutil "github.com/abcxyz/pkg/testutil"
@@ -196,6 +198,182 @@ func TestLog(t *testing.T) {
 	}
 }
 
+func TestLog_TraceContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	traceID := trace.TraceID{0x06, 0x79, 0x68, 0x66, 0x73, 0x8c, 0x85, 0x9f, 0x2f, 0x19, 0xb7, 0xcf, 0xb3, 0x21, 0x48, 0x24}
+	spanID := trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	cases := []struct {
+		name        string
+		spanContext trace.SpanContext
+		logReq      *api.AuditLogRequest
+		wantLogReq  *api.AuditLogRequest
+	}{
+		{
+			name:       "no_span",
+			logReq:     testutil.NewRequest(),
+			wantLogReq: testutil.NewRequest(),
+		},
+		{
+			name: "sampled_span",
+			spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: trace.FlagsSampled,
+			}),
+			logReq: testutil.NewRequest(),
+			wantLogReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest()
+				r.Trace = "06796866738c859f2f19b7cfb3214824"
+				r.SpanId = "00f067aa0ba902b7"
+				r.TraceSampled = true
+				return r
+			}(),
+		},
+		{
+			name: "unsampled_span",
+			spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: traceID,
+				SpanID:  spanID,
+			}),
+			logReq: testutil.NewRequest(),
+			wantLogReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest()
+				r.Trace = "06796866738c859f2f19b7cfb3214824"
+				r.SpanId = "00f067aa0ba902b7"
+				return r
+			}(),
+		},
+		{
+			name: "preserves_trace",
+			spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: trace.FlagsSampled,
+			}),
+			logReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest()
+				r.Trace = "projects/test-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+				r.SpanId = "a3ce929d0e0e4736"
+				return r
+			}(),
+			wantLogReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest()
+				r.Trace = "projects/test-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+				r.SpanId = "a3ce929d0e0e4736"
+				return r
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewClient(ctx, WithBackend(&countingProcessor{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if err := c.Stop(); err != nil {
+					t.Errorf("failed to stop client: %v", err)
+				}
+			})
+
+			if err := c.Log(trace.ContextWithSpanContext(ctx, tc.spanContext), tc.logReq); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantLogReq, tc.logReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Log(%+v) got diff (-want, +got): %v", tc.logReq, diff)
+			}
+		})
+	}
+}
+
+func TestLog_Diagnostics(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	warning := &api.Diagnostic{Severity: api.Diagnostic_WARNING, Message: "backend warning"}
+
+	cases := []struct {
+		name      string
+		opts      []Option
+		wantDiags []*api.Diagnostic
+	}{
+		{
+			name: "success",
+			opts: []Option{WithBackend(testOrderProcessor{name: "backend"})},
+		},
+		{
+			name: "validator_precondition_failed",
+			opts: []Option{
+				WithValidator(testOrderProcessor{name: "validator", returnErr: fmt.Errorf("skip: %w", auditerrors.ErrPreconditionFailed)}),
+				WithBackend(testOrderProcessor{name: "backend"}),
+			},
+			wantDiags: []*api.Diagnostic{{
+				Severity: api.Diagnostic_INFO,
+				Message:  "stopped log request processing as validator precondition failed: skip: precondition failed",
+			}},
+		},
+		{
+			name: "best_effort_failure",
+			opts: []Option{
+				WithLogMode(api.AuditLogRequest_BEST_EFFORT),
+				WithBackend(testOrderProcessor{name: "backend", returnErr: fmt.Errorf("fake error")}),
+			},
+			wantDiags: []*api.Diagnostic{{
+				Severity: api.Diagnostic_WARNING,
+				Message:  "failed to audit log; continuing without audit logging: failed to execute backend audit.testOrderProcessor: fake error",
+			}},
+		},
+		{
+			name: "fail_close_failure",
+			opts: []Option{
+				WithLogMode(api.AuditLogRequest_FAIL_CLOSE),
+				WithBackend(testOrderProcessor{name: "backend", returnErr: fmt.Errorf("fake error")}),
+			},
+		},
+		{
+			name: "backend_diagnostics",
+			opts: []Option{
+				WithBackend(&diagnosticProcessor{diagnostics: []*api.Diagnostic{warning}}),
+			},
+			wantDiags: []*api.Diagnostic{warning},
+		},
+		{
+			name: "parallel_backend_diagnostics",
+			opts: []Option{
+				WithParallelBackends(),
+				WithBackend(&diagnosticProcessor{diagnostics: []*api.Diagnostic{warning}}),
+				WithBackend(testOrderProcessor{name: "backend"}),
+			},
+			wantDiags: []*api.Diagnostic{warning},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewClient(ctx, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, diags := diagnostics.NewContext(ctx)
+			logReq := testutil.NewRequest()
+			_ = c.Log(ctx, logReq)
+			if diff := cmp.Diff(tc.wantDiags, diags.Get(logReq), protocmp.Transform()); diff != "" {
+				t.Errorf("Log() diagnostics (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
 func TestHandleReturn_Client(t *testing.T) {
 	t.Parallel()
 
@@ -241,7 +419,7 @@ func TestHandleReturn_Client(t *testing.T) {
 				}
 			})
 
-			gotErr := c.handleReturn(ctx, tc.err, tc.logMode)
+			gotErr := c.handleReturn(ctx, &api.AuditLogRequest{Mode: tc.logMode}, tc.err)
 
 			if (gotErr != nil) != tc.wantErr {
 				expected := "an error"
@@ -0,0 +1,78 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// ErrorHandler is called with the log request that failed to be audit logged
+// and the swallowed error. The error identifies the processor that failed:
+// use errors.As with *ProcessorError for validators and mutators, and with
+// *BackendError for backends.
+//
+// The handler is called on the goroutine that processed the log request,
+// which is a background worker for an asynchronous client, so it must be safe
+// for concurrent use and should return quickly.
+type ErrorHandler func(ctx context.Context, logReq *api.AuditLogRequest, err error)
+
+// WithErrorHandler sets the handler called on every error that the client,
+// or an interceptor using the client, swallows because the log request is
+// best effort, e.g. to page or count the missing audit logs. Errors returned
+// to the caller are not passed to the handler.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(ctx context.Context, o *Client) error {
+		o.errorHandler = h
+		return nil
+	}
+}
+
+// ProcessorError is the error returned when a validator or a mutator failed
+// to process a log request.
+type ProcessorError struct {
+	// Stage is the stage of the processor: "validator" or "mutator".
+	Stage string
+
+	// Name is the processor's type.
+	Name string
+
+	// Err is the error returned by the processor.
+	Err error
+}
+
+func newProcessorError(stage string, p LogProcessor, err error) *ProcessorError {
+	return &ProcessorError{Stage: stage, Name: fmt.Sprintf("%T", p), Err: err}
+}
+
+// Error satisfies the error interface.
+func (e *ProcessorError) Error() string {
+	return fmt.Sprintf("failed to execute %s %s: %v", e.Stage, e.Name, e.Err)
+}
+
+// Unwrap returns the processor's error.
+func (e *ProcessorError) Unwrap() error {
+	return e.Err
+}
+
+// handleError passes the swallowed error to the error handler, if any.
+func (c *Client) handleError(ctx context.Context, logReq *api.AuditLogRequest, err error) {
+	if c == nil || c.errorHandler == nil {
+		return
+	}
+	c.errorHandler(ctx, logReq, err)
+}
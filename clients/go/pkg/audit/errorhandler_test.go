@@ -0,0 +1,243 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+)
+
+// handledError is a call to the error handler.
+type handledError struct {
+	Method string
+	Err    string
+}
+
+// errorRecorder records the calls to its error handler.
+type errorRecorder struct {
+	mu   sync.Mutex
+	got  []*handledError
+	errs []error
+}
+
+func (r *errorRecorder) handle(_ context.Context, logReq *api.AuditLogRequest, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, &handledError{Method: logReq.GetPayload().GetMethodName(), Err: err.Error()})
+	r.errs = append(r.errs, err)
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	fakeErr := fmt.Errorf("fake error")
+
+	cases := []struct {
+		name          string
+		mutator       LogProcessor
+		backendErr    error
+		logMode       api.AuditLogRequest_LogMode
+		want          []*handledError
+		wantProcessor *ProcessorError
+		wantBackends  []string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:    "mutator_failure",
+			mutator: &countingProcessor{returnErr: fakeErr},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			want: []*handledError{{
+				Method: "test-method",
+				Err:    "failed to execute mutator *audit.countingProcessor: fake error",
+			}},
+			wantProcessor: &ProcessorError{Stage: "mutator", Name: "*audit.countingProcessor", Err: fakeErr},
+		},
+		{
+			name:       "backend_failure",
+			backendErr: fakeErr,
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			want: []*handledError{{
+				Method: "test-method",
+				Err:    "failed to execute backend test-backend: fake error",
+			}},
+			wantBackends: []string{"test-backend"},
+		},
+		{
+			name:       "fail_close_error_not_swallowed",
+			backendErr: fakeErr,
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+		},
+		{
+			name:    "precondition_failure_not_an_error",
+			mutator: &countingProcessor{returnErr: auditerrors.ErrPreconditionFailed},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &errorRecorder{}
+			opts := []Option{
+				WithErrorHandler(r.handle),
+				WithBackend(&countingProcessor{returnErr: tc.backendErr}, WithBackendName("test-backend")),
+				WithLogMode(tc.logMode),
+			}
+			if tc.mutator != nil {
+				opts = append(opts, WithMutator(tc.mutator))
+			}
+			c, err := NewClient(ctx, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_ = c.Log(ctx, testutil.NewRequest(testutil.WithMethodName("test-method")))
+
+			if diff := cmp.Diff(tc.want, r.got); diff != "" {
+				t.Errorf("error handler calls got diff (-want, +got): %v", diff)
+			}
+			if tc.wantProcessor != nil {
+				var perr *ProcessorError
+				if !errors.As(r.errs[0], &perr) {
+					t.Fatalf("error handler got error %v, want *ProcessorError", r.errs[0])
+				}
+				if perr.Stage != tc.wantProcessor.Stage || perr.Name != tc.wantProcessor.Name || !errors.Is(perr.Err, tc.wantProcessor.Err) {
+					t.Errorf("error handler got processor error %#v, want %#v", perr, tc.wantProcessor)
+				}
+			}
+			if tc.wantBackends != nil {
+				var berr *BackendError
+				if !errors.As(r.errs[0], &berr) {
+					t.Fatalf("error handler got error %v, want *BackendError", r.errs[0])
+				}
+				var failed []string
+				for _, res := range berr.Results {
+					if res.Err != nil {
+						failed = append(failed, res.Name)
+					}
+				}
+				if diff := cmp.Diff(tc.wantBackends, failed); diff != "" {
+					t.Errorf("failed backends got diff (-want, +got): %v", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWithErrorHandler_Interceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	rules := []*api.AuditRule{{
+		Selector:  "*",
+		Directive: api.AuditRuleDirectiveDefault,
+		LogType:   "DATA_ACCESS",
+	}}
+
+	cases := []struct {
+		name       string
+		sc         *fakeSecurityContext
+		logMode    api.AuditLogRequest_LogMode
+		backendErr error
+		handlerErr error
+		want       []*handledError
+	}{
+		{
+			name:    "principal_failure_best_effort",
+			sc:      &fakeSecurityContext{err: fmt.Errorf("no principal")},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			want: []*handledError{{
+				Method: "/test.Service/Method",
+				Err:    "audit interceptor: rpc error: code = FailedPrecondition desc = failed to get request principal",
+			}},
+		},
+		{
+			name:    "principal_failure_fail_close",
+			sc:      &fakeSecurityContext{err: fmt.Errorf("no principal")},
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+		},
+		{
+			name:       "log_failure_best_effort",
+			sc:         &fakeSecurityContext{principal: "user@example.com"},
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			backendErr: fmt.Errorf("fake error"),
+			want: []*handledError{{
+				Method: "/test.Service/Method",
+				Err:    "failed to execute backend test-backend: fake error",
+			}},
+		},
+		{
+			// The log of a failed call is always best effort.
+			name:       "log_failure_after_handler_error",
+			sc:         &fakeSecurityContext{principal: "user@example.com"},
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+			backendErr: fmt.Errorf("fake error"),
+			handlerErr: fmt.Errorf("handler error"),
+			want: []*handledError{{
+				Method: "/test.Service/Method",
+				Err:    "failed to execute backend test-backend: fake error",
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &errorRecorder{}
+			c, err := NewClient(ctx,
+				WithErrorHandler(r.handle),
+				WithBackend(&countingProcessor{returnErr: tc.backendErr}, WithBackendName("test-backend")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			i, err := NewInterceptor(ctx,
+				WithAuditClient(c),
+				WithAuditRules(rules...),
+				WithSecurityContext(tc.sc),
+				WithInterceptorLogMode(tc.logMode))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _ = i.UnaryInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+				if logReq, ok := LogReqFromCtx(ctx); ok {
+					logReq.Payload.ResourceName = "test-resource"
+				}
+				return nil, tc.handlerErr
+			})
+
+			if diff := cmp.Diff(tc.want, r.got); diff != "" {
+				t.Errorf("error handler calls got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	capi "google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/genproto/googleapis/rpc/context/attribute_context"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/justification"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/security"
+	"github.com/abcxyz/pkg/logging"
+)
+
+// maxHTTPBodySize is the max size of an HTTP request or response body
+// captured in an audit log. Larger bodies are truncated. It's also the max
+// size of a response held in fail-close mode.
+const maxHTTPBodySize = 64 << 10
+
+// HTTPHandler returns a middleware that automatically emits application audit
+// logs for the HTTP requests to the given handler, as UnaryInterceptor does
+// for gRPC calls.
+//
+// The audit rule selectors match either the request method and path, e.g.
+// "GET /v1/users/*", or the path of any method, e.g. "/v1/users/*". The
+// security context must implement security.HTTPContext to get the principal
+// from the request headers. The request and response bodies are captured per
+// the rule directive, up to 64 KiB, and an HTTP error status is recorded as
+// the audit log status. The handler can get the audit log request with
+// LogReqFromCtx, e.g. to set the resource name, which defaults to the path.
+//
+// In fail-close mode, the response is held until the audit log is written, so
+// that a failure to audit log is returned instead, unless the handler flushes
+// the response first.
+func (i *Interceptor) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.serveHTTP(w, r, next)
+	})
+}
+
+func (i *Interceptor) serveHTTP(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	methodName := r.Method + " " + r.URL.Path
+	rule := mostRelevantHTTPRule(r, i.rules)
+	if rule == nil {
+		logger.DebugContext(ctx, "no audit rule matching the method name",
+			"method_name", methodName,
+			"audit_rules", i.rules)
+		// The path isn't a metrics attribute, as it's unbounded.
+		i.metrics.recordCall(ctx, r.Method, "", outcomeSkipped)
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	outcome := outcomeSuccess
+	defer func() {
+		i.metrics.recordCall(ctx, r.Method+" "+rule.Selector, rule.LogType, outcome)
+	}()
+
+	logReq := &api.AuditLogRequest{
+		Type: api.AuditLogRequest_UNSPECIFIED,
+		Payload: &capi.AuditLog{
+			ServiceName:     r.Host,
+			MethodName:      methodName,
+			ResourceName:    r.URL.Path,
+			RequestMetadata: httpRequestMetadata(r),
+		},
+		Mode:               i.logMode,
+		Timestamp:          timestamppb.New(time.Now().UTC()),
+		JustificationToken: strings.TrimSpace(r.Header.Get(justification.TokenHeaderKey)),
+	}
+	if t, ok := api.AuditLogRequest_LogType_value[rule.LogType]; ok {
+		logReq.Type = api.AuditLogRequest_LogType(t)
+	}
+
+	// Autofill `Payload.AuthenticationInfo.PrincipalEmail`.
+	principal, err := i.httpRequestPrincipal(r)
+	if err != nil {
+		logger.ErrorContext(ctx, "audit interceptor failed to get request principal",
+			"security_context", i.sc,
+			"error", err)
+		outcome = i.failureOutcome()
+		i.handleReturnHTTP(w, r, next, logReq, auditerrors.InterceptorError(
+			status.Errorf(codes.FailedPrecondition, "failed to get request principal")))
+		return
+	}
+	logReq.Payload.AuthenticationInfo = &capi.AuthenticationInfo{PrincipalEmail: principal}
+
+	// Autofill `Payload.Request`.
+	if shouldLogReq(rule) {
+		body, truncated, err := captureRequestBody(r)
+		if err != nil {
+			outcome = i.failureOutcome()
+			i.handleReturnHTTP(w, r, next, logReq, auditerrors.InterceptorError(
+				status.Errorf(codes.Internal, "failed to read request body: %v", err)))
+			return
+		}
+		logReq.Payload.Request = bodyToStruct(body, truncated)
+	}
+
+	// Store our log req in the context to make it accessible
+	// to the handler source code.
+	ctx = context.WithValue(ctx, auditLogReqKey{}, logReq)
+
+	rw := &httpResponseWriter{
+		ResponseWriter: w,
+		capture:        shouldLogResp(rule),
+		hold:           api.ShouldFailClose(i.logMode),
+	}
+	next.ServeHTTP(rw, r.WithContext(ctx))
+	if rw.overflowed {
+		logger.WarnContext(ctx, "released the response before audit logging as it exceeded the held size; fail-close no longer applies",
+			"max_held_size", maxHTTPBodySize)
+	}
+
+	if rw.status >= http.StatusBadRequest {
+		setHTTPErrorStatus(logReq, rw.status)
+
+		// Best effort log the error.
+		if err := i.Log(ctx, logReq); err != nil {
+			outcome = outcomeDropped
+			logger.ErrorContext(ctx, "unable to audit log error", "error", err)
+			i.handleError(ctx, logReq, err)
+		}
+		rw.release()
+		return
+	}
+
+	// Autofill `Payload.Response`.
+	if rw.capture {
+		logReq.Payload.Response = bodyToStruct(rw.body.Bytes(), rw.truncated)
+	}
+
+	if err := i.Log(ctx, logReq); err != nil {
+		outcome = i.failureOutcome()
+		serr := auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err))
+		if api.ShouldFailClose(i.logMode) && rw.discard() {
+			writeHTTPError(w, serr)
+			return
+		}
+		logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
+			"error", serr)
+		i.handleError(ctx, logReq, serr)
+	}
+	rw.release()
+}
+
+// httpRequestPrincipal gets the principal of the HTTP request from the
+// security context.
+func (i *Interceptor) httpRequestPrincipal(r *http.Request) (string, error) {
+	sc, ok := i.sc.(security.HTTPContext)
+	if !ok {
+		return "", fmt.Errorf("security context %T doesn't support HTTP requests", i.sc)
+	}
+	return sc.HTTPRequestPrincipal(r) //nolint:wrapcheck // Want passthrough
+}
+
+// handleReturnHTTP handles a failure to audit log before calling the handler,
+// like handleReturnUnary: it writes the error in fail-close mode, or calls the
+// handler otherwise.
+func (i *Interceptor) handleReturnHTTP(w http.ResponseWriter, r *http.Request, next http.Handler, logReq *api.AuditLogRequest, err error) {
+	if api.ShouldFailClose(i.logMode) {
+		writeHTTPError(w, err)
+		return
+	}
+	// There was an error, but we are failing open.
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+	logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
+		"error", err)
+	i.handleError(ctx, logReq, err)
+	next.ServeHTTP(w, r)
+}
+
+// mostRelevantHTTPRule finds the most relevant rule for the HTTP request,
+// among the rules whose selector matches either its method and path, or its
+// path.
+func mostRelevantHTTPRule(r *http.Request, rules []*api.AuditRule) *api.AuditRule {
+	methodRule := mostRelevantRule(r.Method+" "+r.URL.Path, rules)
+	pathRule := mostRelevantRule(r.URL.Path, rules)
+	if pathRule != nil && (methodRule == nil || len(pathRule.Selector) > len(methodRule.Selector)) {
+		return pathRule
+	}
+	return methodRule
+}
+
+// httpRequestMetadata returns the request metadata of the HTTP request.
+func httpRequestMetadata(r *http.Request) *capi.RequestMetadata {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	callerIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		callerIP = host
+	}
+	return &capi.RequestMetadata{
+		CallerIp:                callerIP,
+		CallerSuppliedUserAgent: r.UserAgent(),
+		RequestAttributes: &attribute_context.AttributeContext_Request{
+			Method:   r.Method,
+			Scheme:   scheme,
+			Host:     r.Host,
+			Path:     r.URL.Path,
+			Query:    r.URL.RawQuery,
+			Protocol: r.Proto,
+			Size:     r.ContentLength,
+		},
+	}
+}
+
+// captureRequestBody reads up to maxHTTPBodySize bytes of the request body,
+// and reports whether the body is longer. The handler still reads the whole
+// body, even if reading it failed, in which case the handler gets the bytes
+// read so far and then the read error.
+func captureRequestBody(r *http.Request) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, false, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+	if err != nil {
+		return nil, false, err //nolint:wrapcheck // Wrapped by the caller.
+	}
+
+	if len(b) > maxHTTPBodySize {
+		return b[:maxHTTPBodySize], true, nil
+	}
+	return b, false, nil
+}
+
+// bodyToStruct converts a captured HTTP body into a proto struct. A JSON
+// object is converted as is, any other body is kept as the "body" string
+// field. It returns nil for an empty body.
+func bodyToStruct(b []byte, truncated bool) *structpb.Struct {
+	if len(b) == 0 {
+		return nil
+	}
+	var m map[string]any
+	if !truncated && json.Unmarshal(b, &m) == nil && m != nil {
+		return jsonMapToProtoStruct(m)
+	}
+
+	fields := map[string]*structpb.Value{
+		"body": structpb.NewStringValue(strings.ToValidUTF8(string(b), "\uFFFD")),
+	}
+	if truncated {
+		fields["truncated"] = structpb.NewBoolValue(true)
+	}
+	return &structpb.Struct{Fields: fields}
+}
+
+// setHTTPErrorStatus sets the status of the log request from the HTTP error
+// status code.
+func setHTTPErrorStatus(logReq *api.AuditLogRequest, code int) {
+	logReq.Payload.Status = &rpcstatus.Status{
+		Code:    int32(httpStatusToCode(code)),
+		Message: http.StatusText(code),
+	}
+}
+
+// httpStatusToCode maps an HTTP error status code to the closest gRPC code,
+// see https://cloud.google.com/apis/design/errors#handling_errors.
+func httpStatusToCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499: // Client closed request.
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if code >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// writeHTTPError writes the interceptor error as the HTTP response, with the
+// message of its gRPC status.
+func writeHTTPError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	code := http.StatusInternalServerError
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		st := se.GRPCStatus()
+		msg = st.Message()
+		if st.Code() == codes.FailedPrecondition {
+			code = http.StatusBadRequest
+		}
+	}
+	http.Error(w, msg, code)
+}
+
+// httpResponseWriter records the status of the response and, if capture is
+// set, the start of its body. If hold is set, the response is held until
+// release, so that it can be discarded, unless its body exceeds
+// maxHTTPBodySize, in which case it's released rather than buffered.
+type httpResponseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+
+	capture   bool
+	body      bytes.Buffer
+	truncated bool
+
+	hold bool
+	held bytes.Buffer
+	// overflowed is set if the response was released as it was too large to
+	// hold.
+	overflowed bool
+}
+
+// WriteHeader records the status code.
+func (w *httpResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	if !w.hold {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// Write records the body.
+func (w *httpResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.capture {
+		n := min(len(b), maxHTTPBodySize-w.body.Len())
+		w.body.Write(b[:n])
+		w.truncated = w.truncated || n < len(b)
+	}
+	if w.hold {
+		if w.held.Len()+len(b) <= maxHTTPBodySize {
+			return w.held.Write(b) //nolint:wrapcheck // Writing to a buffer never fails.
+		}
+		w.overflowed = true
+		w.release()
+	}
+	return w.ResponseWriter.Write(b) //nolint:wrapcheck // Want passthrough
+}
+
+// Flush releases the response, which can't be discarded afterwards, and
+// flushes it.
+func (w *httpResponseWriter) Flush() {
+	w.release()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the original response writer, for http.ResponseController.
+func (w *httpResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// release writes the held response, if any.
+func (w *httpResponseWriter) release() {
+	if !w.hold {
+		return
+	}
+	w.hold = false
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.held.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.held.Bytes())
+		w.held.Reset()
+	}
+}
+
+// discard drops the held response and its headers, and reports whether the
+// response was held.
+func (w *httpResponseWriter) discard() bool {
+	if !w.hold {
+		return false
+	}
+	w.hold = false
+	w.held.Reset()
+	clear(w.ResponseWriter.Header())
+	return true
+}
@@ -0,0 +1,495 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	capi "google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/genproto/googleapis/rpc/context/attribute_context"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/security"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+)
+
+// recordingProcessor records a copy of the log requests it processes and
+// returns the given error.
+type recordingProcessor struct {
+	returnErr error
+
+	mu      sync.Mutex
+	logReqs []*api.AuditLogRequest
+}
+
+func (p *recordingProcessor) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logReqs = append(p.logReqs, proto.Clone(logReq).(*api.AuditLogRequest)) //nolint:forcetypeassert // Only for testing
+	return p.returnErr
+}
+
+func TestHTTPHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	jwt := "Bearer " + testutil.JWTFromClaims(t, map[string]interface{}{
+		"email": "user@example.com",
+	})
+	largeBody := strings.Repeat("a", maxHTTPBodySize+1)
+
+	// wantPayload returns the payload of an audit log request for the
+	// request built by the test.
+	wantPayload := func(method string, size int64) *capi.AuditLog {
+		return &capi.AuditLog{
+			ServiceName:  "api.example.com",
+			MethodName:   method + " /v1/users/123",
+			ResourceName: "/v1/users/123",
+			AuthenticationInfo: &capi.AuthenticationInfo{
+				PrincipalEmail: "user@example.com",
+			},
+			RequestMetadata: &capi.RequestMetadata{
+				CallerIp:                "192.0.2.1",
+				CallerSuppliedUserAgent: "test-agent",
+				RequestAttributes: &attribute_context.AttributeContext_Request{
+					Method:   method,
+					Scheme:   "http",
+					Host:     "api.example.com",
+					Path:     "/v1/users/123",
+					Query:    "view=full",
+					Protocol: "HTTP/1.1",
+					Size:     size,
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name       string
+		auditRules []*api.AuditRule
+		logMode    api.AuditLogRequest_LogMode
+		method     string
+		body       string
+		bodyErr    error
+		noAuth     bool
+		handler    http.HandlerFunc
+		backendErr error
+		wantStatus int
+		wantBody   string
+		wantLogReq *api.AuditLogRequest
+	}{
+		{
+			name: "autofills_request_and_response",
+			auditRules: []*api.AuditRule{{
+				Selector:  "POST /v1/users/*",
+				Directive: api.AuditRuleDirectiveRequestAndResponse,
+				LogType:   "ADMIN_ACTIVITY",
+			}},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			method:  http.MethodPost,
+			body:    `{"name":"alice"}`,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// The handler still reads the body.
+				if b, _ := io.ReadAll(r.Body); string(b) != `{"name":"alice"}` {
+					http.Error(w, "unexpected body", http.StatusBadRequest)
+					return
+				}
+				logReq, _ := LogReqFromCtx(r.Context())
+				logReq.Payload.ResourceName = "users/123"
+				fmt.Fprint(w, `{"id":"123"}`)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":"123"}`,
+			wantLogReq: func() *api.AuditLogRequest {
+				p := wantPayload(http.MethodPost, 16)
+				p.ResourceName = "users/123"
+				p.Request = &structpb.Struct{Fields: map[string]*structpb.Value{
+					"name": structpb.NewStringValue("alice"),
+				}}
+				p.Response = &structpb.Struct{Fields: map[string]*structpb.Value{
+					"id": structpb.NewStringValue("123"),
+				}}
+				return &api.AuditLogRequest{
+					Type:               api.AuditLogRequest_ADMIN_ACTIVITY,
+					Payload:            p,
+					Mode:               api.AuditLogRequest_BEST_EFFORT,
+					JustificationToken: "justification",
+				}
+			}(),
+		},
+		{
+			name: "path_selector_matches_any_method",
+			auditRules: []*api.AuditRule{{
+				Selector:  "GET /v1/*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "ADMIN_ACTIVITY",
+			}, {
+				Selector:  "/v1/users/*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantLogReq: &api.AuditLogRequest{
+				Type:               api.AuditLogRequest_DATA_ACCESS,
+				Payload:            wantPayload(http.MethodGet, 0),
+				Mode:               api.AuditLogRequest_BEST_EFFORT,
+				JustificationToken: "justification",
+			},
+		},
+		{
+			name: "method_mismatch_skipped",
+			auditRules: []*api.AuditRule{{
+				Selector:  "GET /v1/*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "ADMIN_ACTIVITY",
+			}},
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+			method:     http.MethodDelete,
+			noAuth:     true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "non_json_and_truncated_body",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveRequestOnly,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			method:  http.MethodPut,
+			body:    largeBody,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if b, _ := io.ReadAll(r.Body); len(b) != len(largeBody) {
+					http.Error(w, "unexpected body", http.StatusBadRequest)
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantLogReq: func() *api.AuditLogRequest {
+				p := wantPayload(http.MethodPut, int64(len(largeBody)))
+				p.Request = &structpb.Struct{Fields: map[string]*structpb.Value{
+					"body":      structpb.NewStringValue(largeBody[:maxHTTPBodySize]),
+					"truncated": structpb.NewBoolValue(true),
+				}}
+				return &api.AuditLogRequest{
+					Type:               api.AuditLogRequest_DATA_ACCESS,
+					Payload:            p,
+					Mode:               api.AuditLogRequest_BEST_EFFORT,
+					JustificationToken: "justification",
+				}
+			}(),
+		},
+		{
+			name: "error_status",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveRequestAndResponse,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+			method:  http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "no such user", http.StatusNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "no such user\n",
+			wantLogReq: func() *api.AuditLogRequest {
+				p := wantPayload(http.MethodGet, 0)
+				p.Status = &rpcstatus.Status{
+					Code:    int32(codes.NotFound),
+					Message: "Not Found",
+				}
+				return &api.AuditLogRequest{
+					Type:               api.AuditLogRequest_DATA_ACCESS,
+					Payload:            p,
+					Mode:               api.AuditLogRequest_FAIL_CLOSE,
+					JustificationToken: "justification",
+				}
+			}(),
+		},
+		{
+			name: "principal_failure_fail_close",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+			method:     http.MethodGet,
+			noAuth:     true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "failed to get request principal\n",
+		},
+		{
+			name: "principal_failure_best_effort",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			method:     http.MethodGet,
+			noAuth:     true,
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name: "request_body_failure_fail_close",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveRequestAndResponse,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+			method:     http.MethodPost,
+			body:       "partial",
+			bodyErr:    fmt.Errorf("injected error"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "failed to read request body: injected error\n",
+		},
+		{
+			name: "request_body_failure_best_effort",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveRequestAndResponse,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			method:  http.MethodPost,
+			body:    "partial",
+			bodyErr: fmt.Errorf("injected error"),
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// The handler still reads the bytes read by the interceptor,
+				// then the error.
+				b, err := io.ReadAll(r.Body)
+				if err == nil {
+					http.Error(w, "expected read error", http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write(b)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "partial",
+		},
+		{
+			name: "log_failure_fail_close",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+			method:     http.MethodGet,
+			backendErr: fmt.Errorf("injected error"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "failed to emit log: failed to execute backend *audit.recordingProcessor: injected error\n",
+			wantLogReq: &api.AuditLogRequest{
+				Type:               api.AuditLogRequest_DATA_ACCESS,
+				Payload:            wantPayload(http.MethodGet, 0),
+				Mode:               api.AuditLogRequest_FAIL_CLOSE,
+				JustificationToken: "justification",
+			},
+		},
+		{
+			name: "log_failure_fail_close_after_flush",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+			method:  http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "partial")
+				if err := http.NewResponseController(w).Flush(); err != nil {
+					panic(err)
+				}
+				fmt.Fprint(w, " response")
+			},
+			backendErr: fmt.Errorf("injected error"),
+			wantStatus: http.StatusOK,
+			wantBody:   "partial response",
+			wantLogReq: &api.AuditLogRequest{
+				Type:               api.AuditLogRequest_DATA_ACCESS,
+				Payload:            wantPayload(http.MethodGet, 0),
+				Mode:               api.AuditLogRequest_FAIL_CLOSE,
+				JustificationToken: "justification",
+			},
+		},
+		{
+			name: "log_failure_fail_close_large_response",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+			method:  http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// The response is released once it exceeds the held size.
+				fmt.Fprint(w, largeBody[:maxHTTPBodySize])
+				fmt.Fprint(w, "a")
+			},
+			backendErr: fmt.Errorf("injected error"),
+			wantStatus: http.StatusOK,
+			wantBody:   largeBody,
+			wantLogReq: &api.AuditLogRequest{
+				Type:               api.AuditLogRequest_DATA_ACCESS,
+				Payload:            wantPayload(http.MethodGet, 0),
+				Mode:               api.AuditLogRequest_FAIL_CLOSE,
+				JustificationToken: "justification",
+			},
+		},
+		{
+			name: "log_failure_best_effort",
+			auditRules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			method:     http.MethodGet,
+			backendErr: fmt.Errorf("injected error"),
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+			wantLogReq: &api.AuditLogRequest{
+				Type:               api.AuditLogRequest_DATA_ACCESS,
+				Payload:            wantPayload(http.MethodGet, 0),
+				Mode:               api.AuditLogRequest_BEST_EFFORT,
+				JustificationToken: "justification",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &recordingProcessor{returnErr: tc.backendErr}
+			c, err := NewClient(ctx, WithBackend(p))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if err := c.Stop(); err != nil {
+					t.Errorf("failed to stop client: %v", err)
+				}
+			})
+
+			i, err := NewInterceptor(ctx,
+				WithAuditClient(c),
+				WithAuditRules(tc.auditRules...),
+				WithInterceptorLogMode(tc.logMode),
+				WithSecurityContext(&security.FromRawJWT{
+					FromRawJWT: []*api.FromRawJWT{{
+						Key:    "authorization",
+						Prefix: "Bearer ",
+					}},
+				}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := tc.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, "ok")
+				}
+			}
+
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			if tc.bodyErr != nil {
+				body = io.MultiReader(body, iotest.ErrReader(tc.bodyErr))
+			}
+			req := httptest.NewRequestWithContext(ctx, tc.method, "http://api.example.com/v1/users/123?view=full", body)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("User-Agent", "test-agent")
+			req.Header.Set("Justification-Token", "justification")
+			if !tc.noAuth {
+				req.Header.Set("Authorization", jwt)
+			}
+			rec := httptest.NewRecorder()
+
+			i.HTTPHandler(handler).ServeHTTP(rec, req)
+
+			if got, want := rec.Code, tc.wantStatus; got != want {
+				t.Errorf("HTTPHandler(...) got status %d, want %d", got, want)
+			}
+			if tc.wantBody != "" {
+				if got, want := rec.Body.String(), tc.wantBody; got != want {
+					t.Errorf("HTTPHandler(...) got body %q, want %q", got, want)
+				}
+			}
+
+			var gotLogReq *api.AuditLogRequest
+			if len(p.logReqs) > 0 {
+				gotLogReq = p.logReqs[0]
+			}
+			if diff := cmp.Diff(tc.wantLogReq, gotLogReq, protocmp.Transform(), protocmp.IgnoreFields(&api.AuditLogRequest{}, "timestamp")); diff != "" {
+				t.Errorf("HTTPHandler(...) got diff in automatically emitted LogReq (-want, +got): %v", diff)
+			}
+			if gotLogReq != nil && gotLogReq.GetTimestamp() == nil {
+				t.Error("HTTPHandler(...) gotLogReq missing timestamp")
+			}
+		})
+	}
+}
+
+func TestHTTPStatusToCode(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		status int
+		want   codes.Code
+	}{
+		{status: http.StatusBadRequest, want: codes.InvalidArgument},
+		{status: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{status: http.StatusForbidden, want: codes.PermissionDenied},
+		{status: http.StatusTooManyRequests, want: codes.ResourceExhausted},
+		{status: http.StatusTeapot, want: codes.Unknown},
+		{status: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{status: http.StatusBadGateway, want: codes.Internal},
+	}
+
+	for _, tc := range cases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			t.Parallel()
+
+			if got := httpStatusToCode(tc.status); got != tc.want {
+				t.Errorf("httpStatusToCode(%d) = %v, want %v", tc.status, got, tc.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -25,6 +26,7 @@ import (
 
 	"cloud.google.com/go/logging/apiv2/loggingpb"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/metric"
 	capi "google.golang.org/genproto/googleapis/cloud/audit"
 	rpccode "google.golang.org/genproto/googleapis/rpc/code"
 	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
@@ -75,6 +77,15 @@ func WithAuditRules(rs ...*api.AuditRule) InterceptorOption {
 	}
 }
 
+// WithClientAuditRules configures the interceptor to use the given rules to
+// match outbound methods, see UnaryClientInterceptor.
+func WithClientAuditRules(rs ...*api.AuditRule) InterceptorOption {
+	return func(ctx context.Context, i *Interceptor) error {
+		i.clientRules = rs
+		return nil
+	}
+}
+
 // WithInterceptorLogMode configures the interceptor to honor the given log mode.
 func WithInterceptorLogMode(m api.AuditLogRequest_LogMode) InterceptorOption {
 	return func(ctx context.Context, i *Interceptor) error {
@@ -87,9 +98,13 @@ func WithInterceptorLogMode(m api.AuditLogRequest_LogMode) InterceptorOption {
 // to autofill and emit audit logs.
 type Interceptor struct {
 	*Client
-	sc      security.GRPCContext
-	rules   []*api.AuditRule
-	logMode api.AuditLogRequest_LogMode
+	sc          security.GRPCContext
+	rules       []*api.AuditRule
+	clientRules []*api.AuditRule
+	logMode     api.AuditLogRequest_LogMode
+
+	meterProvider metric.MeterProvider
+	metrics       *interceptorMetrics
 }
 
 // NewInterceptor creates a new interceptor with the given options.
@@ -100,6 +115,11 @@ func NewInterceptor(ctx context.Context, opts ...InterceptorOption) (*Intercepto
 			return nil, fmt.Errorf("failed to apply interceptor option: %w", err)
 		}
 	}
+	m, err := newInterceptorMetrics(it.meterProvider)
+	if err != nil {
+		return nil, err
+	}
+	it.metrics = m
 	return &it, nil
 }
 
@@ -113,23 +133,30 @@ func (i *Interceptor) UnaryInterceptor(ctx context.Context, req any, info *grpc.
 			"method_name", info.FullMethod,
 			"audit_rules", i.rules)
 		// Interceptor not applied to this method, continue
+		i.metrics.recordCall(ctx, info.FullMethod, "", outcomeSkipped)
 		return handler(ctx, req)
 	}
 
-	serviceName, err := serviceName(info.FullMethod)
-	if err != nil {
-		return i.handleReturnUnary(ctx, req, handler, auditerrors.InterceptorError(status.Error(codes.FailedPrecondition, err.Error())))
-	}
+	outcome := outcomeSuccess
+	defer func() {
+		i.metrics.recordCall(ctx, info.FullMethod, r.LogType, outcome)
+	}()
 
 	logReq := &api.AuditLogRequest{
 		Payload: &capi.AuditLog{
-			ServiceName: serviceName,
-			MethodName:  info.FullMethod,
+			MethodName: info.FullMethod,
 		},
 		Mode:      i.logMode,
 		Timestamp: timestamppb.New(time.Now().UTC()),
 	}
 
+	serviceName, err := serviceName(info.FullMethod)
+	if err != nil {
+		outcome = i.failureOutcome()
+		return i.handleReturnUnary(ctx, req, handler, logReq, auditerrors.InterceptorError(status.Error(codes.FailedPrecondition, err.Error())))
+	}
+	logReq.Payload.ServiceName = serviceName
+
 	// Set JVS Token
 	fillJVSToken(ctx, logReq)
 
@@ -146,14 +173,16 @@ func (i *Interceptor) UnaryInterceptor(ctx context.Context, req any, info *grpc.
 			"security_context", i.sc,
 			"error", err)
 		serr := auditerrors.InterceptorError(status.Errorf(codes.FailedPrecondition, "failed to get request principal"))
-		return i.handleReturnUnary(ctx, req, handler, serr)
+		outcome = i.failureOutcome()
+		return i.handleReturnUnary(ctx, req, handler, logReq, serr)
 	}
 	logReq.Payload.AuthenticationInfo = &capi.AuthenticationInfo{PrincipalEmail: principal}
 
 	// Autofill `Payload.Request`.
 	if shouldLogReq(r) {
 		if err := setReq(logReq, req); err != nil {
-			return i.handleReturnUnary(ctx, req, handler, auditerrors.InterceptorError(
+			outcome = i.failureOutcome()
+			return i.handleReturnUnary(ctx, req, handler, logReq, auditerrors.InterceptorError(
 				status.Errorf(codes.Internal, "failed to convert req into a Google struct proto: %v", err)))
 		}
 	}
@@ -172,7 +201,9 @@ func (i *Interceptor) UnaryInterceptor(ctx context.Context, req any, info *grpc.
 
 		// Best effort log the error.
 		if err := i.Log(ctx, logReq); err != nil {
+			outcome = outcomeDropped
 			logger.ErrorContext(ctx, "unable to audit log error", "error", err)
+			i.handleError(ctx, logReq, err)
 		}
 		return resp, handlerErr
 	}
@@ -180,13 +211,15 @@ func (i *Interceptor) UnaryInterceptor(ctx context.Context, req any, info *grpc.
 	// Autofill `Payload.Response`.
 	if shouldLogResp(r) {
 		if err := setResp(logReq, resp); err != nil {
-			return i.handleReturnWithResponse(ctx, resp,
+			outcome = i.failureOutcome()
+			return i.handleReturnWithResponse(ctx, resp, logReq,
 				auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to convert resp into a Google struct proto: %v", err)))
 		}
 	}
 
 	if err := i.Log(ctx, logReq); err != nil {
-		return i.handleReturnWithResponse(ctx, resp,
+		outcome = i.failureOutcome()
+		return i.handleReturnWithResponse(ctx, resp, logReq,
 			auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err)))
 	}
 
@@ -203,19 +236,19 @@ func (i *Interceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, i
 		logger.DebugContext(ctx, "no audit rule matching the method name",
 			"method_name", info.FullMethod,
 			"audit_rules", i.rules)
+		i.metrics.recordCall(ctx, info.FullMethod, "", outcomeSkipped)
 		return handler(srv, ss)
 	}
 
-	serviceName, err := serviceName(info.FullMethod)
-	if err != nil {
-		return i.handleReturnStream(ctx, ss, handler, auditerrors.InterceptorError(status.Error(codes.FailedPrecondition, err.Error())))
-	}
+	outcome := outcomeSuccess
+	defer func() {
+		i.metrics.recordCall(ctx, info.FullMethod, r.LogType, outcome)
+	}()
 
 	// Build a baseline log request to be shared by all stream calls.
 	logReq := &api.AuditLogRequest{
 		Payload: &capi.AuditLog{
-			ServiceName: serviceName,
-			MethodName:  info.FullMethod,
+			MethodName: info.FullMethod,
 		},
 		// Set operation to associate logs from the same stream.
 		Operation: &loggingpb.LogEntryOperation{
@@ -225,6 +258,13 @@ func (i *Interceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, i
 		Timestamp: timestamppb.New(time.Now().UTC()),
 	}
 
+	serviceName, err := serviceName(info.FullMethod)
+	if err != nil {
+		outcome = i.failureOutcome()
+		return i.handleReturnStream(ctx, ss, handler, logReq, auditerrors.InterceptorError(status.Error(codes.FailedPrecondition, err.Error())))
+	}
+	logReq.Payload.ServiceName = serviceName
+
 	// Set JVS Token
 	fillJVSToken(ctx, logReq)
 
@@ -241,28 +281,60 @@ func (i *Interceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, i
 			"security_context", i.sc,
 			"error", err)
 		serr := status.Errorf(codes.FailedPrecondition, "audit interceptor failed to get request principal")
-		return i.handleReturnStream(ctx, ss, handler, serr)
+		outcome = i.failureOutcome()
+		return i.handleReturnStream(ctx, ss, handler, logReq, serr)
 	}
 	logReq.Payload.AuthenticationInfo = &capi.AuthenticationInfo{PrincipalEmail: principal}
 
-	handlerErr := handler(srv, &serverStreamWrapper{
+	ssw := &serverStreamWrapper{
 		c:              i.Client,
 		baselineLogReq: logReq,
 		rule:           r,
 		ServerStream:   ss,
-	})
-	if handlerErr != nil {
-		i.setErrorStatus(handlerErr, logReq)
-
-		// Best effort log the error.
-		if err := i.Log(ctx, logReq); err != nil {
-			logger.ErrorContext(ctx, "unable to audit log error",
-				"error", err)
+	}
+	if r.Directive == api.AuditRuleDirectiveStreamSummary {
+		ssw.summary = &streamSummary{start: time.Now()}
+	}
+	handlerErr := handler(srv, ssw)
+	// Audit log failures of the stream messages are returned to the handler as
+	// interceptor errors.
+	if errors.Is(handlerErr, auditerrors.ErrInterceptor) {
+		outcome = outcomeFailed
+	}
+
+	// Log the end of the stream, the last audit log of which holds the final
+	// status: the summary in summary mode, which is the only audit log of the
+	// stream, or the held back audit log of the last stream message.
+	closeReqs, err := ssw.closeLogReqs()
+	closeReq := logReq
+	if err == nil {
+		i.setStreamStatus(handlerErr, closeReqs[len(closeReqs)-1])
+		for _, closeReq = range closeReqs {
+			if err = i.Log(ctx, closeReq); err != nil {
+				err = auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err))
+				break
+			}
+		}
+	}
+	if err != nil {
+		outcome = i.failureOutcome()
+		if _, err := i.handleReturnWithResponse(ctx, nil, closeReq, err); err != nil {
+			return err
 		}
 	}
 	return handlerErr
 }
 
+// setStreamStatus sets the final status of a stream, which is OK unless the
+// handler returned an error.
+func (i *Interceptor) setStreamStatus(handlerErr error, logReq *api.AuditLogRequest) {
+	if handlerErr != nil {
+		i.setErrorStatus(handlerErr, logReq)
+		return
+	}
+	logReq.Payload.Status = &rpcstatus.Status{Code: int32(codes.OK)}
+}
+
 // fillJVSToken looks for the JVS token on the request header and injects it
 // into the log request, if it was present.
 func fillJVSToken(ctx context.Context, logReq *api.AuditLogRequest) {
@@ -293,6 +365,19 @@ type serverStreamWrapper struct {
 	// As a result, (per stream) we will only have one last received request at a time.
 	mu      sync.Mutex
 	lastReq interface{}
+
+	// held is the audit log of the last stream message, which is held back
+	// until the next one, so that it can be marked as the last of the
+	// operation once the handler returns.
+	held *api.AuditLogRequest
+
+	// logged is whether an audit log was emitted for the stream, so that only
+	// the first one is marked as first in its operation.
+	logged bool
+
+	// summary collects the stream messages with AUDIT_STREAM_SUMMARY rules, in
+	// which case no audit log is emitted per message. It's nil otherwise.
+	summary *streamSummary
 }
 
 func (ss *serverStreamWrapper) swapLastReq(m interface{}) interface{} {
@@ -311,6 +396,81 @@ func (ss *serverStreamWrapper) popLastReq() interface{} {
 	return m
 }
 
+// newLogReq returns a copy of the baseline log request for the next audit log
+// of the stream.
+func (ss *serverStreamWrapper) newLogReq() (*api.AuditLogRequest, error) {
+	logReq, ok := proto.Clone(ss.baselineLogReq).(*api.AuditLogRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected *api.AuditLogRequest")
+	}
+	return logReq, nil
+}
+
+// markFirstLocked marks the audit log as the first of the operation if it is.
+// It must be called with mu held, in the order the audit logs are emitted.
+func (ss *serverStreamWrapper) markFirstLocked(logReq *api.AuditLogRequest) {
+	logReq.Operation.First = !ss.logged
+	ss.logged = true
+}
+
+// hold holds back the audit log of a stream message, and emits the previously
+// held one.
+func (ss *serverStreamWrapper) hold(logReq *api.AuditLogRequest) error {
+	ss.mu.Lock()
+	ss.markFirstLocked(logReq)
+	prev := ss.held
+	ss.held = logReq
+	ss.mu.Unlock()
+
+	if prev == nil {
+		return nil
+	}
+	if err := ss.c.Log(ss.ServerStream.Context(), prev); err != nil {
+		return auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err)) //nolint:wrapcheck
+	}
+	return nil
+}
+
+// closeLogReqs returns the audit logs left to emit once the handler returned,
+// the last of which is marked as the last of the operation. In summary mode,
+// it's the summary of the stream. Otherwise, they're the held back audit log,
+// followed by the last received request if it wasn't logged with a response.
+// If there are none, a single audit log marks the end of the stream.
+func (ss *serverStreamWrapper) closeLogReqs() ([]*api.AuditLogRequest, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var logReqs []*api.AuditLogRequest
+	if ss.held != nil {
+		logReqs = append(logReqs, ss.held)
+		ss.held = nil
+	}
+	lr := ss.lastReq
+	ss.lastReq = nil
+	if !shouldLogReq(ss.rule) {
+		lr = nil
+	}
+
+	if lr != nil || len(logReqs) == 0 {
+		logReq, err := ss.newLogReq()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case ss.summary != nil:
+			ss.summary.apply(logReq, time.Now())
+		case lr != nil:
+			if err := setReq(logReq, lr); err != nil {
+				return nil, err
+			}
+		}
+		ss.markFirstLocked(logReq)
+		logReqs = append(logReqs, logReq)
+	}
+	logReqs[len(logReqs)-1].Operation.Last = true
+	return logReqs, nil
+}
+
 // Context attaches the audit log request to the original context.
 func (ss *serverStreamWrapper) Context() context.Context {
 	return context.WithValue(ss.ServerStream.Context(), auditLogReqKey{}, ss.baselineLogReq)
@@ -319,26 +479,30 @@ func (ss *serverStreamWrapper) Context() context.Context {
 // RecvMsg wraps the original ServerStream.RecvMsg to send audit logs
 // for incoming requests. We first log the last request received if any.
 // We keep the latest request with the hope it can be logged in the next response.
+// In summary mode, the request is only recorded in the summary.
 func (ss *serverStreamWrapper) RecvMsg(m interface{}) error {
-	logReq, ok := proto.Clone(ss.baselineLogReq).(*api.AuditLogRequest)
-	if !ok {
-		return fmt.Errorf("expected *api.AuditLogRequest")
-	}
-
 	// RecvMsg is a blocking call until the next message is received into 'm'.
 	if err := ss.ServerStream.RecvMsg(m); err != nil {
 		return fmt.Errorf("failed to receive message from server stream: %w", err)
 	}
 
+	if ss.summary != nil {
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		return ss.summary.recordRecv(m)
+	}
+
 	lr := ss.swapLastReq(m)
 	if lr != nil {
 		if shouldLogReq(ss.rule) {
-			if err := setReq(logReq, lr); err != nil {
+			logReq, err := ss.newLogReq()
+			if err != nil {
 				return err
 			}
-			if err := ss.c.Log(ss.ServerStream.Context(), logReq); err != nil {
-				return auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err)) //nolint:wrapcheck
+			if err := setReq(logReq, lr); err != nil {
+				return err
 			}
+			return ss.hold(logReq)
 		}
 	}
 
@@ -348,10 +512,21 @@ func (ss *serverStreamWrapper) RecvMsg(m interface{}) error {
 // SendMsg wraps the original ServerStream.SendMsg to send audit logs
 // for outgoing responses. If there is a request from last time, we log them
 // together. Otherwise, only the response will be logged.
+// In summary mode, the response is only counted in the summary.
 func (ss *serverStreamWrapper) SendMsg(m interface{}) error {
-	logReq, ok := proto.Clone(ss.baselineLogReq).(*api.AuditLogRequest)
-	if !ok {
-		return fmt.Errorf("expected *api.AuditLogRequest")
+	if ss.summary != nil {
+		if err := ss.ServerStream.SendMsg(m); err != nil {
+			return fmt.Errorf("failed to send message to server stream: %w", err)
+		}
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		ss.summary.sent++
+		return nil
+	}
+
+	logReq, err := ss.newLogReq()
+	if err != nil {
+		return err
 	}
 
 	// If there is a last request, we log it with the response in the same log entry.
@@ -371,8 +546,8 @@ func (ss *serverStreamWrapper) SendMsg(m interface{}) error {
 		}
 	}
 
-	if err := ss.c.Log(ss.ServerStream.Context(), logReq); err != nil {
-		return auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to emit log: %v", err)) //nolint:wrapcheck
+	if err := ss.hold(logReq); err != nil {
+		return err
 	}
 
 	if err := ss.ServerStream.SendMsg(m); err != nil {
@@ -400,16 +575,26 @@ func setResp(logReq *api.AuditLogRequest, m interface{}) error {
 }
 
 func shouldLogReq(r *api.AuditRule) bool {
-	return r.Directive == api.AuditRuleDirectiveRequestAndResponse || r.Directive == api.AuditRuleDirectiveRequestOnly
+	return r.Directive == api.AuditRuleDirectiveRequestAndResponse || r.Directive == api.AuditRuleDirectiveRequestOnly ||
+		r.Directive == api.AuditRuleDirectiveStreamSummary
 }
 
 func shouldLogResp(r *api.AuditRule) bool {
 	return r.Directive == api.AuditRuleDirectiveRequestAndResponse
 }
 
+// failureOutcome is the metrics outcome of an intercepted call that failed
+// to audit log.
+func (i *Interceptor) failureOutcome() string {
+	if api.ShouldFailClose(i.logMode) {
+		return outcomeFailed
+	}
+	return outcomeDropped
+}
+
 // handleReturnUnary is intended to be a wrapper that handles the LogMode correctly, and returns errors or the handler
 // depending on whether the config and has specified to fail close.
-func (i *Interceptor) handleReturnUnary(ctx context.Context, req interface{}, handler grpc.UnaryHandler, err error) (interface{}, error) {
+func (i *Interceptor) handleReturnUnary(ctx context.Context, req interface{}, handler grpc.UnaryHandler, logReq *api.AuditLogRequest, err error) (interface{}, error) {
 	if api.ShouldFailClose(i.logMode) && err != nil {
 		return nil, err
 	}
@@ -418,11 +603,12 @@ func (i *Interceptor) handleReturnUnary(ctx context.Context, req interface{}, ha
 		logger := logging.FromContext(ctx)
 		logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
 			"error", err)
+		i.handleError(ctx, logReq, err)
 	}
 	return handler(ctx, req)
 }
 
-func (i *Interceptor) handleReturnStream(ctx context.Context, ss grpc.ServerStream, handler grpc.StreamHandler, err error) error {
+func (i *Interceptor) handleReturnStream(ctx context.Context, ss grpc.ServerStream, handler grpc.StreamHandler, logReq *api.AuditLogRequest, err error) error {
 	if api.ShouldFailClose(i.logMode) && err != nil {
 		return err
 	}
@@ -431,6 +617,7 @@ func (i *Interceptor) handleReturnStream(ctx context.Context, ss grpc.ServerStre
 		logger := logging.FromContext(ctx)
 		logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
 			"error", err)
+		i.handleError(ctx, logReq, err)
 	}
 	return handler(ctx, ss)
 }
@@ -438,7 +625,7 @@ func (i *Interceptor) handleReturnStream(ctx context.Context, ss grpc.ServerStre
 // handleReturnWithResponse is intended to be a wrapper that handles the LogMode correctly, and returns errors or a response
 // depending on whether the config and has specified to fail close. Differs from the above, as this is intended to be used
 // after the next handler in the chain has returned, and so we have a response formed already.
-func (i *Interceptor) handleReturnWithResponse(ctx context.Context, handlerResp interface{}, err error) (interface{}, error) {
+func (i *Interceptor) handleReturnWithResponse(ctx context.Context, handlerResp interface{}, logReq *api.AuditLogRequest, err error) (interface{}, error) {
 	if api.ShouldFailClose(i.logMode) && err != nil {
 		return handlerResp, err
 	}
@@ -447,6 +634,7 @@ func (i *Interceptor) handleReturnWithResponse(ctx context.Context, handlerResp
 		logger := logging.FromContext(ctx)
 		logger.ErrorContext(ctx, "failed to audit log; continuing without audit logging",
 			"error", err)
+		i.handleError(ctx, logReq, err)
 	}
 	return handlerResp, nil
 }
@@ -42,11 +42,15 @@ import (
 
 type fakeServer struct {
 	api.UnimplementedAuditLogAgentServer
-	gotReqs []*api.AuditLogRequest
+	gotReqs   []*api.AuditLogRequest
+	returnErr error
 }
 
 func (s *fakeServer) ProcessLog(_ context.Context, logReq *api.AuditLogRequest) (*api.AuditLogResponse, error) {
 	s.gotReqs = append(s.gotReqs, logReq)
+	if s.returnErr != nil {
+		return nil, s.returnErr
+	}
 	return &api.AuditLogResponse{Result: logReq}, nil
 }
 
@@ -613,6 +617,8 @@ func TestStreamInterceptor(t *testing.T) {
 		info          *grpc.StreamServerInfo
 		handler       grpc.StreamHandler
 		auditRules    []*api.AuditRule
+		logMode       api.AuditLogRequest_LogMode
+		serverErr     error
 		wantLogReqs   []*api.AuditLogRequest
 		wantErrSubstr string
 	}{
@@ -695,6 +701,8 @@ func TestStreamInterceptor(t *testing.T) {
 								"Val": structpb.NewStringValue("resp1"),
 							},
 						},
+						// The last audit log holds the status of the stream.
+						Status: &rpcstatus.Status{},
 					},
 				},
 			},
@@ -781,6 +789,7 @@ func TestStreamInterceptor(t *testing.T) {
 								"Val": structpb.NewStringValue("resp3"),
 							},
 						},
+						Status: &rpcstatus.Status{},
 					},
 				},
 			},
@@ -860,6 +869,7 @@ func TestStreamInterceptor(t *testing.T) {
 								"Val": structpb.NewStringValue("resp2"),
 							},
 						},
+						Status: &rpcstatus.Status{},
 					},
 				},
 			},
@@ -919,6 +929,7 @@ func TestStreamInterceptor(t *testing.T) {
 						AuthenticationInfo: &capi.AuthenticationInfo{
 							PrincipalEmail: "user@example.com",
 						},
+						Status: &rpcstatus.Status{},
 					},
 				},
 			},
@@ -988,6 +999,7 @@ func TestStreamInterceptor(t *testing.T) {
 								"Val": structpb.NewStringValue("req2"),
 							},
 						},
+						Status: &rpcstatus.Status{},
 					},
 				},
 			},
@@ -1050,6 +1062,488 @@ func TestStreamInterceptor(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "client_stream_ends_without_resp",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveRequestOnly,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				for _, m := range []*msg{{Val: "req1"}, {Val: "req2"}} {
+					if err := ss.RecvMsg(m); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req1"),
+							},
+						},
+					},
+				},
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						// The last request is logged when the stream closes.
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req2"),
+							},
+						},
+						Status: &rpcstatus.Status{},
+					},
+				},
+			},
+		},
+		{
+			name: "best_effort_stream_end",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveRequestAndResponse,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				if err := ss.RecvMsg(&msg{Val: "req1"}); err != nil {
+					return err
+				}
+				return ss.SendMsg(&msg{Val: "resp1"})
+			},
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req1"),
+							},
+						},
+						Response: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("resp1"),
+							},
+						},
+						// The end of the stream is marked the same way in
+						// both log modes.
+						Status: &rpcstatus.Status{},
+					},
+					Mode: api.AuditLogRequest_BEST_EFFORT,
+				},
+			},
+		},
+		{
+			name: "fail_close_stream_end_log_failure",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveRequestOnly,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			logMode:   api.AuditLogRequest_FAIL_CLOSE,
+			serverErr: grpcstatus.Error(codes.Unavailable, "injected error"),
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				return ss.RecvMsg(&msg{Val: "req1"})
+			},
+			wantErrSubstr: "failed to emit log",
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req1"),
+							},
+						},
+						Status: &rpcstatus.Status{},
+					},
+					Mode: api.AuditLogRequest_FAIL_CLOSE,
+				},
+			},
+		},
+		{
+			name: "fail_close_stream_end",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveRequestAndResponse,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				for _, v := range []string{"1", "2"} {
+					if err := ss.RecvMsg(&msg{Val: "req" + v}); err != nil {
+						return err
+					}
+					if err := ss.SendMsg(&msg{Val: "resp" + v}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req1"),
+							},
+						},
+						Response: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("resp1"),
+							},
+						},
+					},
+					Mode: api.AuditLogRequest_FAIL_CLOSE,
+				},
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req2"),
+							},
+						},
+						Response: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("resp2"),
+							},
+						},
+						Status: &rpcstatus.Status{},
+					},
+					Mode: api.AuditLogRequest_FAIL_CLOSE,
+				},
+			},
+		},
+		{
+			name: "best_effort_stream_end_log_failure",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveRequestOnly,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			logMode:   api.AuditLogRequest_BEST_EFFORT,
+			serverErr: grpcstatus.Error(codes.Unavailable, "injected error"),
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				return ss.RecvMsg(&msg{Val: "req1"})
+			},
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Request: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"Val": structpb.NewStringValue("req1"),
+							},
+						},
+						Status: &rpcstatus.Status{},
+					},
+					Mode: api.AuditLogRequest_BEST_EFFORT,
+				},
+			},
+		},
+		{
+			name: "fail_close_stream_summary_log_failure",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveStreamSummary,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			logMode:   api.AuditLogRequest_FAIL_CLOSE,
+			serverErr: grpcstatus.Error(codes.Unavailable, "injected error"),
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				return ss.RecvMsg(&msg{Val: "req1"})
+			},
+			wantErrSubstr: "failed to emit log",
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Metadata: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"stream_summary": structpb.NewStructValue(&structpb.Struct{
+									Fields: map[string]*structpb.Value{
+										"received_messages": structpb.NewNumberValue(1),
+										"sent_messages":     structpb.NewNumberValue(0),
+										"first_request": structpb.NewStructValue(&structpb.Struct{
+											Fields: map[string]*structpb.Value{
+												"Val": structpb.NewStringValue("req1"),
+											},
+										}),
+										"last_request": structpb.NewStructValue(&structpb.Struct{
+											Fields: map[string]*structpb.Value{
+												"Val": structpb.NewStringValue("req1"),
+											},
+										}),
+									},
+								}),
+							},
+						},
+						Status: &rpcstatus.Status{},
+					},
+					Mode: api.AuditLogRequest_FAIL_CLOSE,
+				},
+			},
+		},
+		{
+			name: "stream_summary",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveStreamSummary,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				if err := ss.RecvMsg(&msg{Val: "req1"}); err != nil {
+					return err
+				}
+				if err := ss.SendMsg(&msg{Val: "resp1"}); err != nil {
+					return err
+				}
+				for _, m := range []*msg{{Val: "req2"}, {Val: "req3"}} {
+					if err := ss.RecvMsg(m); err != nil {
+						return err
+					}
+				}
+				return ss.SendMsg(&msg{Val: "resp2"})
+			},
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						NumResponseItems: 2,
+						Metadata: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"stream_summary": structpb.NewStructValue(&structpb.Struct{
+									Fields: map[string]*structpb.Value{
+										"received_messages": structpb.NewNumberValue(3),
+										"sent_messages":     structpb.NewNumberValue(2),
+										"first_request": structpb.NewStructValue(&structpb.Struct{
+											Fields: map[string]*structpb.Value{
+												"Val": structpb.NewStringValue("req1"),
+											},
+										}),
+										"last_request": structpb.NewStructValue(&structpb.Struct{
+											Fields: map[string]*structpb.Value{
+												"Val": structpb.NewStringValue("req3"),
+											},
+										}),
+									},
+								}),
+							},
+						},
+						Status: &rpcstatus.Status{},
+					},
+				},
+			},
+		},
+		{
+			name: "stream_summary_handler_error",
+			ss: &fakeServerStream{
+				incomingCtx: metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
+					"authorization": jwt,
+				})),
+			},
+			info: &grpc.StreamServerInfo{
+				FullMethod: "/ExampleService/ExampleMethod",
+			},
+			auditRules: []*api.AuditRule{
+				{
+					Selector:  "/ExampleService/ExampleMethod",
+					Directive: api.AuditRuleDirectiveStreamSummary,
+					LogType:   "DATA_ACCESS",
+				},
+			},
+			handler: func(srv interface{}, ss grpc.ServerStream) error {
+				logReq, _ := LogReqFromCtx(ss.Context())
+				logReq.Payload.ResourceName = "ExampleResourceName"
+				if err := ss.RecvMsg(&msg{Val: "req1"}); err != nil {
+					return err
+				}
+				return grpcstatus.Error(codes.NotFound, "no such thing")
+			},
+			wantErrSubstr: "no such thing",
+			wantLogReqs: []*api.AuditLogRequest{
+				{
+					Type: api.AuditLogRequest_DATA_ACCESS,
+					Payload: &capi.AuditLog{
+						ServiceName:  "ExampleService",
+						MethodName:   "/ExampleService/ExampleMethod",
+						ResourceName: "ExampleResourceName",
+						AuthenticationInfo: &capi.AuthenticationInfo{
+							PrincipalEmail: "user@example.com",
+						},
+						Metadata: &structpb.Struct{
+							Fields: map[string]*structpb.Value{
+								"stream_summary": structpb.NewStructValue(&structpb.Struct{
+									Fields: map[string]*structpb.Value{
+										"received_messages": structpb.NewNumberValue(1),
+										"sent_messages":     structpb.NewNumberValue(0),
+										"first_request": structpb.NewStructValue(&structpb.Struct{
+											Fields: map[string]*structpb.Value{
+												"Val": structpb.NewStringValue("req1"),
+											},
+										}),
+										"last_request": structpb.NewStructValue(&structpb.Struct{
+											Fields: map[string]*structpb.Value{
+												"Val": structpb.NewStringValue("req1"),
+											},
+										}),
+									},
+								}),
+							},
+						},
+						Status: &rpcstatus.Status{
+							Code:    int32(codes.NotFound),
+							Message: "no such thing",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "handler_error",
 			ss: &fakeServerStream{
@@ -1097,9 +1591,9 @@ func TestStreamInterceptor(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			i := &Interceptor{rules: tc.auditRules}
+			i := &Interceptor{rules: tc.auditRules, logMode: tc.logMode}
 
-			r := &fakeServer{}
+			r := &fakeServer{returnErr: tc.serverErr}
 
 			addr, _ := testutil.TestFakeGRPCServer(t, func(s *grpc.Server) {
 				api.RegisterAuditLogAgentServer(s, r)
@@ -1109,7 +1603,11 @@ func TestStreamInterceptor(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			c, err := NewClient(ctx, WithBackend(p))
+			opts := []Option{WithBackend(p)}
+			if tc.logMode != api.AuditLogRequest_LOG_MODE_UNSPECIFIED {
+				opts = append(opts, WithLogMode(tc.logMode))
+			}
+			c, err := NewClient(ctx, opts...)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1128,13 +1626,36 @@ func TestStreamInterceptor(t *testing.T) {
 				t.Errorf("UnaryInterceptor(...) got unexpected error substring: %v", diff)
 			}
 
+			var lastCount int
 			for i, lr := range r.gotReqs {
+				if lr.GetOperation().GetLast() {
+					lastCount++
+				}
 				if lr.GetOperation() == nil || lr.GetOperation().GetId() == "" {
 					t.Errorf("StreamInterceptor(...) gotReqs[%d] missing operation id", i)
 				}
 				if lr.GetTimestamp() == nil {
 					t.Errorf("StreamInterceptor(...) gotReqs[%d] missing timestamp", i)
 				}
+				if got, want := lr.GetOperation().GetFirst(), i == 0; got != want {
+					t.Errorf("StreamInterceptor(...) gotReqs[%d] operation first got %t, want %t", i, got, want)
+				}
+				// Only the audit log of the end of the stream, which holds its
+				// status, is the last of the operation.
+				if got, want := lr.GetOperation().GetLast(), lr.GetPayload().GetStatus() != nil; got != want {
+					t.Errorf("StreamInterceptor(...) gotReqs[%d] operation last got %t, want %t", i, got, want)
+				}
+				// The stream duration varies, check it's set and ignore it.
+				if summary := lr.GetPayload().GetMetadata().GetFields()[streamSummaryKey].GetStructValue(); summary != nil {
+					if _, ok := summary.GetFields()["duration"]; !ok {
+						t.Errorf("StreamInterceptor(...) gotReqs[%d] missing stream duration", i)
+					}
+					delete(summary.GetFields(), "duration")
+				}
+			}
+
+			if len(r.gotReqs) > 0 && lastCount != 1 {
+				t.Errorf("StreamInterceptor(...) got %d audit logs marked as last, want 1", lastCount)
 			}
 
 			if diff := cmp.Diff(tc.wantLogReqs, r.gotReqs, protocmp.Transform(), protocmp.IgnoreFields(&api.AuditLogRequest{}, "timestamp", "operation")); diff != "" {
@@ -1257,7 +1778,7 @@ func TestHandleReturnUnary(t *testing.T) {
 
 			i := &Interceptor{logMode: tc.logMode}
 
-			got, gotErr := i.handleReturnUnary(ctx, req, handler, tc.err)
+			got, gotErr := i.handleReturnUnary(ctx, req, handler, &api.AuditLogRequest{}, tc.err)
 
 			if (gotErr != nil) != tc.wantErr {
 				expected := "an error"
@@ -1324,7 +1845,7 @@ func TestHandleReturnStream(t *testing.T) {
 
 			i := &Interceptor{logMode: tc.logMode}
 
-			gotErr := i.handleReturnStream(ctx, ss, handler, tc.err)
+			gotErr := i.handleReturnStream(ctx, ss, handler, &api.AuditLogRequest{}, tc.err)
 
 			if (gotErr != nil) != tc.wantErr {
 				expected := "an error"
@@ -1384,7 +1905,7 @@ func TestHandleReturnWithResponse(t *testing.T) {
 
 			i := &Interceptor{logMode: tc.logMode}
 
-			got, gotErr := i.handleReturnWithResponse(ctx, response, tc.err)
+			got, gotErr := i.handleReturnWithResponse(ctx, response, &api.AuditLogRequest{}, tc.err)
 
 			if diff := pkgtestutil.DiffErrString(gotErr, tc.wantErrStr); diff != "" {
 				t.Errorf("got unexpected error substring: %v", diff)
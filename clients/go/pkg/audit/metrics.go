@@ -0,0 +1,193 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+)
+
+// meterName is the instrumentation scope of the audit metrics.
+const meterName = "github.com/abcxyz/lumberjack/clients/go/pkg/audit"
+
+// Metric attribute keys.
+const (
+	attrLogType   = "log_type"
+	attrMethod    = "method"
+	attrOutcome   = "outcome"
+	attrStage     = "stage"
+	attrProcessor = "processor"
+)
+
+// Processing stages of a log request.
+const (
+	stageValidator = "validator"
+	stageMutator   = "mutator"
+	stageBackend   = "backend"
+)
+
+// Outcomes of a log request, a processor call or an intercepted call.
+const (
+	// outcomeSuccess means the log request was processed.
+	outcomeSuccess = "success"
+
+	// outcomeSkipped means the log request was intentionally not processed,
+	// e.g. a processor precondition failed or no audit rule matched.
+	outcomeSkipped = "skipped"
+
+	// outcomeFailed means the failure was returned to the caller.
+	outcomeFailed = "failed"
+
+	// outcomeDropped means the failure was swallowed because the log request
+	// is best effort, so the audit log is lost.
+	outcomeDropped = "dropped"
+
+	// outcomeError means a processor returned an error.
+	outcomeError = "error"
+)
+
+// WithMeterProvider sets the OpenTelemetry meter provider used to record the
+// client metrics. The default is the global meter provider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(ctx context.Context, o *Client) error {
+		o.meterProvider = mp
+		return nil
+	}
+}
+
+// WithInterceptorMeterProvider sets the OpenTelemetry meter provider used to
+// record the interceptor metrics. The default is the global meter provider.
+func WithInterceptorMeterProvider(mp metric.MeterProvider) InterceptorOption {
+	return func(ctx context.Context, i *Interceptor) error {
+		i.meterProvider = mp
+		return nil
+	}
+}
+
+// clientMetrics are the instruments recorded by the client.
+type clientMetrics struct {
+	logs              metric.Int64Counter
+	logDuration       metric.Float64Histogram
+	processorCalls    metric.Int64Counter
+	processorDuration metric.Float64Histogram
+}
+
+func newClientMetrics(mp metric.MeterProvider) (*clientMetrics, error) {
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(meterName)
+
+	var m clientMetrics
+	var err, merr error
+	m.logs, err = meter.Int64Counter("lumberjack.audit.logs",
+		metric.WithDescription("Number of audit log requests by log type, method and outcome."),
+		metric.WithUnit("{request}"))
+	merr = errors.Join(merr, err)
+	m.logDuration, err = meter.Float64Histogram("lumberjack.audit.log.duration",
+		metric.WithDescription("Time to process an audit log request through all the stages."),
+		metric.WithUnit("s"))
+	merr = errors.Join(merr, err)
+	m.processorCalls, err = meter.Int64Counter("lumberjack.audit.processor.calls",
+		metric.WithDescription("Number of log processor calls by stage, processor, log type and outcome."),
+		metric.WithUnit("{call}"))
+	merr = errors.Join(merr, err)
+	m.processorDuration, err = meter.Float64Histogram("lumberjack.audit.processor.duration",
+		metric.WithDescription("Time taken by a log processor call, by stage and processor."),
+		metric.WithUnit("s"))
+	merr = errors.Join(merr, err)
+	if merr != nil {
+		return nil, fmt.Errorf("failed to create client metrics: %w", merr)
+	}
+	return &m, nil
+}
+
+// recordLog records the outcome of a log request. Like the other record
+// methods, it's a no-op on a nil receiver, e.g. for a zero value Client.
+func (m *clientMetrics) recordLog(ctx context.Context, logReq *api.AuditLogRequest, outcome string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.logs.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(attrLogType, logReq.GetType().String()),
+		attribute.String(attrMethod, logReq.GetPayload().GetMethodName()),
+		attribute.String(attrOutcome, outcome)))
+	if !start.IsZero() {
+		m.logDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			attribute.String(attrOutcome, outcome)))
+	}
+}
+
+// recordProcessor records a log processor call that started at the given
+// time and returned the given error.
+func (m *clientMetrics) recordProcessor(ctx context.Context, logReq *api.AuditLogRequest, stage, processor string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	outcome := outcomeSuccess
+	switch {
+	case errors.Is(err, auditerrors.ErrPreconditionFailed):
+		outcome = outcomeSkipped
+	case err != nil:
+		outcome = outcomeError
+	}
+	m.processorCalls.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(attrStage, stage),
+		attribute.String(attrProcessor, processor),
+		attribute.String(attrLogType, logReq.GetType().String()),
+		attribute.String(attrOutcome, outcome)))
+	m.processorDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String(attrStage, stage),
+		attribute.String(attrProcessor, processor)))
+}
+
+// interceptorMetrics are the instruments recorded by the interceptor.
+type interceptorMetrics struct {
+	calls metric.Int64Counter
+}
+
+func newInterceptorMetrics(mp metric.MeterProvider) (*interceptorMetrics, error) {
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	calls, err := mp.Meter(meterName).Int64Counter("lumberjack.audit.interceptor.calls",
+		metric.WithDescription("Number of intercepted gRPC calls by method, log type and audit outcome."),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create interceptor metrics: %w", err)
+	}
+	return &interceptorMetrics{calls: calls}, nil
+}
+
+// recordCall records the audit outcome of an intercepted call. The log type
+// is empty for calls that no audit rule matched.
+func (m *interceptorMetrics) recordCall(ctx context.Context, method, logType, outcome string) {
+	if m == nil {
+		return
+	}
+	m.calls.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(attrMethod, method),
+		attribute.String(attrLogType, logType),
+		attribute.String(attrOutcome, outcome)))
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+)
+
+type fakeSecurityContext struct {
+	principal string
+	err       error
+}
+
+func (sc *fakeSecurityContext) RequestPrincipal(context.Context) (string, error) {
+	return sc.principal, sc.err
+}
+
+// collectMetrics returns the metric data points recorded by the reader, keyed
+// by "metric_name{attr=value,...}". Counters report their sum and histograms
+// their count.
+func collectMetrics(tb testing.TB, r sdkmetric.Reader) map[string]int64 {
+	tb.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.Collect(tb.Context(), &rm); err != nil {
+		tb.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	got := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range d.DataPoints {
+					got[metricKey(m.Name, dp.Attributes.ToSlice())] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range d.DataPoints {
+					got[metricKey(m.Name, dp.Attributes.ToSlice())] += int64(dp.Count) //nolint:gosec // Test counts are small.
+				}
+			}
+		}
+	}
+	return got
+}
+
+func metricKey(name string, attrs []attribute.KeyValue) string {
+	parts := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		parts = append(parts, fmt.Sprintf("%s=%s", a.Key, a.Value.Emit()))
+	}
+	sort.Strings(parts)
+	return fmt.Sprintf("%s{%s}", name, strings.Join(parts, ","))
+}
+
+func TestClient_Metrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	fakeErr := fmt.Errorf("fake error")
+
+	cases := []struct {
+		name        string
+		validator   LogProcessor
+		backendErr  error
+		logMode     api.AuditLogRequest_LogMode
+		wantMetrics map[string]int64
+	}{
+		{
+			name: "success",
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.logs{log_type=DATA_ACCESS,method=test-method,outcome=success}":                                           1,
+				"lumberjack.audit.log.duration{outcome=success}":                                                                           1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=success,processor=*audit.RequestValidator,stage=validator}": 1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=success,processor=test-backend,stage=backend}":              1,
+				"lumberjack.audit.processor.duration{processor=*audit.RequestValidator,stage=validator}":                                   1,
+				"lumberjack.audit.processor.duration{processor=test-backend,stage=backend}":                                                1,
+			},
+		},
+		{
+			name:      "validator_precondition_failed",
+			validator: &countingProcessor{returnErr: auditerrors.ErrPreconditionFailed},
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.logs{log_type=DATA_ACCESS,method=test-method,outcome=skipped}":                                            1,
+				"lumberjack.audit.log.duration{outcome=skipped}":                                                                            1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=success,processor=*audit.RequestValidator,stage=validator}":  1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=skipped,processor=*audit.countingProcessor,stage=validator}": 1,
+				"lumberjack.audit.processor.duration{processor=*audit.RequestValidator,stage=validator}":                                    1,
+				"lumberjack.audit.processor.duration{processor=*audit.countingProcessor,stage=validator}":                                   1,
+			},
+		},
+		{
+			name:       "backend_failure_best_effort",
+			backendErr: fakeErr,
+			logMode:    api.AuditLogRequest_BEST_EFFORT,
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.logs{log_type=DATA_ACCESS,method=test-method,outcome=dropped}":                                           1,
+				"lumberjack.audit.log.duration{outcome=dropped}":                                                                           1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=success,processor=*audit.RequestValidator,stage=validator}": 1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=error,processor=test-backend,stage=backend}":                1,
+				"lumberjack.audit.processor.duration{processor=*audit.RequestValidator,stage=validator}":                                   1,
+				"lumberjack.audit.processor.duration{processor=test-backend,stage=backend}":                                                1,
+			},
+		},
+		{
+			name:       "backend_failure_fail_close",
+			backendErr: fakeErr,
+			logMode:    api.AuditLogRequest_FAIL_CLOSE,
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.logs{log_type=DATA_ACCESS,method=test-method,outcome=failed}":                                            1,
+				"lumberjack.audit.log.duration{outcome=failed}":                                                                            1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=success,processor=*audit.RequestValidator,stage=validator}": 1,
+				"lumberjack.audit.processor.calls{log_type=DATA_ACCESS,outcome=error,processor=test-backend,stage=backend}":                1,
+				"lumberjack.audit.processor.duration{processor=*audit.RequestValidator,stage=validator}":                                   1,
+				"lumberjack.audit.processor.duration{processor=test-backend,stage=backend}":                                                1,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := sdkmetric.NewManualReader()
+			opts := []Option{
+				WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+				WithBackend(&countingProcessor{returnErr: tc.backendErr}, WithBackendName("test-backend")),
+				WithLogMode(tc.logMode),
+			}
+			if tc.validator != nil {
+				opts = append(opts, WithValidator(tc.validator))
+			}
+			c, err := NewClient(ctx, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_ = c.Log(ctx, testutil.NewRequest(testutil.WithMethodName("test-method")))
+
+			if diff := cmp.Diff(tc.wantMetrics, collectMetrics(t, reader)); diff != "" {
+				t.Errorf("metrics got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Metrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+
+	cases := []struct {
+		name        string
+		rules       []*api.AuditRule
+		sc          *fakeSecurityContext
+		logMode     api.AuditLogRequest_LogMode
+		wantMetrics map[string]int64
+	}{
+		{
+			name: "no_rule",
+			rules: []*api.AuditRule{{
+				Selector:  "/other.Service/Method",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "ADMIN_ACTIVITY",
+			}},
+			sc: &fakeSecurityContext{principal: "user@example.com"},
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.interceptor.calls{log_type=,method=/test.Service/Method,outcome=skipped}": 1,
+			},
+		},
+		{
+			name: "success",
+			rules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "ADMIN_ACTIVITY",
+			}},
+			sc: &fakeSecurityContext{principal: "user@example.com"},
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.interceptor.calls{log_type=ADMIN_ACTIVITY,method=/test.Service/Method,outcome=success}": 1,
+			},
+		},
+		{
+			name: "principal_failure_best_effort",
+			rules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			sc:      &fakeSecurityContext{err: fmt.Errorf("no principal")},
+			logMode: api.AuditLogRequest_BEST_EFFORT,
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.interceptor.calls{log_type=DATA_ACCESS,method=/test.Service/Method,outcome=dropped}": 1,
+			},
+		},
+		{
+			name: "principal_failure_fail_close",
+			rules: []*api.AuditRule{{
+				Selector:  "*",
+				Directive: api.AuditRuleDirectiveDefault,
+				LogType:   "DATA_ACCESS",
+			}},
+			sc:      &fakeSecurityContext{err: fmt.Errorf("no principal")},
+			logMode: api.AuditLogRequest_FAIL_CLOSE,
+			wantMetrics: map[string]int64{
+				"lumberjack.audit.interceptor.calls{log_type=DATA_ACCESS,method=/test.Service/Method,outcome=failed}": 1,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The client records to a separate provider, so only the
+			// interceptor metrics are collected.
+			c, err := NewClient(ctx,
+				WithMeterProvider(sdkmetric.NewMeterProvider()),
+				WithBackend(&countingProcessor{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reader := sdkmetric.NewManualReader()
+			i, err := NewInterceptor(ctx,
+				WithInterceptorMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+				WithAuditClient(c),
+				WithAuditRules(tc.rules...),
+				WithSecurityContext(tc.sc),
+				WithInterceptorLogMode(tc.logMode))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _ = i.UnaryInterceptor(ctx, nil, info, handler)
+
+			if diff := cmp.Diff(tc.wantMetrics, collectMetrics(t, reader)); diff != "" {
+				t.Errorf("metrics got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
@@ -47,7 +47,13 @@ func mostRelevantRule(methodName string, rules []*api.AuditRule) *api.AuditRule
 // isRuleApplicable determines if a Rule applies to the given method by
 // comparing the Rule's Selector to the methodName.
 func isRuleApplicable(rule *api.AuditRule, methodName string) bool {
-	sel := rule.Selector
+	return selectorMatches(rule.Selector, methodName)
+}
+
+// selectorMatches determines if the selector matches the given method. The
+// selector is either the wildcard "*", a method name, or a method name prefix
+// followed by "*".
+func selectorMatches(sel, methodName string) bool {
 	if sel == wildcard {
 		return true
 	}
@@ -265,7 +265,8 @@ func toStructVal(monitoredResource *mrpb.MonitoredResource) (*structpb.Value, er
 
 // Process stores the application's GCP runtime information in the audit log
 // request. More specifically, in the Payload.Metadata under the key
-// "originating_resource".
+// "originating_resource". The Cloud Logging backend sets it as the resource of
+// the log entry.
 func (p *runtimeInfo) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
 	if p == nil || p.monitoredResource == nil {
 		return nil
@@ -0,0 +1,197 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"strconv"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+)
+
+// SamplingRateLabel is the label holding the sampling rate of a sampled audit
+// log request that was kept, so that the original volume can be extrapolated.
+const SamplingRateLabel = "sampling_rate"
+
+// SamplingKey specifies what a SamplingProcessor keys its sampling decision on.
+type SamplingKey int
+
+const (
+	// SampleRandomly samples each log request independently. This is the
+	// default.
+	SampleRandomly SamplingKey = iota
+
+	// SampleByPrincipal keeps or drops all the log requests of a principal
+	// together, e.g. to keep complete trails for the sampled principals.
+	SampleByPrincipal
+
+	// SampleByOperationID keeps or drops all the log requests of an operation
+	// together, e.g. all the messages of a stream.
+	SampleByOperationID
+)
+
+// SamplingProcessor is a mutator that keeps only a fraction of the audit log
+// requests, to reduce the volume of high-traffic methods. The sampling rate
+// of a log request is the rate of the most relevant selector matching its
+// method, or else the rate of its log type. Log requests without a sampling
+// rate, and ADMIN_ACTIVITY log requests, are always kept.
+//
+// A kept log request gets the SamplingRateLabel label. A dropped log request
+// fails with auditerrors.ErrPreconditionFailed, so the client skips it
+// without an error.
+type SamplingProcessor struct {
+	selectorRates []*selectorRate
+	logTypeRates  map[api.AuditLogRequest_LogType]float64
+	key           SamplingKey
+
+	// random returns a number in [0, 1), it's replaced in tests.
+	random func() float64
+}
+
+// selectorRate is the sampling rate of the methods matching the selector.
+type selectorRate struct {
+	selector string
+	rate     float64
+}
+
+// SamplingOption is a configuration option for NewSamplingProcessor.
+type SamplingOption func(p *SamplingProcessor) error
+
+// WithSelectorSamplingRate sets the sampling rate, between 0 and 1, of the log
+// requests whose method matches the selector. Selectors follow the audit rule
+// syntax, e.g. "/com.example.Service/Get*", and the most relevant one applies.
+func WithSelectorSamplingRate(selector string, rate float64) SamplingOption {
+	return func(p *SamplingProcessor) error {
+		if selector == "" {
+			return fmt.Errorf("sampling selector is empty")
+		}
+		if err := validateSamplingRate(rate); err != nil {
+			return fmt.Errorf("invalid sampling rate for selector %q: %w", selector, err)
+		}
+		p.selectorRates = append(p.selectorRates, &selectorRate{selector: selector, rate: rate})
+		return nil
+	}
+}
+
+// WithLogTypeSamplingRate sets the sampling rate, between 0 and 1, of the log
+// requests of the given log type that no selector matches. ADMIN_ACTIVITY log
+// requests can't be sampled.
+func WithLogTypeSamplingRate(logType api.AuditLogRequest_LogType, rate float64) SamplingOption {
+	return func(p *SamplingProcessor) error {
+		if logType == api.AuditLogRequest_ADMIN_ACTIVITY {
+			return fmt.Errorf("%s log requests are never sampled", logType)
+		}
+		if err := validateSamplingRate(rate); err != nil {
+			return fmt.Errorf("invalid sampling rate for log type %s: %w", logType, err)
+		}
+		p.logTypeRates[logType] = rate
+		return nil
+	}
+}
+
+// WithSamplingKey sets what the sampling decision is keyed on. The default is
+// SampleRandomly. Log requests missing the key are sampled randomly.
+func WithSamplingKey(k SamplingKey) SamplingOption {
+	return func(p *SamplingProcessor) error {
+		p.key = k
+		return nil
+	}
+}
+
+func validateSamplingRate(rate float64) error {
+	if math.IsNaN(rate) || rate < 0 || rate > 1 {
+		return fmt.Errorf("rate %v must be between 0 and 1", rate)
+	}
+	return nil
+}
+
+// NewSamplingProcessor creates a SamplingProcessor with the given options.
+func NewSamplingProcessor(opts ...SamplingOption) (*SamplingProcessor, error) {
+	p := &SamplingProcessor{
+		logTypeRates: make(map[api.AuditLogRequest_LogType]float64),
+		random:       rand.Float64,
+	}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply sampling options: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Process keeps or drops the log request according to its sampling rate.
+func (p *SamplingProcessor) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	if logReq.GetType() == api.AuditLogRequest_ADMIN_ACTIVITY {
+		return nil
+	}
+	rate, ok := p.rate(logReq)
+	if !ok {
+		return nil
+	}
+
+	if p.sample(logReq) >= rate {
+		return fmt.Errorf("log request dropped by sampling at rate %v: %w", rate, auditerrors.ErrPreconditionFailed)
+	}
+
+	if logReq.Labels == nil {
+		logReq.Labels = map[string]string{}
+	}
+	logReq.Labels[SamplingRateLabel] = strconv.FormatFloat(rate, 'g', -1, 64)
+	return nil
+}
+
+// rate returns the sampling rate of the log request, if any.
+func (p *SamplingProcessor) rate(logReq *api.AuditLogRequest) (float64, bool) {
+	method := logReq.GetPayload().GetMethodName()
+	var longest int
+	var rate float64
+	var found bool
+	for _, sr := range p.selectorRates {
+		if selectorMatches(sr.selector, method) && len(sr.selector) > longest {
+			longest, rate, found = len(sr.selector), sr.rate, true
+		}
+	}
+	if found {
+		return rate, true
+	}
+	rate, found = p.logTypeRates[logReq.GetType()]
+	return rate, found
+}
+
+// sample returns a number in [0, 1) for the log request, which is the same for
+// all the log requests with the same sampling key.
+func (p *SamplingProcessor) sample(logReq *api.AuditLogRequest) float64 {
+	var key string
+	switch p.key {
+	case SampleByPrincipal:
+		key = logReq.GetPayload().GetAuthenticationInfo().GetPrincipalEmail()
+	case SampleByOperationID:
+		key = logReq.GetOperation().GetId()
+	case SampleRandomly:
+	}
+	if key == "" {
+		return p.random()
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key)) // Writing to a hash never fails.
+	// Use the top 53 bits to get a uniformly distributed float64.
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
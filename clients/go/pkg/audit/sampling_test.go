@@ -0,0 +1,208 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+func TestNewSamplingProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		opts          []SamplingOption
+		wantErrSubstr string
+	}{
+		{
+			name: "valid",
+			opts: []SamplingOption{
+				WithSelectorSamplingRate("*", 0.5),
+				WithLogTypeSamplingRate(api.AuditLogRequest_DATA_ACCESS, 0),
+				WithSamplingKey(SampleByPrincipal),
+			},
+		},
+		{
+			name:          "rate_out_of_range",
+			opts:          []SamplingOption{WithSelectorSamplingRate("*", 1.5)},
+			wantErrSubstr: `invalid sampling rate for selector "*": rate 1.5 must be between 0 and 1`,
+		},
+		{
+			name:          "empty_selector",
+			opts:          []SamplingOption{WithSelectorSamplingRate("", 0.5)},
+			wantErrSubstr: "sampling selector is empty",
+		},
+		{
+			name:          "admin_activity",
+			opts:          []SamplingOption{WithLogTypeSamplingRate(api.AuditLogRequest_ADMIN_ACTIVITY, 0.5)},
+			wantErrSubstr: "ADMIN_ACTIVITY log requests are never sampled",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSamplingProcessor(tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewSamplingProcessor() got unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSamplingProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	withType := func(logType api.AuditLogRequest_LogType, logReq *api.AuditLogRequest) *api.AuditLogRequest {
+		logReq.Type = logType
+		return logReq
+	}
+
+	cases := []struct {
+		name       string
+		opts       []SamplingOption
+		random     float64
+		logReq     *api.AuditLogRequest
+		wantLogReq *api.AuditLogRequest
+		wantErr    error
+	}{
+		{
+			name:       "no_rate_kept_without_label",
+			opts:       []SamplingOption{WithSelectorSamplingRate("/other.Service/*", 0)},
+			logReq:     testutil.NewRequest(testutil.WithMethodName("/test.Service/Get")),
+			wantLogReq: testutil.NewRequest(testutil.WithMethodName("/test.Service/Get")),
+		},
+		{
+			name:    "selector_rate_dropped",
+			opts:    []SamplingOption{WithSelectorSamplingRate("/test.Service/*", 0.5)},
+			random:  0.7,
+			logReq:  testutil.NewRequest(testutil.WithMethodName("/test.Service/Get")),
+			wantErr: auditerrors.ErrPreconditionFailed,
+		},
+		{
+			name:   "selector_rate_kept_with_label",
+			opts:   []SamplingOption{WithSelectorSamplingRate("/test.Service/*", 0.5)},
+			random: 0.3,
+			logReq: testutil.NewRequest(testutil.WithMethodName("/test.Service/Get")),
+			wantLogReq: testutil.NewRequest(testutil.WithMethodName("/test.Service/Get"),
+				testutil.WithLabels(map[string]string{SamplingRateLabel: "0.5"})),
+		},
+		{
+			name: "most_relevant_selector",
+			opts: []SamplingOption{
+				WithSelectorSamplingRate("/test.Service/Get", 1),
+				WithSelectorSamplingRate("*", 0),
+				WithSelectorSamplingRate("/test.Service/*", 0),
+			},
+			logReq: testutil.NewRequest(testutil.WithMethodName("/test.Service/Get")),
+			wantLogReq: testutil.NewRequest(testutil.WithMethodName("/test.Service/Get"),
+				testutil.WithLabels(map[string]string{SamplingRateLabel: "1"})),
+		},
+		{
+			name: "selector_overrides_log_type",
+			opts: []SamplingOption{
+				WithSelectorSamplingRate("/test.Service/*", 0.01),
+				WithLogTypeSamplingRate(api.AuditLogRequest_DATA_ACCESS, 1),
+			},
+			random:  0.5,
+			logReq:  testutil.NewRequest(testutil.WithMethodName("/test.Service/Get")),
+			wantErr: auditerrors.ErrPreconditionFailed,
+		},
+		{
+			name:    "log_type_rate_dropped",
+			opts:    []SamplingOption{WithLogTypeSamplingRate(api.AuditLogRequest_DATA_ACCESS, 0.25)},
+			random:  0.25,
+			logReq:  testutil.NewRequest(),
+			wantErr: auditerrors.ErrPreconditionFailed,
+		},
+		{
+			name:       "admin_activity_never_sampled",
+			opts:       []SamplingOption{WithSelectorSamplingRate("*", 0)},
+			logReq:     withType(api.AuditLogRequest_ADMIN_ACTIVITY, testutil.NewRequest()),
+			wantLogReq: withType(api.AuditLogRequest_ADMIN_ACTIVITY, testutil.NewRequest()),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewSamplingProcessor(tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.random = func() float64 { return tc.random }
+
+			err = p.Process(t.Context(), tc.logReq)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Process() got error %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.wantLogReq, tc.logReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Process() unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSamplingProcessor_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	const rate, n = 0.2, 2000
+
+	p, err := NewSamplingProcessor(
+		WithLogTypeSamplingRate(api.AuditLogRequest_DATA_ACCESS, rate),
+		WithSamplingKey(SampleByPrincipal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.random = func() float64 {
+		t.Fatal("random sampling used with a principal key")
+		return 0
+	}
+
+	var kept int
+	for i := range n {
+		principal := fmt.Sprintf("user-%d@example.com", i)
+		first := p.Process(t.Context(), testutil.NewRequest(testutil.WithPrincipal(principal)))
+		// The same principal always gets the same decision.
+		for range 3 {
+			if got := p.Process(t.Context(), testutil.NewRequest(testutil.WithPrincipal(principal))); (got == nil) != (first == nil) {
+				t.Fatalf("Process() for %s got error %v, then %v", principal, first, got)
+			}
+		}
+		if first == nil {
+			kept++
+		}
+	}
+
+	// The kept fraction is close to the rate.
+	if got := float64(kept) / n; got < rate-0.05 || got > rate+0.05 {
+		t.Errorf("kept %d of %d principals (%v), want about %v", kept, n, got, rate)
+	}
+}
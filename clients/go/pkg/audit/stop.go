@@ -0,0 +1,179 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StopResult is the outcome of stopping a single log processor.
+type StopResult struct {
+	// Stage is the stage the processor was added to: "validator", "mutator"
+	// or "backend".
+	Stage string
+
+	// Name is the name of the processor: the backend name for backends, see
+	// WithBackendName, and the processor's type otherwise.
+	Name string
+
+	// Err is the error returned by the processor's Stop, e.g. a failure to
+	// flush its logs, or the context error if the processor didn't stop in
+	// time. It's nil if the processor stopped.
+	Err error
+}
+
+// StopError is the error returned when stopping the client failed for at
+// least one processor. It holds the results of all the stoppable processors.
+type StopError struct {
+	Results []*StopResult
+}
+
+// Error satisfies the error interface.
+func (e *StopError) Error() string {
+	var msgs []string
+	for _, r := range e.Results {
+		if r.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("failed to stop %s %s: %v", r.Stage, r.Name, r.Err))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the processors that failed to stop.
+func (e *StopError) Unwrap() []error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// StopContext stops the client. New log requests are rejected with
+// auditerrors.ErrClientStopped, then StopContext waits for the in-flight log
+// calls and, if the client is asynchronous, for the queued log requests to be
+// processed. Finally, it stops all the validators, mutators and backends that
+// implement StoppableProcessor, concurrently.
+//
+// StopContext returns once all the processors stopped or the context is
+// done, whichever comes first. The processors that didn't stop in time keep
+// stopping in the background and are reported with the context error. If any
+// processor failed to stop, the returned error wraps a *StopError with the
+// result of every stoppable processor.
+func (c *Client) StopContext(ctx context.Context) error {
+	c.stopMu.Lock()
+	c.stopped = true
+	c.stopMu.Unlock()
+
+	var merr error
+	if err := waitContext(ctx, c.inflight.Wait); err != nil {
+		merr = errors.Join(merr, fmt.Errorf("failed to wait for in-flight audit log requests: %w", err))
+	}
+	if c.queue != nil {
+		if err := c.queue.stop(ctx); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+	if err := c.stopProcessors(ctx); err != nil {
+		merr = errors.Join(merr, err)
+	}
+	return merr
+}
+
+// stopProcessors stops all the stoppable processors concurrently, and returns
+// a *StopError if any of them failed or didn't stop before the context is
+// done.
+func (c *Client) stopProcessors(ctx context.Context) error {
+	type stoppable struct {
+		stage, name string
+		processor   StoppableProcessor
+	}
+	var ps []stoppable
+	for _, p := range c.validators {
+		if s, ok := p.(StoppableProcessor); ok {
+			ps = append(ps, stoppable{stageValidator, fmt.Sprintf("%T", p), s})
+		}
+	}
+	for _, p := range c.mutators {
+		if s, ok := p.(StoppableProcessor); ok {
+			ps = append(ps, stoppable{stageMutator, fmt.Sprintf("%T", p), s})
+		}
+	}
+	for _, b := range c.backends {
+		if s, ok := b.processor.(StoppableProcessor); ok {
+			ps = append(ps, stoppable{stageBackend, b.name, s})
+		}
+	}
+	if len(ps) == 0 {
+		return nil
+	}
+
+	type stopped struct {
+		i   int
+		err error
+	}
+	// Buffered so that processors stopping after the deadline don't leak a
+	// blocked goroutine.
+	ch := make(chan stopped, len(ps))
+	for i, p := range ps {
+		go func() {
+			ch <- stopped{i, p.processor.Stop()}
+		}()
+	}
+
+	results := make([]*StopResult, len(ps))
+	var failed bool
+collect:
+	for range ps {
+		select {
+		case s := <-ch:
+			results[s.i] = &StopResult{Stage: ps[s.i].stage, Name: ps[s.i].name, Err: s.err}
+			failed = failed || s.err != nil
+		case <-ctx.Done():
+			break collect
+		}
+	}
+	for i, r := range results {
+		if r == nil {
+			results[i] = &StopResult{Stage: ps[i].stage, Name: ps[i].name, Err: fmt.Errorf("processor did not stop in time: %w", ctx.Err())}
+			failed = true
+		}
+	}
+
+	if failed {
+		return &StopError{Results: results}
+	}
+	return nil
+}
+
+// waitContext calls wait and returns once it returns or once the context is
+// done, in which case it returns the context error.
+func waitContext(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // Wrapped by the callers.
+	}
+}
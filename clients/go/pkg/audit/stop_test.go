@@ -0,0 +1,198 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+)
+
+// stoppableProcessor is a no-op processor whose Stop returns stopErr, or
+// blocks until release is closed if set.
+type stoppableProcessor struct {
+	stopErr error
+	release chan struct{}
+}
+
+func (p *stoppableProcessor) Process(context.Context, *api.AuditLogRequest) error {
+	return nil
+}
+
+func (p *stoppableProcessor) Stop() error {
+	if p.release != nil {
+		<-p.release
+	}
+	return p.stopErr
+}
+
+func TestStopContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	fakeErr := fmt.Errorf("fake error")
+
+	hung := &stoppableProcessor{release: make(chan struct{})}
+	t.Cleanup(func() { close(hung.release) })
+
+	cases := []struct {
+		name        string
+		opts        []Option
+		timeout     time.Duration
+		wantResults []*StopResult
+	}{
+		{
+			name: "all_stopped",
+			opts: []Option{
+				WithValidator(&stoppableProcessor{}),
+				WithMutator(&stoppableProcessor{}),
+				WithBackend(&stoppableProcessor{}, WithBackendName("backend")),
+			},
+		},
+		{
+			name: "mutator_failure",
+			opts: []Option{
+				WithMutator(&stoppableProcessor{stopErr: fakeErr}),
+				WithBackend(&stoppableProcessor{}, WithBackendName("backend")),
+			},
+			wantResults: []*StopResult{
+				{Stage: "mutator", Name: "*audit.stoppableProcessor", Err: fakeErr},
+				{Stage: "backend", Name: "backend"},
+			},
+		},
+		{
+			name: "deadline_exceeded",
+			opts: []Option{
+				WithBackend(hung, WithBackendName("hung")),
+				WithBackend(&stoppableProcessor{}, WithBackendName("backend")),
+			},
+			timeout: 50 * time.Millisecond,
+			wantResults: []*StopResult{
+				{Stage: "backend", Name: "hung", Err: context.DeadlineExceeded},
+				{Stage: "backend", Name: "backend"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewClient(ctx, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stopCtx := ctx
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				stopCtx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			err = c.StopContext(stopCtx)
+			var stopErr *StopError
+			if !errors.As(err, &stopErr) {
+				if tc.wantResults != nil {
+					t.Fatalf("StopContext() got error %v, want *StopError", err)
+				}
+				if err != nil {
+					t.Fatalf("StopContext() unexpected error: %v", err)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.wantResults, stopErr.Results, cmp.Comparer(func(a, b error) bool {
+				return errors.Is(a, b) || errors.Is(b, a)
+			})); diff != "" {
+				t.Errorf("StopContext() results got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestStopContext_RejectsNewLogs(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	b := &countingProcessor{}
+	c, err := NewClient(ctx,
+		WithBackend(b),
+		WithLogMode(api.AuditLogRequest_FAIL_CLOSE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StopContext(ctx); err != nil {
+		t.Fatalf("StopContext() unexpected error: %v", err)
+	}
+
+	if err := c.Log(ctx, testutil.NewRequest()); !errors.Is(err, auditerrors.ErrClientStopped) {
+		t.Errorf("Log() after StopContext got error %v, want %v", err, auditerrors.ErrClientStopped)
+	}
+	for _, err := range c.LogBatch(ctx, []*api.AuditLogRequest{testutil.NewRequest()}) {
+		if !errors.Is(err, auditerrors.ErrClientStopped) {
+			t.Errorf("LogBatch() after StopContext got error %v, want %v", err, auditerrors.ErrClientStopped)
+		}
+	}
+	if got := b.count.Load(); got != 0 {
+		t.Errorf("backend processed %d requests after StopContext, want 0", got)
+	}
+}
+
+func TestStopContext_WaitsForInflightLogs(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	b := &blockingProcessor{started: make(chan struct{}), release: make(chan struct{})}
+	c, err := NewClient(ctx, WithBackend(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logDone := make(chan error, 1)
+	go func() {
+		logDone <- c.Log(ctx, testutil.NewRequest())
+	}()
+	<-b.started
+
+	stopDone := make(chan error, 1)
+	go func() {
+		stopDone <- c.StopContext(ctx)
+	}()
+
+	select {
+	case err := <-stopDone:
+		t.Fatalf("StopContext() returned %v before the in-flight log finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(b.release)
+	if err := <-logDone; err != nil {
+		t.Errorf("Log() unexpected error: %v", err)
+	}
+	if err := <-stopDone; err != nil {
+		t.Errorf("StopContext() unexpected error: %v", err)
+	}
+}
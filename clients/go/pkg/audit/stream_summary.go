@@ -0,0 +1,84 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+)
+
+// streamSummaryKey is the key of the stream summary in the Payload.Metadata of
+// the audit log of a summarized stream.
+const streamSummaryKey = "stream_summary"
+
+// streamSummary collects the messages of a server stream audited with an
+// AUDIT_STREAM_SUMMARY rule, which is logged once when the stream closes.
+type streamSummary struct {
+	start    time.Time
+	received int64
+	sent     int64
+
+	// firstReq and lastReq are samples of the received requests.
+	firstReq *structpb.Struct
+	lastReq  *structpb.Struct
+}
+
+// recordRecv records a received request. The request is converted right away,
+// as the handler may reuse the message.
+func (s *streamSummary) recordRecv(m interface{}) error {
+	ms, err := toProtoStruct(m)
+	if err != nil {
+		return auditerrors.InterceptorError(status.Errorf(codes.Internal, "failed to convert req into a proto struct: %v", err)) //nolint:wrapcheck
+	}
+	s.received++
+	if s.firstReq == nil {
+		s.firstReq = ms
+	}
+	s.lastReq = ms
+	return nil
+}
+
+// apply sets the summary of the stream, which closed at the given time, in the
+// log request: the number of sent messages is the number of response items,
+// and the message counts, the duration and the request samples are set in the
+// Payload.Metadata under the key "stream_summary".
+func (s *streamSummary) apply(logReq *api.AuditLogRequest, end time.Time) {
+	fields := map[string]*structpb.Value{
+		"received_messages": structpb.NewNumberValue(float64(s.received)),
+		"sent_messages":     structpb.NewNumberValue(float64(s.sent)),
+		// In the JSON format of google.protobuf.Duration.
+		"duration": structpb.NewStringValue(strconv.FormatFloat(end.Sub(s.start).Seconds(), 'f', -1, 64) + "s"),
+	}
+	if s.firstReq != nil {
+		fields["first_request"] = structpb.NewStructValue(s.firstReq)
+		fields["last_request"] = structpb.NewStructValue(s.lastReq)
+	}
+
+	logReq.Payload.NumResponseItems = s.sent
+	if logReq.GetPayload().GetMetadata() == nil {
+		logReq.Payload.Metadata = &structpb.Struct{}
+	}
+	if logReq.GetPayload().GetMetadata().GetFields() == nil {
+		logReq.Payload.Metadata.Fields = map[string]*structpb.Value{}
+	}
+	logReq.Payload.Metadata.Fields[streamSummaryKey] = structpb.NewStructValue(&structpb.Struct{Fields: fields})
+}
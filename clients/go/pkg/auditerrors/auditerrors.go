@@ -51,9 +51,56 @@ const (
 
 	// ErrInterceptor is used to assert whether an error is an interceptor error.
 	ErrInterceptor = Error("audit interceptor")
+
+	// ErrQueueFull is the error returned when an asynchronous audit client
+	// drops a log request because its queue is full.
+	ErrQueueFull = Error("audit log queue is full")
+
+	// ErrClientStopped is the error returned when a log request is sent to an
+	// audit client that has been stopped.
+	ErrClientStopped = Error("audit client is stopped")
 )
 
 // InterceptorError wraps an error with ErrInterceptor.
 func InterceptorError(err error) error {
 	return fmt.Errorf("%w: %w", ErrInterceptor, err)
 }
+
+// BatchError is returned by batch log processors to report the result of
+// each log request of a batch, see audit.BatchLogProcessor. It lives in this
+// package so that log processors can return it without depending on the
+// audit package.
+type BatchError struct {
+	// Errs holds the error of each log request, in the order of the batch, or
+	// nil if the log request succeeded.
+	Errs []error
+}
+
+// Error satisfies the error interface.
+func (e *BatchError) Error() string {
+	var failed int
+	var first error
+	for _, err := range e.Errs {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	if first == nil {
+		return fmt.Sprintf("0 of %d log requests failed", len(e.Errs))
+	}
+	return fmt.Sprintf("%d of %d log requests failed, first error: %v", failed, len(e.Errs), first)
+}
+
+// Unwrap returns the errors of the failed log requests.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
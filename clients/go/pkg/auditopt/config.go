@@ -37,23 +37,33 @@ package auditopt
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 
-	"cloud.google.com/go/logging"
+	cloudloggingsdk "cloud.google.com/go/logging"
 	"github.com/sethvargo/go-envconfig"
 
 	jvspb "github.com/abcxyz/jvs/apis/v0"
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/cloudlogging"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/file"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/filtering"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/justification"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/otlp"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/pubsub"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/remote"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/resilience"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/security"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/stdout"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/syslog"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/webhook"
 	"github.com/abcxyz/pkg/cfgloader"
+	"github.com/abcxyz/pkg/logging"
 )
 
 const DefaultConfigFilePath = "/etc/lumberjack/config.yaml"
@@ -96,7 +106,8 @@ func fromConfigFile(path string, lookuper envconfig.Lookuper) audit.Option {
 
 // InterceptorFromConfigFile returns an interceptor option from the given
 // config file. The returned option can be used to create an interceptor
-// to add capability to gRPC server.
+// to add capability to gRPC server, or to an HTTP server with
+// audit.Interceptor.HTTPHandler.
 func InterceptorFromConfigFile(path string) audit.InterceptorOption {
 	return interceptorFromConfigFile(path, envconfig.OsLookuper())
 }
@@ -124,6 +135,7 @@ func interceptorFromConfigFile(path string, lookuper envconfig.Lookuper) audit.I
 		opts := []audit.InterceptorOption{
 			audit.WithInterceptorLogMode(cfg.GetLogMode()),
 			audit.WithAuditRules(cfg.Rules...),
+			audit.WithClientAuditRules(cfg.ClientRules...),
 		}
 
 		// Add security context to interceptor.
@@ -177,6 +189,14 @@ func clientFromConfig(ctx context.Context, c *audit.Client, cfg *api.Config) err
 		opts = append(opts, withPrincipalFilter)
 	}
 
+	withSampling, err := samplingFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if withSampling != nil {
+		opts = append(opts, withSampling)
+	}
+
 	withBackends, err := backendsFromConfig(ctx, cfg)
 	if err != nil {
 		return err
@@ -222,25 +242,77 @@ func principalFilterFromConfig(cfg *api.Config) (audit.Option, error) {
 	return audit.WithValidator(m), nil
 }
 
+func samplingFromConfig(cfg *api.Config) (audit.Option, error) {
+	if cfg.Sampling == nil {
+		return nil, nil
+	}
+	var opts []audit.SamplingOption
+	switch cfg.Sampling.Key {
+	case api.SamplingKeyPrincipal:
+		opts = append(opts, audit.WithSamplingKey(audit.SampleByPrincipal))
+	case api.SamplingKeyOperationID:
+		opts = append(opts, audit.WithSamplingKey(audit.SampleByOperationID))
+	}
+	for logType, rate := range cfg.Sampling.LogTypes {
+		t := api.AuditLogRequest_LogType(api.AuditLogRequest_LogType_value[logType])
+		opts = append(opts, audit.WithLogTypeSamplingRate(t, rate))
+	}
+	for _, r := range cfg.Sampling.Rules {
+		opts = append(opts, audit.WithSelectorSamplingRate(r.Selector, r.Rate))
+	}
+	p, err := audit.NewSamplingProcessor(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampling processor: %w", err)
+	}
+	// The client adds it before the labels and justification mutators, so
+	// that dropped requests don't cost a justification check.
+	return audit.WithMutator(p), nil
+}
+
 func backendsFromConfig(ctx context.Context, cfg *api.Config) ([]audit.Option, error) {
 	var backendOpts []audit.Option
 
 	if cfg.Backend.Remote != nil {
-		addr := cfg.Backend.Remote.Address
-		authopts := []remote.Option{}
-		if !cfg.Backend.Remote.InsecureEnabled {
-			impersonate := cfg.Backend.Remote.ImpersonateAccount
-			if impersonate == "" {
+		rcfg := cfg.Backend.Remote
+		addrs := rcfg.Addresses
+		if rcfg.Address != "" {
+			addrs = append([]string{rcfg.Address}, addrs...)
+		}
+		authopts := []remote.Option{remote.WithAddresses(addrs[1:]...)}
+		if rcfg.LoadBalancingPolicy == api.RemotePolicyRoundRobin {
+			authopts = append(authopts, remote.WithLoadBalancingPolicy(remote.RoundRobin))
+		}
+		if rcfg.EjectionDuration > 0 {
+			authopts = append(authopts, remote.WithEjectionDuration(rcfg.EjectionDuration))
+		}
+		if !rcfg.InsecureEnabled {
+			impersonate := rcfg.ImpersonateAccount
+			switch {
+			case rcfg.IDTokenDisabled:
+			case impersonate == "":
 				authopts = append(authopts, remote.WithDefaultAuth())
-			} else {
+			default:
 				authopts = append(authopts, remote.WithImpersonatedIDTokenAuth(ctx, impersonate))
 			}
+			authopts = append(authopts, remote.WithTLS(&remote.TLSConfig{
+				CAFile:     rcfg.CAFile,
+				CertFile:   rcfg.CertFile,
+				KeyFile:    rcfg.KeyFile,
+				ServerName: rcfg.ServerName,
+			}))
 		}
-		b, err := remote.NewProcessor(addr, authopts...)
+		if rcfg.MaxBatchSize > 1 {
+			authopts = append(authopts, remote.WithBatching(int(rcfg.MaxBatchSize), rcfg.BatchDelay)) //nolint:gosec // Batch sizes are small.
+		}
+		b, err := remote.NewProcessor(addrs[0], authopts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create remote processor: %w", err)
 		}
-		backendOpts = append(backendOpts, audit.WithBackend(b))
+		rb, err := withResilience(ctx, cfg, "remote", b)
+		if err != nil {
+			return nil, err
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(rb))
 	}
 
 	if cfg.Backend.CloudLogging != nil {
@@ -248,6 +320,7 @@ func backendsFromConfig(ctx context.Context, cfg *api.Config) ([]audit.Option, e
 		if cfg.GetLogMode() == api.AuditLogRequest_BEST_EFFORT {
 			opts = append(opts, cloudlogging.WithDefaultBestEffort())
 		}
+		opts = append(opts, cloudLoggingRoutingOptions(cfg.Backend.CloudLogging)...)
 
 		var p *cloudlogging.Processor
 		var perr error
@@ -255,23 +328,225 @@ func backendsFromConfig(ctx context.Context, cfg *api.Config) ([]audit.Option, e
 		if cfg.Backend.CloudLogging.DefaultProject {
 			p, perr = cloudlogging.NewProcessor(ctx, opts...)
 		} else {
-			clc, err := logging.NewClient(ctx, cfg.Backend.CloudLogging.Project)
+			clc, err := cloudloggingsdk.NewClient(ctx, cfg.Backend.CloudLogging.Project)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create cloud logging client: %w", err)
 			}
-			opts = append(opts, cloudlogging.WithLoggingClient(clc))
+			opts = append(opts, cloudlogging.WithLoggingClient(clc), cloudlogging.WithTraceProject(cfg.Backend.CloudLogging.Project))
 			p, perr = cloudlogging.NewProcessor(ctx, opts...)
 		}
 
 		if perr != nil {
 			return nil, fmt.Errorf("failed to create processor: %w", perr)
 		}
+		rp, err := withResilience(ctx, cfg, "cloudlogging", p)
+		if err != nil {
+			return nil, err
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(rp))
+	}
+
+	if cfg.Backend.Stdout != nil {
+		var opts []stdout.Option
+		if cfg.Backend.Stdout.Stderr {
+			opts = append(opts, stdout.WithStderr())
+		}
+		p, err := stdout.NewProcessor(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout processor: %w", err)
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(p))
+	}
+
+	if cfg.Backend.File != nil {
+		opts := []file.Option{
+			file.WithMaxSize(cfg.Backend.File.MaxSizeBytes),
+			file.WithRotationInterval(cfg.Backend.File.RotationInterval),
+			file.WithFileMode(cfg.Backend.File.GetPermissions()),
+		}
+		if cfg.Backend.File.Compress {
+			opts = append(opts, file.WithCompression())
+		}
+		p, err := file.NewProcessor(cfg.Backend.File.Path, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file processor: %w", err)
+		}
 		backendOpts = append(backendOpts, audit.WithBackend(p))
 	}
 
+	if cfg.Backend.Webhook != nil {
+		wcfg := cfg.Backend.Webhook
+		opts := []webhook.Option{
+			webhook.WithHeaders(wcfg.Headers),
+			webhook.WithTimeout(wcfg.Timeout),
+		}
+		if wcfg.HMACSecret != "" {
+			opts = append(opts, webhook.WithHMACSecret([]byte(wcfg.HMACSecret)))
+		}
+		if wcfg.MaxBatchSize > 1 {
+			opts = append(opts, webhook.WithBatching(int(wcfg.MaxBatchSize), wcfg.BatchDelay)) //nolint:gosec // Batch sizes are small.
+		}
+		if wcfg.InsecureEnabled {
+			opts = append(opts, webhook.WithInsecure())
+		}
+		p, err := webhook.NewProcessor(wcfg.URL, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook processor: %w", err)
+		}
+		rp, err := withResilience(ctx, cfg, "webhook", p)
+		if err != nil {
+			return nil, err
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(rp))
+	}
+
+	if cfg.Backend.OTLP != nil {
+		ocfg := cfg.Backend.OTLP
+		protocol := otlp.ProtocolGRPC
+		if ocfg.Protocol == api.OTLPProtocolHTTP {
+			protocol = otlp.ProtocolHTTP
+		}
+		var opts []otlp.Option
+		if ocfg.Endpoint != "" {
+			opts = append(opts, otlp.WithEndpointURL(protocol, ocfg.Endpoint))
+		}
+		if ocfg.Headers != nil {
+			opts = append(opts, otlp.WithHeaders(ocfg.Headers))
+		}
+		if ocfg.Timeout > 0 {
+			opts = append(opts, otlp.WithTimeout(ocfg.Timeout))
+		}
+		p, err := otlp.NewProcessor(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP processor: %w", err)
+		}
+		rp, err := withResilience(ctx, cfg, "otlp", p)
+		if err != nil {
+			return nil, err
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(rp))
+	}
+
+	if cfg.Backend.Syslog != nil {
+		scfg := cfg.Backend.Syslog
+		facility, err := syslog.ParseFacility(scfg.Facility)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create syslog processor: %w", err)
+		}
+		opts := []syslog.Option{
+			syslog.WithFacility(facility),
+			syslog.WithAppName(scfg.AppName),
+			syslog.WithTimeout(scfg.Timeout),
+		}
+		if scfg.CAFile != "" {
+			pem, err := os.ReadFile(scfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read syslog CA file: %w", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in syslog CA file %q", scfg.CAFile)
+			}
+			opts = append(opts, syslog.WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}))
+		}
+		p, err := syslog.NewProcessor(syslog.Network(scfg.Network), scfg.Address, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create syslog processor: %w", err)
+		}
+		rp, err := withResilience(ctx, cfg, "syslog", p)
+		if err != nil {
+			return nil, err
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(rp))
+	}
+
+	if cfg.Backend.PubSub != nil {
+		var opts []pubsub.Option
+		if cfg.GetLogMode() == api.AuditLogRequest_BEST_EFFORT {
+			opts = append(opts, pubsub.WithDefaultBestEffort())
+		}
+		if cfg.Backend.PubSub.OrderingEnabled {
+			opts = append(opts, pubsub.WithOrdering())
+		}
+		p, err := pubsub.NewProcessor(ctx, cfg.Backend.PubSub.Topic, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Pub/Sub processor: %w", err)
+		}
+		rp, err := withResilience(ctx, cfg, "pubsub", p)
+		if err != nil {
+			return nil, err
+		}
+		backendOpts = append(backendOpts, audit.WithBackend(rp))
+	}
+
 	return backendOpts, nil
 }
 
+// cloudLoggingRoutingOptions returns the Cloud Logging processor options for
+// the configured log names and routes.
+func cloudLoggingRoutingOptions(cfg *api.CloudLogging) []cloudlogging.Option {
+	var opts []cloudlogging.Option
+	if cfg.LogNamePrefix != "" {
+		opts = append(opts, cloudlogging.WithLogNamePrefix(cfg.LogNamePrefix))
+	}
+	if len(cfg.LogNames) > 0 {
+		logNames := make(map[api.AuditLogRequest_LogType]string, len(cfg.LogNames))
+		for logType, logName := range cfg.LogNames {
+			logNames[api.AuditLogRequest_LogType(api.AuditLogRequest_LogType_value[logType])] = logName
+		}
+		opts = append(opts, cloudlogging.WithLogNames(logNames))
+	}
+	for _, r := range cfg.Routes {
+		logTypes := make([]api.AuditLogRequest_LogType, 0, len(r.LogTypes))
+		for _, logType := range r.LogTypes {
+			logTypes = append(logTypes, api.AuditLogRequest_LogType(api.AuditLogRequest_LogType_value[logType]))
+		}
+		opts = append(opts, cloudlogging.WithRoute(&cloudlogging.Route{
+			Labels:      r.Labels,
+			ServiceName: r.ServiceName,
+			LogTypes:    logTypes,
+			Project:     r.Project,
+			LogName:     r.LogName,
+		}))
+	}
+	return opts
+}
+
+// withResilience wraps the backend with retries and a circuit breaker when
+// the config asks for either. Circuit breaker state transitions are logged.
+func withResilience(ctx context.Context, cfg *api.Config, name string, b audit.LogProcessor) (audit.LogProcessor, error) {
+	retryCfg, cbCfg := cfg.Backend.Retry, cfg.Backend.CircuitBreaker
+	if retryCfg == nil && cbCfg == nil {
+		return b, nil
+	}
+
+	// Without a retry config, the log request is only sent once.
+	opts := []resilience.Option{resilience.WithMaxAttempts(1)}
+	if retryCfg != nil {
+		opts = []resilience.Option{
+			resilience.WithMaxAttempts(retryCfg.MaxAttempts),
+			resilience.WithBackoff(retryCfg.InitialBackoff, retryCfg.MaxBackoff, retryCfg.JitterPercent),
+		}
+	}
+	if cbCfg != nil {
+		logger := logging.FromContext(ctx)
+		opts = append(opts,
+			resilience.WithCircuitBreaker(cbCfg.FailureThreshold, cbCfg.OpenTimeout),
+			resilience.WithStateChangeHook(func(ctx context.Context, from, to resilience.State) {
+				logger.WarnContext(ctx, "audit log backend circuit breaker changed state",
+					"backend", name,
+					"from", from.String(),
+					"to", to.String())
+			}))
+	}
+
+	p, err := resilience.NewProcessor(b, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resilience processor for %s backend: %w", name, err)
+	}
+	return p, nil
+}
+
 func labelsFromConfig(ctx context.Context, cfg *api.Config) audit.Option {
 	lp := audit.NewLabelProcessor(ctx, cfg.Labels)
 	return audit.WithMutator(lp)
@@ -16,10 +16,15 @@ package auditopt
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sethvargo/go-envconfig"
@@ -30,6 +35,7 @@ import (
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/lumberjack/pkg/validation"
 	"github.com/abcxyz/pkg/logging"
 	pkgtestutil "github.com/abcxyz/pkg/testutil"
 )
@@ -196,7 +202,7 @@ func TestFromConfig(t *testing.T) {
 			testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
 	}, {
 		name:          "invalid_config_error",
-		cfg:           &api.Config{}, // Empty config is invalid
+		cfg:           &api.Config{Version: "v0"},
 		wantErrSubstr: "invalid configuration:",
 	}}
 
@@ -228,6 +234,108 @@ func TestFromConfig(t *testing.T) {
 	}
 }
 
+func TestFromConfig_FileBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+
+	c, err := audit.NewClient(ctx, FromConfig(&api.Config{
+		Backend: &api.Backend{
+			File: &api.File{Path: logPath},
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Log(ctx, testutil.NewRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validation.Validate(string(b)); err != nil {
+		t.Errorf("file backend wrote invalid log entry %q: %v", b, err)
+	}
+}
+
+func TestFromConfig_WebhookBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := audit.NewClient(ctx, FromConfig(&api.Config{
+		Backend: &api.Backend{
+			Webhook: &api.Webhook{
+				URL:             srv.URL,
+				Headers:         map[string]string{"Authorization": "Bearer token"},
+				InsecureEnabled: true,
+			},
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Log(ctx, testutil.NewRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotAuth, "Bearer token"; got != want {
+		t.Errorf("webhook got Authorization header %q, want %q", got, want)
+	}
+}
+
+func TestFromConfig_SyslogBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c, err := audit.NewClient(ctx, FromConfig(&api.Config{
+		Backend: &api.Backend{
+			Syslog: &api.Syslog{
+				Address:  conn.LocalAddr().String(),
+				Facility: "local0",
+				AppName:  "myapp",
+			},
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Log(ctx, testutil.NewRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64*1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// local0.info is priority 134.
+	if got, want := string(buf[:n]), "<134>1 "; !strings.HasPrefix(got, want) || !strings.Contains(got, " myapp ") {
+		t.Errorf("syslog message got %q, want prefix %q and app name myapp", got, want)
+	}
+}
+
 func TestLoadConfig(t *testing.T) {
 	t.Parallel()
 
@@ -361,6 +469,135 @@ justification:
 				},
 			},
 		},
+		{
+			name: "backend_retry_and_circuit_breaker",
+			fileContent: `
+version: v1alpha1
+backend:
+  remote:
+    address: foo:443
+    insecure_enabled: true
+  retry:
+    max_attempts: 5
+    initial_backoff: 50ms
+  circuit_breaker:
+    open_timeout: 1m
+`,
+			wantCfg: &api.Config{
+				Version: "v1alpha1",
+				LogMode: api.AuditLogRequest_FAIL_CLOSE.String(),
+				Backend: &api.Backend{
+					Remote: &api.Remote{Address: "foo:443", InsecureEnabled: true},
+					Retry: &api.Retry{
+						MaxAttempts:    5,
+						InitialBackoff: 50 * time.Millisecond,
+						MaxBackoff:     2 * time.Second,
+						JitterPercent:  10,
+					},
+					CircuitBreaker: &api.CircuitBreaker{
+						FailureThreshold: 5,
+						OpenTimeout:      time.Minute,
+					},
+				},
+			},
+		},
+		{
+			name: "sampling",
+			fileContent: `
+version: v1alpha1
+backend:
+  remote:
+    address: foo:443
+    insecure_enabled: true
+sampling:
+  key: PRINCIPAL
+  log_types:
+    DATA_ACCESS: 0.1
+  rules:
+  - selector: /com.example.Service/Get*
+    rate: 0.001
+  - selector: /com.example.Service/GetSecret
+    rate: 1
+`,
+			wantCfg: &api.Config{
+				Version: "v1alpha1",
+				LogMode: api.AuditLogRequest_FAIL_CLOSE.String(),
+				Backend: &api.Backend{
+					Remote: &api.Remote{Address: "foo:443", InsecureEnabled: true},
+				},
+				Sampling: &api.Sampling{
+					Key:      "PRINCIPAL",
+					LogTypes: map[string]float64{"DATA_ACCESS": 0.1},
+					Rules: []*api.SamplingRule{
+						{Selector: "/com.example.Service/Get*", Rate: 0.001},
+						{Selector: "/com.example.Service/GetSecret", Rate: 1},
+					},
+				},
+			},
+		},
+		{
+			name: "cloudlogging_routing",
+			fileContent: `
+version: v1alpha1
+backend:
+  cloudlogging:
+    default_project: true
+    log_name_prefix: audit.example.com/
+    log_names:
+      ADMIN_ACTIVITY: admin-audit
+    routes:
+    - labels:
+        region: eu
+      log_types:
+      - DATA_ACCESS
+      project: eu-project
+    - service_name: billing
+      log_name: billing-audit
+`,
+			wantCfg: &api.Config{
+				Version: "v1alpha1",
+				LogMode: api.AuditLogRequest_FAIL_CLOSE.String(),
+				Backend: &api.Backend{
+					CloudLogging: &api.CloudLogging{
+						DefaultProject: true,
+						LogNamePrefix:  "audit.example.com/",
+						LogNames:       map[string]string{"ADMIN_ACTIVITY": "admin-audit"},
+						Routes: []*api.CloudLoggingRoute{
+							{
+								Labels:   map[string]string{"region": "eu"},
+								LogTypes: []string{"DATA_ACCESS"},
+								Project:  "eu-project",
+							},
+							{ServiceName: "billing", LogName: "billing-audit"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "remote_failover",
+			fileContent: `
+version: v1alpha1
+backend:
+  remote:
+    addresses:
+    - agent.us-central1.example.com:443
+    - agent.us-east1.example.com:443
+    load_balancing_policy: round_robin
+    ejection_duration: 1m
+`,
+			wantCfg: &api.Config{
+				Version: "v1alpha1",
+				LogMode: api.AuditLogRequest_FAIL_CLOSE.String(),
+				Backend: &api.Backend{
+					Remote: &api.Remote{
+						Addresses:           []string{"agent.us-central1.example.com:443", "agent.us-east1.example.com:443"},
+						LoadBalancingPolicy: api.RemotePolicyRoundRobin,
+						EjectionDuration:    time.Minute,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
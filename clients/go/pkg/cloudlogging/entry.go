@@ -0,0 +1,235 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudlogging
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+	auditpb "google.golang.org/genproto/googleapis/cloud/audit"
+	ltype "google.golang.org/genproto/googleapis/logging/type"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// The keys of the AuditLogRequest context honored by the processor. Each
+// value is a struct in the JSON form of the corresponding proto message.
+const (
+	// ContextKeyResource is the monitored resource of the log entry, a
+	// google.api.MonitoredResource. It overrides the runtime resource.
+	ContextKeyResource = "resource"
+
+	// ContextKeySeverity is the severity of the log entry, a string such as
+	// "WARNING". It overrides the severity derived from the payload status.
+	ContextKeySeverity = "severity"
+
+	// ContextKeyHTTPRequest is the HTTP request of the log entry, a
+	// google.logging.type.HttpRequest. It overrides the HTTP request derived
+	// from the payload request metadata.
+	ContextKeyHTTPRequest = "http_request"
+
+	// ContextKeySourceLocation is the source code location of the log entry, a
+	// google.logging.v2.LogEntrySourceLocation.
+	ContextKeySourceLocation = "source_location"
+)
+
+// originatingResourceKey is the payload metadata key holding the monitored
+// resource of the runtime, see audit.WithRuntimeInfo.
+const originatingResourceKey = "originating_resource"
+
+// toEntry converts the audit log request to a Cloud Logging entry. Bare trace
+// IDs are qualified with the trace project, if any.
+func toEntry(logReq *api.AuditLogRequest, traceProject string) (logging.Entry, error) {
+	payload := logReq.GetPayload()
+	logEntry := logging.Entry{
+		Payload:      payload,
+		Labels:       logReq.GetLabels(),
+		Operation:    logReq.GetOperation(),
+		Severity:     logging.Info,
+		Trace:        logReq.GetTrace(),
+		SpanID:       logReq.GetSpanId(),
+		TraceSampled: logReq.GetTraceSampled(),
+	}
+	if logEntry.Trace != "" && traceProject != "" && !strings.Contains(logEntry.Trace, "/") {
+		logEntry.Trace = fmt.Sprintf("projects/%s/traces/%s", traceProject, logEntry.Trace)
+	}
+
+	if logReq.GetTimestamp() != nil {
+		logEntry.Timestamp = logReq.GetTimestamp().AsTime()
+	}
+
+	if c := payload.GetStatus().GetCode(); c != int32(code.Code_OK) {
+		logEntry.Severity = logging.Error
+	}
+
+	// The runtime resource of the audit client, which may differ from the
+	// resource of the logger, e.g. when logging through a remote agent.
+	originatingResource, ok := payload.GetMetadata().GetFields()[originatingResourceKey]
+	if ok {
+		var mr mrpb.MonitoredResource
+		if err := fromStructValue(originatingResource, &mr); err != nil {
+			return logging.Entry{}, fmt.Errorf("invalid payload metadata %q: %w", originatingResourceKey, err)
+		}
+		logEntry.Resource = &mr
+	}
+
+	logEntry.HTTPRequest = httpRequestFromMetadata(payload)
+
+	ctxFields := logReq.GetContext().GetFields()
+	if v, ok := ctxFields[ContextKeyResource]; ok {
+		var mr mrpb.MonitoredResource
+		if err := fromStructValue(v, &mr); err != nil {
+			return logging.Entry{}, fmt.Errorf("invalid context %q: %w", ContextKeyResource, err)
+		}
+		logEntry.Resource = &mr
+	} else if originatingResource != nil {
+		// The runtime resource is the resource of the entry, so don't repeat
+		// it in the payload.
+		logEntry.Payload = withoutMetadata(payload, originatingResourceKey)
+	}
+	if v, ok := ctxFields[ContextKeySeverity]; ok {
+		s := v.GetStringValue()
+		sev := logging.ParseSeverity(s)
+		if sev == logging.Default && !strings.EqualFold(s, "default") {
+			return logging.Entry{}, fmt.Errorf("invalid context %q: unknown severity %q", ContextKeySeverity, s)
+		}
+		logEntry.Severity = sev
+	}
+	if v, ok := ctxFields[ContextKeyHTTPRequest]; ok {
+		var r ltype.HttpRequest
+		if err := fromStructValue(v, &r); err != nil {
+			return logging.Entry{}, fmt.Errorf("invalid context %q: %w", ContextKeyHTTPRequest, err)
+		}
+		hr, err := toHTTPRequest(&r)
+		if err != nil {
+			return logging.Entry{}, fmt.Errorf("invalid context %q: %w", ContextKeyHTTPRequest, err)
+		}
+		logEntry.HTTPRequest = hr
+	}
+	if v, ok := ctxFields[ContextKeySourceLocation]; ok {
+		var sl loggingpb.LogEntrySourceLocation
+		if err := fromStructValue(v, &sl); err != nil {
+			return logging.Entry{}, fmt.Errorf("invalid context %q: %w", ContextKeySourceLocation, err)
+		}
+		logEntry.SourceLocation = &sl
+	}
+
+	return logEntry, nil
+}
+
+// withoutMetadata returns a copy of the payload without the given metadata key,
+// and without metadata if it was the only key. The payload is shared with the
+// other backends, so it's not modified.
+func withoutMetadata(payload *auditpb.AuditLog, key string) *auditpb.AuditLog {
+	p := &auditpb.AuditLog{}
+	proto.Merge(p, payload)
+	p.Metadata = nil
+	if len(payload.GetMetadata().GetFields()) > 1 {
+		fields := maps.Clone(payload.GetMetadata().GetFields())
+		delete(fields, key)
+		p.Metadata = &structpb.Struct{Fields: fields}
+	}
+	return p
+}
+
+// fromStructValue converts the struct value to the proto message.
+func fromStructValue(v *structpb.Value, m proto.Message) error {
+	if v.GetStructValue() == nil {
+		return fmt.Errorf("value is not a struct")
+	}
+	b, err := protojson.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	if err := protojson.Unmarshal(b, m); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", m.ProtoReflect().Descriptor().FullName(), err)
+	}
+	return nil
+}
+
+// httpRequestFromMetadata returns the HTTP request of the payload request
+// metadata, or nil if the audited request isn't an HTTP request.
+func httpRequestFromMetadata(payload *auditpb.AuditLog) *logging.HTTPRequest {
+	md := payload.GetRequestMetadata()
+	attrs := md.GetRequestAttributes()
+	if attrs.GetMethod() == "" {
+		return nil
+	}
+
+	u := &url.URL{
+		Scheme:   attrs.GetScheme(),
+		Host:     attrs.GetHost(),
+		Path:     attrs.GetPath(),
+		RawQuery: attrs.GetQuery(),
+	}
+	r := &http.Request{
+		Method: attrs.GetMethod(),
+		URL:    u,
+		Proto:  attrs.GetProtocol(),
+		Header: http.Header{},
+	}
+	if ua := md.GetCallerSuppliedUserAgent(); ua != "" {
+		r.Header.Set("User-Agent", ua)
+	}
+	return &logging.HTTPRequest{
+		Request:     r,
+		RequestSize: attrs.GetSize(),
+		RemoteIP:    md.GetCallerIp(),
+	}
+}
+
+// toHTTPRequest converts the HTTP request proto to the form of the logging
+// client.
+func toHTTPRequest(r *ltype.HttpRequest) (*logging.HTTPRequest, error) {
+	u, err := url.Parse(r.GetRequestUrl())
+	if err != nil {
+		return nil, fmt.Errorf("invalid request URL: %w", err)
+	}
+	req := &http.Request{
+		Method: r.GetRequestMethod(),
+		URL:    u,
+		Proto:  r.GetProtocol(),
+		Header: http.Header{},
+	}
+	if r.GetUserAgent() != "" {
+		req.Header.Set("User-Agent", r.GetUserAgent())
+	}
+	if r.GetReferer() != "" {
+		req.Header.Set("Referer", r.GetReferer())
+	}
+	return &logging.HTTPRequest{
+		Request:                        req,
+		RequestSize:                    r.GetRequestSize(),
+		Status:                         int(r.GetStatus()),
+		ResponseSize:                   r.GetResponseSize(),
+		Latency:                        r.GetLatency().AsDuration(),
+		LocalIP:                        r.GetServerIp(),
+		RemoteIP:                       r.GetRemoteIp(),
+		CacheHit:                       r.GetCacheHit(),
+		CacheValidatedWithOriginServer: r.GetCacheValidatedWithOriginServer(),
+		CacheFillBytes:                 r.GetCacheFillBytes(),
+		CacheLookup:                    r.GetCacheLookup(),
+	}, nil
+}
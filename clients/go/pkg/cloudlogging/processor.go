@@ -18,14 +18,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"cloud.google.com/go/logging"
-	"google.golang.org/protobuf/reflect/protoreflect"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/pkg/gcputil"
 )
 
+// DefaultLogNamePrefix is the prefix of the default log names, e.g.
+// "audit.abcxyz/data_access".
+const DefaultLogNamePrefix = "audit.abcxyz/"
+
+// logNameRegexp matches the valid log names, see the LogEntry documentation.
+var logNameRegexp = regexp.MustCompile(`^[A-Za-z0-9/_.\-]{1,511}$`)
+
 // Processor is the remote Cloud Logging processor.
 type Processor struct {
 	client *logging.Client
@@ -34,6 +42,22 @@ type Processor struct {
 	bestEffort bool
 	// loggerByLogType is not threadsafe.
 	loggerByLogType map[api.AuditLogRequest_LogType]*logging.Logger
+
+	// Log names, see WithLogNamePrefix and WithLogNames.
+	logNamePrefix string
+	logNames      map[api.AuditLogRequest_LogType]string
+
+	// routes are evaluated in order, the first matching one applies.
+	routes []*resolvedRoute
+	// newClient creates the clients of the routes' projects.
+	newClient func(ctx context.Context, project string) (*logging.Client, error)
+	// destinations holds one client per project, keyed by project with ""
+	// for the default project.
+	destinations map[string]*destination
+
+	// traceProject is the project of the traces with a bare trace ID, see
+	// WithTraceProject.
+	traceProject string
 }
 
 // Option is the option to set up a Cloud Logging processor.
@@ -65,10 +89,83 @@ func WithDefaultBestEffort() Option {
 	}
 }
 
+// WithLogNamePrefix replaces DefaultLogNamePrefix in the log names of the
+// log types, e.g. with the "audit.example.com/" prefix, DATA_ACCESS audit logs
+// go to the "audit.example.com/data_access" log.
+func WithLogNamePrefix(prefix string) Option {
+	return func(p *Processor) error {
+		if prefix != "" && !logNameRegexp.MatchString(prefix) {
+			return fmt.Errorf("invalid log name prefix %q", prefix)
+		}
+		p.logNamePrefix = prefix
+		return nil
+	}
+}
+
+// WithLogNames sets the log names of the given log types, overriding the
+// default log names and WithLogNamePrefix.
+func WithLogNames(logNames map[api.AuditLogRequest_LogType]string) Option {
+	return func(p *Processor) error {
+		for logType, logName := range logNames {
+			if !logNameRegexp.MatchString(logName) {
+				return fmt.Errorf("invalid log name %q for log type %v", logName, logType)
+			}
+			p.logNames[logType] = logName
+		}
+		return nil
+	}
+}
+
+// WithRoute adds a route for the matching audit log requests. Routes are
+// evaluated in the order they are added, and the first matching route
+// applies. Audit log requests that match no route go to the default project
+// and log names.
+func WithRoute(r *Route) Option {
+	return func(p *Processor) error {
+		if r.Project == "" && r.LogName == "" {
+			return fmt.Errorf("route must set a project or a log name")
+		}
+		if r.LogName != "" && !logNameRegexp.MatchString(r.LogName) {
+			return fmt.Errorf("invalid route log name %q", r.LogName)
+		}
+		p.routes = append(p.routes, &resolvedRoute{Route: r})
+		return nil
+	}
+}
+
+// WithTraceProject sets the project of the traces that audit log requests
+// reference by a bare trace ID, so that the log entries link to the trace in
+// Cloud Trace. The default is the project of the default client when the
+// processor creates it.
+func WithTraceProject(project string) Option {
+	return func(p *Processor) error {
+		p.traceProject = project
+		return nil
+	}
+}
+
+// WithClientFactory sets the function creating the Cloud Logging clients of
+// the routes' projects, e.g. to set client options. The processor creates a
+// single client per project, and closes it on Stop. The default is
+// logging.NewClient.
+func WithClientFactory(f func(ctx context.Context, project string) (*logging.Client, error)) Option {
+	return func(p *Processor) error {
+		p.newClient = f
+		return nil
+	}
+}
+
 // NewProcessor creates a new Cloud Logging log processor with the given
 // options.
 func NewProcessor(ctx context.Context, opts ...Option) (*Processor, error) {
-	p := &Processor{}
+	p := &Processor{
+		logNamePrefix: DefaultLogNamePrefix,
+		logNames:      map[api.AuditLogRequest_LogType]string{},
+		newClient: func(ctx context.Context, project string) (*logging.Client, error) {
+			return logging.NewClient(ctx, project) //nolint:wrapcheck // Wrapped by the caller.
+		},
+		destinations: map[string]*destination{},
+	}
 	for _, o := range opts {
 		if err := o(p); err != nil {
 			return nil, fmt.Errorf("failed to apply client options: %w", err)
@@ -83,53 +180,73 @@ func NewProcessor(ctx context.Context, opts ...Option) (*Processor, error) {
 			return nil, fmt.Errorf("error creating the default Cloud Logging client for project %v: %w", projectID, err)
 		}
 		p.client = client
+		if p.traceProject == "" {
+			p.traceProject = projectID
+		}
 	}
 
-	loggerByLogType := map[api.AuditLogRequest_LogType]*logging.Logger{}
+	logNames := map[api.AuditLogRequest_LogType]string{}
 	for v := range api.AuditLogRequest_LogType_name {
 		logType := api.AuditLogRequest_LogType(v)
-		logName := logNameFromLogType(logType)
-		if logName == "" {
-			return nil, fmt.Errorf("the log type %v is not annotated with a log name", logType)
+		logName, ok := p.logNames[logType]
+		if !ok {
+			logName = api.LogName(logType)
+			if logName == "" {
+				return nil, fmt.Errorf("the log type %v is not annotated with a log name", logType)
+			}
+			logName = p.logNamePrefix + strings.TrimPrefix(logName, DefaultLogNamePrefix)
 		}
-		loggerByLogType[logType] = p.client.Logger(logName)
+		logNames[logType] = logName
+	}
+
+	defaultDest, err := p.destination(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	loggerByLogType := map[api.AuditLogRequest_LogType]*logging.Logger{}
+	for logType, logName := range logNames {
+		loggerByLogType[logType] = defaultDest.logger(logName)
 	}
 	p.loggerByLogType = loggerByLogType
-	return p, nil
-}
 
-// logNameFromLogType obtains the Cloud Logging LogName by reading the
-// proto annotation of the AuditLogRequest.Type. If the proto annotation
-// is missing, we use a default logName.
-func logNameFromLogType(t api.AuditLogRequest_LogType) string {
-	enumOpts := t.Descriptor().Values().ByNumber(t.Number()).Options().ProtoReflect()
-	var logName string
-	enumOpts.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
-		if fd.Name() == "log_name" {
-			logName = v.String()
-			return false
-		}
-		return true
-	})
-	return logName
+	for _, r := range p.routes {
+		d, err := p.destination(ctx, r.Project)
+		if err != nil {
+			// Don't leak the clients created so far.
+			return nil, errors.Join(err, p.closeClients())
+		}
+		r.loggerByLogType = map[api.AuditLogRequest_LogType]*logging.Logger{}
+		for logType, logName := range logNames {
+			if r.LogName != "" {
+				logName = r.LogName
+			}
+			r.loggerByLogType[logType] = d.logger(logName)
+		}
+	}
+	return p, nil
 }
 
-// Process emits an audit logs to Cloud Logging synchronously.
+// Process emits an audit logs to Cloud Logging synchronously. The log entry
+// gets the runtime resource, the severity and the HTTP request derived from
+// the audit log, which the ContextKey* keys of the log request context
+// override, and the trace and span of the audit log request.
 func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
-	logger, ok := p.loggerByLogType[logReq.GetType()]
+	loggerByLogType := p.loggerByLogType
+	for _, r := range p.routes {
+		if r.matches(logReq) {
+			loggerByLogType = r.loggerByLogType
+			break
+		}
+	}
+	logger, ok := loggerByLogType[logReq.GetType()]
 	if !ok {
 		// Hitting this code path would be unlikely because NewProcessor
 		// creates loggers for every log type in the AuditLogRequest proto.
-		logger = p.loggerByLogType[api.AuditLogRequest_UNSPECIFIED]
+		logger = loggerByLogType[api.AuditLogRequest_UNSPECIFIED]
 	}
-	logEntry := logging.Entry{
-		Payload:   logReq.GetPayload(),
-		Labels:    logReq.GetLabels(),
-		Operation: logReq.GetOperation(),
-	}
-
-	if logReq.GetTimestamp() != nil {
-		logEntry.Timestamp = logReq.GetTimestamp().AsTime()
+	logEntry, err := toEntry(logReq, p.traceProject)
+	if err != nil {
+		return fmt.Errorf("failed to convert audit log request to log entry: %w", err)
 	}
 
 	bestEffort := p.bestEffort
@@ -147,15 +264,45 @@ func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) er
 	return nil
 }
 
-// Stop stops the processor by flushing the logs from all loggers.
-// Stop is only meaningful when the client emitted logs as best-effort.
+// Stop stops the processor by flushing the logs from all loggers, and closes
+// the clients of the routes' projects.
+// Flushing is only meaningful when the client emitted logs as best-effort.
 func (p *Processor) Stop() error {
 	var merr error
+	flushed := map[*logging.Logger]bool{}
 	for logtype, logger := range p.loggerByLogType {
+		flushed[logger] = true
 		if err := logger.Flush(); err != nil {
 			merr = errors.Join(merr, fmt.Errorf("failed to flush %s logs: %w", logtype, err))
 		}
 	}
+	// The other loggers of the default project, created by the routes.
+	for logName, logger := range p.destinations[""].loggers {
+		if flushed[logger] {
+			continue
+		}
+		if err := logger.Flush(); err != nil {
+			merr = errors.Join(merr, fmt.Errorf("failed to flush %s logs: %w", logName, err))
+		}
+	}
+	// Closing the clients of the routes' projects flushes their loggers.
+	if err := p.closeClients(); err != nil {
+		merr = errors.Join(merr, err)
+	}
+
+	return merr
+}
 
+// closeClients closes the clients created by the processor.
+func (p *Processor) closeClients() error {
+	var merr error
+	for project, d := range p.destinations {
+		if !d.owned {
+			continue
+		}
+		if err := d.client.Close(); err != nil {
+			merr = errors.Join(merr, fmt.Errorf("failed to close Cloud Logging client for project %v: %w", project, err))
+		}
+	}
 	return merr
 }
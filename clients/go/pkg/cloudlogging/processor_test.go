@@ -17,17 +17,29 @@ package cloudlogging
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/logging"
 	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/api/option"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
 	"google.golang.org/genproto/googleapis/cloud/audit"
+	ltype "google.golang.org/genproto/googleapis/logging/type"
+	"google.golang.org/genproto/googleapis/rpc/context/attribute_context"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
@@ -39,9 +51,24 @@ type fakeServer struct {
 
 	resp      *logpb.WriteLogEntriesResponse
 	returnErr error
+
+	mu      sync.Mutex
+	entries []*logpb.LogEntry
+	// logNames holds the log name of each entry.
+	logNames []string
 }
 
 func (s *fakeServer) WriteLogEntries(_ context.Context, req *logpb.WriteLogEntriesRequest) (*logpb.WriteLogEntriesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, req.GetEntries()...)
+	for _, e := range req.GetEntries() {
+		logName := e.GetLogName()
+		if logName == "" {
+			logName = req.GetLogName()
+		}
+		s.logNames = append(s.logNames, logName)
+	}
 	return s.resp, s.returnErr
 }
 
@@ -304,3 +331,411 @@ func TestProcessor_Stop(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessor_LogEntry(t *testing.T) {
+	t.Parallel()
+
+	resource := &mrpb.MonitoredResource{
+		Type:   "cloud_run_revision",
+		Labels: map[string]string{"service_name": "my-service"},
+	}
+	resourceVal, err := toStructValue(resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctxResource := &mrpb.MonitoredResource{
+		Type:   "gce_instance",
+		Labels: map[string]string{"instance_id": "123"},
+	}
+	ctxResourceVal, err := toStructValue(ctxResource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		logReq        *api.AuditLogRequest
+		wantEntry     *logpb.LogEntry
+		wantMetadata  *structpb.Struct
+		wantErrSubstr string
+	}{
+		{
+			name: "success_is_info",
+			logReq: &api.AuditLogRequest{
+				Type:    api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{ServiceName: "test-service"},
+			},
+			wantEntry: &logpb.LogEntry{
+				Severity: ltype.LogSeverity_INFO,
+			},
+		},
+		{
+			name: "failure_is_error",
+			logReq: &api.AuditLogRequest{
+				Type: api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{
+					ServiceName: "test-service",
+					Status:      &rpcstatus.Status{Code: int32(codes.PermissionDenied)},
+				},
+			},
+			wantEntry: &logpb.LogEntry{
+				Severity: ltype.LogSeverity_ERROR,
+			},
+		},
+		{
+			name: "runtime_resource_and_request_metadata",
+			logReq: &api.AuditLogRequest{
+				Type: api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{
+					ServiceName: "test-service",
+					Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+						"originating_resource": resourceVal,
+						"foo":                  structpb.NewStringValue("bar"),
+					}},
+					RequestMetadata: &audit.RequestMetadata{
+						CallerIp:                "10.0.0.1",
+						CallerSuppliedUserAgent: "curl/8.0",
+						RequestAttributes: &attribute_context.AttributeContext_Request{
+							Method: "GET",
+							Scheme: "https",
+							Host:   "example.com",
+							Path:   "/v1/items",
+							Query:  "page=2",
+						},
+					},
+				},
+			},
+			wantEntry: &logpb.LogEntry{
+				Resource: resource,
+				Severity: ltype.LogSeverity_INFO,
+				HttpRequest: &ltype.HttpRequest{
+					RequestMethod: "GET",
+					RequestUrl:    "https://example.com/v1/items?page=2",
+					UserAgent:     "curl/8.0",
+					RemoteIp:      "10.0.0.1",
+				},
+			},
+			wantMetadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"foo": structpb.NewStringValue("bar"),
+			}},
+		},
+		{
+			name: "context_overrides",
+			logReq: &api.AuditLogRequest{
+				Type: api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{
+					ServiceName: "test-service",
+					Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+						"originating_resource": resourceVal,
+					}},
+				},
+				Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+					ContextKeyResource: ctxResourceVal,
+					ContextKeySeverity: structpb.NewStringValue("notice"),
+					ContextKeyHTTPRequest: structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+						"requestMethod": structpb.NewStringValue("POST"),
+						"requestUrl":    structpb.NewStringValue("https://example.com/v1/items"),
+						"status":        structpb.NewNumberValue(201),
+						"latency":       structpb.NewStringValue("1.5s"),
+					}}),
+					ContextKeySourceLocation: structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+						"file":     structpb.NewStringValue("server.go"),
+						"line":     structpb.NewStringValue("42"),
+						"function": structpb.NewStringValue("main.handle"),
+					}}),
+				}},
+			},
+			wantEntry: &logpb.LogEntry{
+				Resource: ctxResource,
+				Severity: ltype.LogSeverity_NOTICE,
+				HttpRequest: &ltype.HttpRequest{
+					RequestMethod: "POST",
+					RequestUrl:    "https://example.com/v1/items",
+					Status:        201,
+					Latency:       durationpb.New(1500 * time.Millisecond),
+				},
+				SourceLocation: &logpb.LogEntrySourceLocation{
+					File:     "server.go",
+					Line:     42,
+					Function: "main.handle",
+				},
+			},
+			wantMetadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"originating_resource": resourceVal,
+			}},
+		},
+		{
+			name: "trace",
+			logReq: &api.AuditLogRequest{
+				Type:         api.AuditLogRequest_DATA_ACCESS,
+				Payload:      &audit.AuditLog{ServiceName: "test-service"},
+				Trace:        "06796866738c859f2f19b7cfb3214824",
+				SpanId:       "00f067aa0ba902b7",
+				TraceSampled: true,
+			},
+			wantEntry: &logpb.LogEntry{
+				Severity:     ltype.LogSeverity_INFO,
+				Trace:        "projects/trace-project/traces/06796866738c859f2f19b7cfb3214824",
+				SpanId:       "00f067aa0ba902b7",
+				TraceSampled: true,
+			},
+		},
+		{
+			name: "trace_resource_name",
+			logReq: &api.AuditLogRequest{
+				Type:    api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{ServiceName: "test-service"},
+				Trace:   "projects/other-project/traces/06796866738c859f2f19b7cfb3214824",
+				SpanId:  "00f067aa0ba902b7",
+			},
+			wantEntry: &logpb.LogEntry{
+				Severity: ltype.LogSeverity_INFO,
+				Trace:    "projects/other-project/traces/06796866738c859f2f19b7cfb3214824",
+				SpanId:   "00f067aa0ba902b7",
+			},
+		},
+		{
+			name: "invalid_context_severity",
+			logReq: &api.AuditLogRequest{
+				Type:    api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{ServiceName: "test-service"},
+				Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+					ContextKeySeverity: structpb.NewStringValue("loud"),
+				}},
+			},
+			wantErrSubstr: `invalid context "severity": unknown severity "loud"`,
+		},
+		{
+			name: "invalid_context_source_location",
+			logReq: &api.AuditLogRequest{
+				Type:    api.AuditLogRequest_DATA_ACCESS,
+				Payload: &audit.AuditLog{ServiceName: "test-service"},
+				Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+					ContextKeySourceLocation: structpb.NewStringValue("server.go:42"),
+				}},
+			},
+			wantErrSubstr: `invalid context "source_location": value is not a struct`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := &fakeServer{resp: &logpb.WriteLogEntriesResponse{}}
+			_, conn := testutil.TestFakeGRPCServer(t, func(s *grpc.Server) {
+				logpb.RegisterLoggingServiceV2Server(s, server)
+			})
+			ctx := t.Context()
+			loggingClient, err := logging.NewClient(ctx, "testProjectID", option.WithGRPCConn(conn))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := NewProcessor(ctx, WithLoggingClient(loggingClient), WithTraceProject("trace-project"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = p.Process(ctx, tc.logReq)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Fatalf("Process(%+v) got unexpected error substring: %v", tc.logReq, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			// The logging client also writes a diagnostic entry once per
+			// process, so only keep the audit log entries.
+			var entries []*logpb.LogEntry
+			for _, e := range server.entries {
+				if !strings.HasSuffix(e.GetLogName(), "/diagnostic-log") {
+					entries = append(entries, e)
+				}
+			}
+			if got, want := len(entries), 1; got != want {
+				t.Fatalf("got %d log entries, want %d", got, want)
+			}
+			got := entries[0]
+
+			gotMetadata := got.GetJsonPayload().GetFields()["metadata"].GetStructValue()
+			if diff := cmp.Diff(tc.wantMetadata, gotMetadata, protocmp.Transform()); diff != "" {
+				t.Errorf("payload metadata got diff (-want, +got): %v", diff)
+			}
+
+			// Only compare the fields derived from the audit log request
+			// beyond the payload.
+			got = &logpb.LogEntry{
+				Resource:       got.GetResource(),
+				Severity:       got.GetSeverity(),
+				HttpRequest:    got.GetHttpRequest(),
+				SourceLocation: got.GetSourceLocation(),
+				Trace:          got.GetTrace(),
+				SpanId:         got.GetSpanId(),
+				TraceSampled:   got.GetTraceSampled(),
+			}
+			if tc.wantEntry.Resource == nil {
+				// The logging client detects a default resource.
+				got.Resource = nil
+			}
+			if diff := cmp.Diff(tc.wantEntry, got, protocmp.Transform()); diff != "" {
+				t.Errorf("log entry got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_Routing(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(logType api.AuditLogRequest_LogType, service string, labels map[string]string) *api.AuditLogRequest {
+		return &api.AuditLogRequest{
+			Type:    logType,
+			Payload: &audit.AuditLog{ServiceName: service},
+			Labels:  labels,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		logReqs       []*api.AuditLogRequest
+		wantLogNames  []string
+		wantErrSubstr string
+	}{
+		{
+			name: "default_log_names",
+			logReqs: []*api.AuditLogRequest{
+				newRequest(api.AuditLogRequest_DATA_ACCESS, "svc", nil),
+				newRequest(api.AuditLogRequest_ADMIN_ACTIVITY, "svc", nil),
+			},
+			wantLogNames: []string{
+				"projects/default-project/logs/audit.abcxyz%2Fdata_access",
+				"projects/default-project/logs/audit.abcxyz%2Factivity",
+			},
+		},
+		{
+			name: "log_name_prefix_and_mapping",
+			opts: []Option{
+				WithLogNamePrefix("audit.example.com/"),
+				WithLogNames(map[api.AuditLogRequest_LogType]string{
+					api.AuditLogRequest_ADMIN_ACTIVITY: "admin-audit",
+				}),
+			},
+			logReqs: []*api.AuditLogRequest{
+				newRequest(api.AuditLogRequest_DATA_ACCESS, "svc", nil),
+				newRequest(api.AuditLogRequest_ADMIN_ACTIVITY, "svc", nil),
+			},
+			wantLogNames: []string{
+				"projects/default-project/logs/audit.example.com%2Fdata_access",
+				"projects/default-project/logs/admin-audit",
+			},
+		},
+		{
+			name: "routes",
+			opts: []Option{
+				WithRoute(&Route{
+					Labels:  map[string]string{"region": "eu"},
+					Project: "eu-project",
+				}),
+				WithRoute(&Route{
+					ServiceName: "billing",
+					LogTypes:    []api.AuditLogRequest_LogType{api.AuditLogRequest_DATA_ACCESS},
+					LogName:     "billing-data-access",
+				}),
+				WithRoute(&Route{
+					ServiceName: "billing",
+					Project:     "billing-project",
+					LogName:     "billing-audit",
+				}),
+			},
+			logReqs: []*api.AuditLogRequest{
+				newRequest(api.AuditLogRequest_DATA_ACCESS, "svc", map[string]string{"region": "eu"}),
+				newRequest(api.AuditLogRequest_DATA_ACCESS, "billing", nil),
+				newRequest(api.AuditLogRequest_ADMIN_ACTIVITY, "billing", nil),
+				// The first matching route applies.
+				newRequest(api.AuditLogRequest_ADMIN_ACTIVITY, "billing", map[string]string{"region": "eu"}),
+				newRequest(api.AuditLogRequest_DATA_ACCESS, "svc", map[string]string{"region": "us"}),
+			},
+			wantLogNames: []string{
+				"projects/eu-project/logs/audit.abcxyz%2Fdata_access",
+				"projects/default-project/logs/billing-data-access",
+				"projects/billing-project/logs/billing-audit",
+				"projects/eu-project/logs/audit.abcxyz%2Factivity",
+				"projects/default-project/logs/audit.abcxyz%2Fdata_access",
+			},
+		},
+		{
+			name:          "invalid_route",
+			opts:          []Option{WithRoute(&Route{ServiceName: "billing"})},
+			wantErrSubstr: "route must set a project or a log name",
+		},
+		{
+			name:          "invalid_log_name",
+			opts:          []Option{WithLogNames(map[api.AuditLogRequest_LogType]string{api.AuditLogRequest_DATA_ACCESS: "data access"})},
+			wantErrSubstr: `invalid log name "data access"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := &fakeServer{resp: &logpb.WriteLogEntriesResponse{}}
+			addr, conn := testutil.TestFakeGRPCServer(t, func(s *grpc.Server) {
+				logpb.RegisterLoggingServiceV2Server(s, server)
+			})
+			ctx := t.Context()
+			// Each client closes its connection.
+			newClient := func(ctx context.Context, project string) (*logging.Client, error) {
+				conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+				if err != nil {
+					return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+				}
+				return logging.NewClient(ctx, project, option.WithGRPCConn(conn))
+			}
+			loggingClient, err := logging.NewClient(ctx, "default-project", option.WithGRPCConn(conn))
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := append([]Option{WithLoggingClient(loggingClient), WithClientFactory(newClient)}, tc.opts...)
+			p, err := NewProcessor(ctx, opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, logReq := range tc.logReqs {
+				if err := p.Process(ctx, logReq); err != nil {
+					t.Fatalf("Process(%+v) unexpected error: %v", logReq, err)
+				}
+			}
+			if err := p.Stop(); err != nil {
+				t.Errorf("Stop() unexpected error: %v", err)
+			}
+
+			var gotLogNames []string
+			for _, logName := range server.logNames {
+				if !strings.HasSuffix(logName, "/diagnostic-log") {
+					gotLogNames = append(gotLogNames, logName)
+				}
+			}
+			if diff := cmp.Diff(tc.wantLogNames, gotLogNames); diff != "" {
+				t.Errorf("log names got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func toStructValue(m proto.Message) (*structpb.Value, error) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	var v structpb.Value
+	if err := protojson.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudlogging
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"cloud.google.com/go/logging"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// Route sends the matching audit log requests to another project or log than
+// the default ones, e.g. to meet data residency rules. An audit log request
+// matches a route if it matches all of its set criteria.
+type Route struct {
+	// Labels matches the log requests with all of these labels.
+	Labels map[string]string
+
+	// ServiceName matches the log requests of this service.
+	ServiceName string
+
+	// LogTypes matches the log requests of any of these log types.
+	LogTypes []api.AuditLogRequest_LogType
+
+	// Project is the project to write the matching log requests to. If empty,
+	// it's the project of the processor's client.
+	Project string
+
+	// LogName is the log to write the matching log requests to. If empty,
+	// it's the log name of the log type.
+	LogName string
+}
+
+// matches reports whether the audit log request matches the route.
+func (r *Route) matches(logReq *api.AuditLogRequest) bool {
+	for k, v := range r.Labels {
+		if got, ok := logReq.GetLabels()[k]; !ok || got != v {
+			return false
+		}
+	}
+	if r.ServiceName != "" && r.ServiceName != logReq.GetPayload().GetServiceName() {
+		return false
+	}
+	if len(r.LogTypes) > 0 && !slices.Contains(r.LogTypes, logReq.GetType()) {
+		return false
+	}
+	return true
+}
+
+// resolvedRoute is a route with the loggers of its destination.
+type resolvedRoute struct {
+	*Route
+	loggerByLogType map[api.AuditLogRequest_LogType]*logging.Logger
+}
+
+// destination is a project that audit logs are written to. Its client is
+// shared by all the loggers of the project.
+type destination struct {
+	client *logging.Client
+	// owned is whether the processor created the client, and must close it.
+	owned   bool
+	loggers map[string]*logging.Logger
+}
+
+// logger returns the logger of the log name, creating it if needed.
+func (d *destination) logger(logName string) *logging.Logger {
+	l, ok := d.loggers[logName]
+	if !ok {
+		l = d.client.Logger(logName)
+		d.loggers[logName] = l
+	}
+	return l
+}
+
+// destination returns the destination of the project, creating its client
+// if needed. The empty project is the project of the processor's client.
+func (p *Processor) destination(ctx context.Context, project string) (*destination, error) {
+	if d, ok := p.destinations[project]; ok {
+		return d, nil
+	}
+	d := &destination{client: p.client, loggers: map[string]*logging.Logger{}}
+	if project != "" {
+		client, err := p.newClient(ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the Cloud Logging client for project %v: %w", project, err)
+		}
+		d.client, d.owned = client, true
+	}
+	p.destinations[project] = d
+	return d, nil
+}
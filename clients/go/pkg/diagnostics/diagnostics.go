@@ -0,0 +1,67 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diagnostics collects the diagnostics that audit log processors
+// report about the log requests they process, e.g. warnings that don't fail
+// them, so that the AuditLogAgent can return them to its clients.
+package diagnostics
+
+import (
+	"context"
+	"sync"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+type contextKey struct{}
+
+// Collector holds the diagnostics reported for each log request.
+type Collector struct {
+	mu    sync.Mutex
+	diags map[*api.AuditLogRequest][]*api.Diagnostic
+}
+
+// NewContext returns a context collecting the diagnostics reported with Add,
+// and the Collector holding them.
+func NewContext(ctx context.Context) (context.Context, *Collector) {
+	c := &Collector{diags: make(map[*api.AuditLogRequest][]*api.Diagnostic)}
+	return context.WithValue(ctx, contextKey{}, c), c
+}
+
+// Add reports a diagnostic about the log request. It's a no-op unless the
+// context was created with NewContext. The diagnostics are keyed by the log
+// request, so they must be reported on the log request given to the audit
+// client rather than on a copy of it; backends return their diagnostics
+// instead, see audit.DiagnosticLogProcessor.
+func Add(ctx context.Context, logReq *api.AuditLogRequest, d *api.Diagnostic) {
+	c, ok := ctx.Value(contextKey{}).(*Collector)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diags[logReq] = append(c.diags[logReq], d)
+}
+
+// Warn reports a warning about the log request, see Add.
+func Warn(ctx context.Context, logReq *api.AuditLogRequest, msg string) {
+	Add(ctx, logReq, &api.Diagnostic{Severity: api.Diagnostic_WARNING, Message: msg})
+}
+
+// Get returns the diagnostics reported about the log request, in order.
+func (c *Collector) Get(logReq *api.AuditLogRequest) []*api.Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.diags[logReq]
+}
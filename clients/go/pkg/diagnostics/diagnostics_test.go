@@ -0,0 +1,56 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	ctx, c := NewContext(t.Context())
+	r1, r2 := &api.AuditLogRequest{}, &api.AuditLogRequest{}
+
+	Add(ctx, r1, &api.Diagnostic{Severity: api.Diagnostic_INFO, Message: "info"})
+	Warn(ctx, r1, "warning")
+	Warn(ctx, r2, "other")
+
+	want := []*api.Diagnostic{
+		{Severity: api.Diagnostic_INFO, Message: "info"},
+		{Severity: api.Diagnostic_WARNING, Message: "warning"},
+	}
+	if diff := cmp.Diff(want, c.Get(r1), protocmp.Transform()); diff != "" {
+		t.Errorf("Get() (-want, +got):\n%s", diff)
+	}
+	if got, want := len(c.Get(r2)), 1; got != want {
+		t.Errorf("Get() got %d diagnostics, want %d", got, want)
+	}
+	if got := c.Get(&api.AuditLogRequest{}); got != nil {
+		t.Errorf("Get() got %v for an unknown log request, want nil", got)
+	}
+
+	// Diagnostics reported without a collector are dropped.
+	Warn(context.Background(), r1, "dropped")
+	if got, want := len(c.Get(r1)), 2; got != want {
+		t.Errorf("Get() got %d diagnostics, want %d", got, want)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file provides a backend that writes audit logs to local files as
+// JSON lines, with size and time based rotation, e.g. for a SIEM forwarder to
+// ship them in on-prem or air-gapped deployments.
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/logentry"
+)
+
+const (
+	// DefaultMaxSize is the default size in bytes after which the file is
+	// rotated.
+	DefaultMaxSize = 100 << 20
+
+	// DefaultFileMode is the default permissions of the log files, readable
+	// and writable by the owner only.
+	DefaultFileMode os.FileMode = 0o600
+
+	dirFileMode = 0o700
+
+	// rotatedTimeFormat is the format of the time appended to the name of a
+	// rotated file, which sorts in rotation order.
+	rotatedTimeFormat = "20060102T150405.000000000Z"
+	compressedSuffix  = ".gz"
+)
+
+// Processor is a backend that appends each audit log request to a file as one
+// JSON line in the shape of a Cloud Logging LogEntry, see
+// logentry.MarshalLine, so that `lumberctl validate` accepts each line.
+//
+// The file is rotated when a write would exceed its maximum size, or on the
+// first write after the rotation interval elapsed since the file was opened.
+// A rotated file is renamed with the UTC rotation time appended to its name,
+// e.g. "audit.log.20261017T150405.000000000Z", and optionally gzipped in the
+// background.
+type Processor struct {
+	path             string
+	maxSize          int64
+	rotationInterval time.Duration
+	compress         bool
+	mode             os.FileMode
+
+	// now returns the current time, it's replaced in tests.
+	now func() time.Time
+
+	// mu guards the current file and its state.
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+	stopped  bool
+
+	// compressing tracks the background compressions, which Stop waits for.
+	compressing sync.WaitGroup
+	compressMu  sync.Mutex
+	compressErr error
+}
+
+// Option is a configuration option for NewProcessor.
+type Option func(p *Processor) error
+
+// WithMaxSize sets the size in bytes after which the file is rotated. Zero
+// disables size based rotation. The default is DefaultMaxSize.
+func WithMaxSize(size int64) Option {
+	return func(p *Processor) error {
+		if size < 0 {
+			return fmt.Errorf("max size must not be negative")
+		}
+		p.maxSize = size
+		return nil
+	}
+}
+
+// WithRotationInterval rotates the file on the first write after the given
+// interval elapsed since the file was opened. By default, files are not
+// rotated based on time.
+func WithRotationInterval(d time.Duration) Option {
+	return func(p *Processor) error {
+		if d < 0 {
+			return fmt.Errorf("rotation interval must not be negative")
+		}
+		p.rotationInterval = d
+		return nil
+	}
+}
+
+// WithCompression gzips the rotated files.
+func WithCompression() Option {
+	return func(p *Processor) error {
+		p.compress = true
+		return nil
+	}
+}
+
+// WithFileMode sets the permissions of the log files. The default is
+// DefaultFileMode.
+func WithFileMode(mode os.FileMode) Option {
+	return func(p *Processor) error {
+		if mode&^os.ModePerm != 0 {
+			return fmt.Errorf("file mode %v must only set permission bits", mode)
+		}
+		p.mode = mode
+		return nil
+	}
+}
+
+// NewProcessor creates a new file processor writing to the given path. The
+// parent directory is created if it doesn't exist, and an existing file is
+// appended to.
+func NewProcessor(path string, opts ...Option) (*Processor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file path must not be empty")
+	}
+	p := &Processor{
+		path:    path,
+		maxSize: DefaultMaxSize,
+		mode:    DefaultFileMode,
+		now:     time.Now,
+	}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply file processor options: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirFileMode); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := p.open(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Process appends the audit log request to the file as a JSON line, rotating
+// the file first if needed.
+func (p *Processor) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	b, err := logentry.MarshalLine(logReq)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return fmt.Errorf("file processor is stopped")
+	}
+	if p.shouldRotate(int64(len(b))) {
+		if err := p.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := p.f.Write(b)
+	p.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write log entry: %w", err)
+	}
+	return nil
+}
+
+// Stop syncs and closes the file, and waits for the rotated files to be
+// compressed. The processor can't be used afterwards.
+func (p *Processor) Stop() error {
+	p.mu.Lock()
+	var merr error
+	if !p.stopped {
+		p.stopped = true
+		merr = p.close()
+	}
+	p.mu.Unlock()
+
+	p.compressing.Wait()
+	p.compressMu.Lock()
+	defer p.compressMu.Unlock()
+	return errors.Join(merr, p.compressErr)
+}
+
+// open opens the file for appending, with the configured permissions even if
+// it already exists.
+func (p *Processor) open() error {
+	f, err := os.OpenFile(p.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, p.mode)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	// The mode of OpenFile is subject to the umask and ignored for existing
+	// files.
+	if err := f.Chmod(p.mode); err != nil {
+		return errors.Join(fmt.Errorf("failed to set log file permissions: %w", err), f.Close())
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to stat log file: %w", err), f.Close())
+	}
+	p.f, p.size, p.openedAt = f, info.Size(), p.now()
+	return nil
+}
+
+// close syncs and closes the file.
+func (p *Processor) close() error {
+	var merr error
+	if err := p.f.Sync(); err != nil {
+		merr = errors.Join(merr, fmt.Errorf("failed to sync log file: %w", err))
+	}
+	if err := p.f.Close(); err != nil {
+		merr = errors.Join(merr, fmt.Errorf("failed to close log file: %w", err))
+	}
+	return merr
+}
+
+// shouldRotate returns whether the file must be rotated before writing n
+// bytes. An empty file is never rotated.
+func (p *Processor) shouldRotate(n int64) bool {
+	if p.size == 0 {
+		return false
+	}
+	if p.maxSize > 0 && p.size+n > p.maxSize {
+		return true
+	}
+	return p.rotationInterval > 0 && p.now().Sub(p.openedAt) >= p.rotationInterval
+}
+
+// rotate renames the current file and opens a new one. If the file can't be
+// renamed, the current file is reopened so that the next write retries.
+func (p *Processor) rotate() error {
+	if err := p.close(); err != nil {
+		return errors.Join(fmt.Errorf("failed to rotate log file: %w", err), p.open())
+	}
+	rotated := p.rotatedPath()
+	if err := os.Rename(p.path, rotated); err != nil {
+		return errors.Join(fmt.Errorf("failed to rotate log file: %w", err), p.open())
+	}
+	if err := p.open(); err != nil {
+		return err
+	}
+
+	if p.compress {
+		p.compressing.Add(1)
+		go func() {
+			defer p.compressing.Done()
+			if err := compressFile(rotated, p.mode); err != nil {
+				p.compressMu.Lock()
+				defer p.compressMu.Unlock()
+				p.compressErr = errors.Join(p.compressErr, err)
+			}
+		}()
+	}
+	return nil
+}
+
+// rotatedPath returns the name of the file after rotation, which doesn't
+// exist yet, compressed or not.
+func (p *Processor) rotatedPath() string {
+	base := p.path + "." + p.now().UTC().Format(rotatedTimeFormat)
+	rotated := base
+	for i := 1; exists(rotated) || exists(rotated+compressedSuffix); i++ {
+		rotated = fmt.Sprintf("%s.%d", base, i)
+	}
+	return rotated
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// compressFile gzips the file into a file with the ".gz" suffix and removes
+// it. The file is kept if compression fails.
+func compressFile(path string, mode os.FileMode) (retErr error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open rotated log file: %w", err)
+	}
+	defer src.Close()
+
+	dstPath := path + compressedSuffix
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed log file: %w", err)
+	}
+	defer func() {
+		if retErr != nil {
+			retErr = errors.Join(retErr, os.Remove(dstPath))
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return errors.Join(fmt.Errorf("failed to compress rotated log file: %w", err), dst.Close())
+	}
+	if err := gz.Close(); err != nil {
+		return errors.Join(fmt.Errorf("failed to compress rotated log file: %w", err), dst.Close())
+	}
+	if err := dst.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync compressed log file: %w", err), dst.Close())
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to close compressed log file: %w", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove rotated log file: %w", err)
+	}
+	return nil
+}
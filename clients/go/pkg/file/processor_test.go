@@ -0,0 +1,344 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/abcxyz/lumberjack/clients/go/pkg/logentry"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/lumberjack/pkg/validation"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		path          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "default",
+			path: "audit.log",
+		},
+		{
+			name: "nested_directory",
+			path: filepath.Join("a", "b", "audit.log"),
+			opts: []Option{
+				WithMaxSize(1024),
+				WithRotationInterval(time.Hour),
+				WithCompression(),
+				WithFileMode(0o640),
+			},
+		},
+		{
+			name:          "empty_path",
+			wantErrSubstr: "file path must not be empty",
+		},
+		{
+			name:          "negative_max_size",
+			path:          "audit.log",
+			opts:          []Option{WithMaxSize(-1)},
+			wantErrSubstr: "max size must not be negative",
+		},
+		{
+			name:          "negative_rotation_interval",
+			path:          "audit.log",
+			opts:          []Option{WithRotationInterval(-time.Second)},
+			wantErrSubstr: "rotation interval must not be negative",
+		},
+		{
+			name:          "invalid_file_mode",
+			path:          "audit.log",
+			opts:          []Option{WithFileMode(os.ModeDir | 0o600)},
+			wantErrSubstr: "must only set permission bits",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := tc.path
+			if path != "" {
+				path = filepath.Join(t.TempDir(), path)
+			}
+			p, err := NewProcessor(path, tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if err := p.Stop(); err != nil {
+				t.Errorf("Stop() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	// Existing files are appended to with the configured permissions.
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewProcessor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Mode().Perm(), DefaultFileMode; got != want {
+		t.Errorf("file mode got %v, want %v", got, want)
+	}
+	if got, want := len(readLines(t, path)), 3; got != want {
+		t.Errorf("got %d lines, want %d", got, want)
+	}
+
+	if err := p.Process(t.Context(), testutil.NewRequest()); err == nil {
+		t.Errorf("Process() after Stop() got no error")
+	}
+}
+
+func TestProcessor_Rotation(t *testing.T) {
+	t.Parallel()
+
+	line, err := logentry.MarshalLine(testutil.NewRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lineSize := int64(len(line))
+
+	cases := []struct {
+		name string
+		opts []Option
+		// advance is how much the clock advances before each write.
+		advance time.Duration
+		writes  int
+		// wantFileLines is the number of lines of the current file, then of
+		// the rotated files in rotation order.
+		wantFileLines  []int
+		wantCompressed bool
+	}{
+		{
+			name:          "no_rotation",
+			writes:        3,
+			wantFileLines: []int{3},
+		},
+		{
+			name:          "size",
+			opts:          []Option{WithMaxSize(2 * lineSize)},
+			advance:       time.Second,
+			writes:        5,
+			wantFileLines: []int{1, 2, 2},
+		},
+		{
+			name:    "time",
+			opts:    []Option{WithRotationInterval(time.Minute)},
+			advance: 40 * time.Second,
+			writes:  4,
+			// The file opened at 0s is rotated at 80s, and the file opened
+			// at 80s is rotated at 160s.
+			wantFileLines: []int{1, 1, 2},
+		},
+		{
+			name:           "compressed",
+			opts:           []Option{WithMaxSize(lineSize), WithCompression()},
+			advance:        time.Second,
+			writes:         3,
+			wantFileLines:  []int{1, 1, 1},
+			wantCompressed: true,
+		},
+		{
+			name:          "same_rotation_time",
+			opts:          []Option{WithMaxSize(lineSize)},
+			writes:        3,
+			wantFileLines: []int{1, 1, 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "audit.log")
+
+			now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			p, err := NewProcessor(path, append(tc.opts, withNow(func() time.Time { return now }))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range tc.writes {
+				now = now.Add(tc.advance)
+				if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+					t.Fatalf("Process() unexpected error: %v", err)
+				}
+			}
+			if err := p.Stop(); err != nil {
+				t.Fatalf("Stop() unexpected error: %v", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// ReadDir sorts by name: the current file comes first, then the
+			// rotated files in rotation order.
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			if names[0] != "audit.log" {
+				t.Fatalf("got files %q, want audit.log first", names)
+			}
+
+			var gotLines []int
+			for _, name := range names {
+				if got := strings.HasSuffix(name, ".gz"); name != "audit.log" && got != tc.wantCompressed {
+					t.Errorf("file %q got compressed %t, want %t", name, got, tc.wantCompressed)
+				}
+				info, err := os.Stat(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, want := info.Mode().Perm(), DefaultFileMode; got != want {
+					t.Errorf("file %q mode got %v, want %v", name, got, want)
+				}
+				lines := readLines(t, filepath.Join(dir, name))
+				for _, l := range lines {
+					if err := validation.Validate(l); err != nil {
+						t.Errorf("file %q has invalid line %q: %v", name, l, err)
+					}
+				}
+				gotLines = append(gotLines, len(lines))
+			}
+			if diff := cmp.Diff(tc.wantFileLines, gotLines); diff != "" {
+				t.Errorf("lines per file got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const n = 100
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	p, err := NewProcessor(path, WithMaxSize(4096))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+				t.Errorf("Process() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+
+	files, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines int
+	for _, f := range files {
+		for _, l := range readLines(t, f) {
+			if err := validation.Validate(l); err != nil {
+				t.Errorf("file %q has invalid line %q: %v", f, l, err)
+			}
+			lines++
+		}
+	}
+	if lines != n {
+		t.Errorf("got %d lines, want %d", lines, n)
+	}
+}
+
+// withNow sets the clock of the processor.
+func withNow(now func() time.Time) Option {
+	return func(p *Processor) error {
+		p.now = now
+		return nil
+	}
+}
+
+// readLines returns the lines of the file, decompressing it if it's gzipped.
+func readLines(tb testing.TB, path string) []string {
+	tb.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		tb.Fatal(err)
+	}
+	return lines
+}
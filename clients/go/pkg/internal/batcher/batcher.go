@@ -0,0 +1,144 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package batcher coalesces the items added concurrently into batches, for
+// the backends that send log requests in batches.
+package batcher
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Batcher collects items into batches: a batch is flushed once it holds
+// maxSize items, or maxDelay after its first item.
+type Batcher[T comparable] struct {
+	maxSize  int
+	maxDelay time.Duration
+	flush    func(batch []T)
+
+	// mu guards the pending items and the stopped state.
+	mu      sync.Mutex
+	pending []T
+	// gen identifies the pending batch, so that a batch delay timer doesn't
+	// flush a later batch.
+	gen     uint64
+	timer   *time.Timer
+	stopped bool
+	flushes sync.WaitGroup
+}
+
+// New creates a new batcher calling flush with each batch. The batches are
+// flushed concurrently, so flush must be safe for concurrent use.
+func New[T comparable](maxSize int, maxDelay time.Duration, flush func(batch []T)) *Batcher[T] {
+	return &Batcher[T]{
+		maxSize:  maxSize,
+		maxDelay: maxDelay,
+		flush:    flush,
+	}
+}
+
+// Add adds the item to the pending batch, and flushes the batch once it's
+// full. It reports whether the item was added, which isn't the case once the
+// batcher is stopped.
+func (b *Batcher[T]) Add(item T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return false
+	}
+
+	b.pending = append(b.pending, item)
+	switch len(b.pending) {
+	case b.maxSize:
+		batch := b.takePendingLocked()
+		b.flushes.Add(1)
+		go b.doFlush(batch)
+	case 1:
+		gen := b.gen
+		b.timer = time.AfterFunc(b.maxDelay, func() {
+			b.mu.Lock()
+			if b.gen != gen {
+				// The batch was already flushed.
+				b.mu.Unlock()
+				return
+			}
+			batch := b.takePendingLocked()
+			b.flushes.Add(1)
+			b.mu.Unlock()
+			b.doFlush(batch)
+		})
+	}
+	return true
+}
+
+// Remove removes the item from the pending batch, and reports whether it was
+// still pending, in which case it's not flushed.
+func (b *Batcher[T]) Remove(item T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i := slices.Index(b.pending, item)
+	if i < 0 {
+		return false
+	}
+	b.pending = slices.Delete(b.pending, i, i+1)
+	if len(b.pending) == 0 {
+		// Start a new batch, and stop the batch delay timer.
+		b.takePendingLocked()
+	}
+	return true
+}
+
+// Len returns the number of pending items.
+func (b *Batcher[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Stop flushes the pending batch, and waits for the batches being flushed.
+// No items can be added afterwards.
+func (b *Batcher[T]) Stop() {
+	b.mu.Lock()
+	b.stopped = true
+	batch := b.takePendingLocked()
+	if len(batch) > 0 {
+		b.flushes.Add(1)
+	}
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.doFlush(batch)
+	}
+	b.flushes.Wait()
+}
+
+// takePendingLocked returns the pending batch and starts a new one. It must
+// be called with mu held.
+func (b *Batcher[T]) takePendingLocked() []T {
+	batch := b.pending
+	b.pending = nil
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
+
+func (b *Batcher[T]) doFlush(batch []T) {
+	defer b.flushes.Done()
+	b.flush(batch)
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batcher
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// recorder records the flushed batches, which are returned in the order of
+// their first item since they're flushed concurrently.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) flush(batch []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, slices.Clone(batch))
+}
+
+func (r *recorder) got() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	batches := slices.Clone(r.batches)
+	slices.SortFunc(batches, func(a, b []int) int { return a[0] - b[0] })
+	return batches
+}
+
+func TestBatcher(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		maxSize  int
+		maxDelay time.Duration
+		add      []int
+		remove   []int
+		want     [][]int
+	}{{
+		name:     "flush_full_batch",
+		maxSize:  2,
+		maxDelay: time.Hour,
+		add:      []int{1, 2, 3},
+		want:     [][]int{{1, 2}, {3}},
+	}, {
+		name:     "flush_after_delay",
+		maxSize:  10,
+		maxDelay: time.Millisecond,
+		add:      []int{1},
+		want:     [][]int{{1}},
+	}, {
+		name:     "remove_pending",
+		maxSize:  10,
+		maxDelay: time.Hour,
+		add:      []int{1, 2},
+		remove:   []int{1},
+		want:     [][]int{{2}},
+	}, {
+		name:     "remove_all_pending",
+		maxSize:  10,
+		maxDelay: time.Hour,
+		add:      []int{1},
+		remove:   []int{1},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &recorder{}
+			b := New(tc.maxSize, tc.maxDelay, r.flush)
+			for _, i := range tc.add {
+				if !b.Add(i) {
+					t.Fatalf("Add(%d) got false, want true", i)
+				}
+			}
+			for _, i := range tc.remove {
+				if !b.Remove(i) {
+					t.Errorf("Remove(%d) got false, want true", i)
+				}
+			}
+			if tc.maxDelay < time.Hour {
+				for len(r.got()) == 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			b.Stop()
+
+			if diff := cmp.Diff(tc.want, r.got()); diff != "" {
+				t.Errorf("flushed batches (-want,+got):\n%s", diff)
+			}
+			if b.Add(0) {
+				t.Errorf("Add() after Stop() got true, want false")
+			}
+		})
+	}
+}
+
+func TestBatcher_RemoveFlushed(t *testing.T) {
+	t.Parallel()
+
+	r := &recorder{}
+	b := New(1, time.Hour, r.flush)
+	b.Add(1)
+	b.Stop()
+
+	if b.Remove(1) {
+		t.Errorf("Remove() of a flushed item got true, want false")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logentry encodes audit log requests as JSON lines in the shape of a
+// Cloud Logging LogEntry, which the local backends write and which
+// `lumberctl validate` accepts.
+package logentry
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// FromRequest converts the audit log request to a LogEntry, with the audit
+// log as its jsonPayload. The timestamp defaults to the current time.
+func FromRequest(logReq *api.AuditLogRequest) (*loggingpb.LogEntry, error) {
+	entry := &loggingpb.LogEntry{
+		LogName:   api.LogName(logReq.GetType()),
+		Timestamp: logReq.GetTimestamp(),
+		Labels:    logReq.GetLabels(),
+		Operation: logReq.GetOperation(),
+	}
+	if entry.GetTimestamp() == nil {
+		entry.Timestamp = timestamppb.New(time.Now().UTC())
+	}
+	if logReq.GetPayload() != nil {
+		b, err := protojson.Marshal(logReq.GetPayload())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit log payload: %w", err)
+		}
+		payload := &structpb.Struct{}
+		if err := protojson.Unmarshal(b, payload); err != nil {
+			return nil, fmt.Errorf("failed to convert audit log payload: %w", err)
+		}
+		entry.Payload = &loggingpb.LogEntry_JsonPayload{JsonPayload: payload}
+	}
+	return entry, nil
+}
+
+// MarshalLine encodes the audit log request as a LogEntry, see FromRequest,
+// in a single line of JSON terminated by a newline.
+func MarshalLine(logReq *api.AuditLogRequest) ([]byte, error) {
+	entry, err := FromRequest(logReq)
+	if err != nil {
+		return nil, err
+	}
+	// Without the multiline option, protojson never emits a newline.
+	b, err := protojson.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logentry
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/lumberjack/pkg/validation"
+)
+
+func TestMarshalLine(t *testing.T) {
+	t.Parallel()
+
+	ts := timestamppb.New(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	cases := []struct {
+		name      string
+		logReq    *api.AuditLogRequest
+		want      *loggingpb.LogEntry
+		wantValid bool
+	}{
+		{
+			name: "full_request",
+			logReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest(
+					testutil.WithMethodName("/test.Service/Get"),
+					testutil.WithLabels(map[string]string{"env": "dev"}))
+				r.Timestamp = ts
+				r.Operation = &loggingpb.LogEntryOperation{Id: "op-1", Producer: "test", First: true}
+				return r
+			}(),
+			want: &loggingpb.LogEntry{
+				LogName:   "audit.abcxyz/data_access",
+				Timestamp: ts,
+				Labels:    map[string]string{"env": "dev"},
+				Operation: &loggingpb.LogEntryOperation{Id: "op-1", Producer: "test", First: true},
+			},
+			wantValid: true,
+		},
+		{
+			name: "admin_activity_log_name",
+			logReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest()
+				r.Type = api.AuditLogRequest_ADMIN_ACTIVITY
+				r.Timestamp = ts
+				return r
+			}(),
+			want: &loggingpb.LogEntry{
+				LogName:   "audit.abcxyz/activity",
+				Timestamp: ts,
+			},
+			wantValid: true,
+		},
+		{
+			name:   "no_payload",
+			logReq: &api.AuditLogRequest{Type: api.AuditLogRequest_DATA_ACCESS, Timestamp: ts},
+			want: &loggingpb.LogEntry{
+				LogName:   "audit.abcxyz/data_access",
+				Timestamp: ts,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			line, err := MarshalLine(tc.logReq)
+			if err != nil {
+				t.Fatalf("MarshalLine() unexpected error: %v", err)
+			}
+			if got := bytes.Count(line, []byte("\n")); got != 1 || line[len(line)-1] != '\n' {
+				t.Fatalf("MarshalLine() got %q, want a single line", line)
+			}
+
+			var got loggingpb.LogEntry
+			if err := protojson.Unmarshal(line, &got); err != nil {
+				t.Fatalf("failed to unmarshal line %q: %v", line, err)
+			}
+
+			// Compare the payload as an AuditLog, as its JSON field names
+			// are an implementation detail.
+			var gotPayload *audit.AuditLog
+			if jp := got.GetJsonPayload(); jp != nil {
+				b, err := protojson.Marshal(jp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotPayload = &audit.AuditLog{}
+				if err := protojson.Unmarshal(b, gotPayload); err != nil {
+					t.Fatalf("failed to unmarshal payload %q: %v", b, err)
+				}
+				got.Payload = nil
+			}
+			if diff := cmp.Diff(tc.logReq.GetPayload(), gotPayload, protocmp.Transform()); diff != "" {
+				t.Errorf("MarshalLine() unexpected payload (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, &got, protocmp.Transform()); diff != "" {
+				t.Errorf("MarshalLine() unexpected log entry (-want, +got):\n%s", diff)
+			}
+
+			if err := validation.Validate(string(line)); (err == nil) != tc.wantValid {
+				t.Errorf("validation.Validate() got error %v, want valid %t", err, tc.wantValid)
+			}
+		})
+	}
+}
+
+func TestFromRequest_DefaultTimestamp(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	entry, err := FromRequest(testutil.NewRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entry.GetTimestamp().AsTime(); got.Before(before) || got.After(time.Now()) {
+		t.Errorf("FromRequest() got timestamp %v, want the current time", got)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics manages open telemetry metric exporter.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mexporter "github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/metric"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// Init initializes and sets the global meter provider, which exports the
+// metrics to Cloud Monitoring at the given interval.
+func Init(interval time.Duration) error {
+	exporter, err := mexporter.New()
+	if err != nil {
+		return fmt.Errorf("failed to create metric exporter: %w", err)
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+	)
+	otel.SetMeterProvider(mp)
+	return nil
+}
+
+// Shutdown flushes the remaining metrics and shuts down the global meter
+// provider.
+func Shutdown() error {
+	mpRaw := otel.GetMeterProvider()
+	mp, ok := mpRaw.(*sdkmetric.MeterProvider)
+	if !ok {
+		// Not the meter provider we expect, ignore.
+		return nil
+	}
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+
+	if err := mp.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown meter provider: %w", err)
+	}
+	return nil
+}
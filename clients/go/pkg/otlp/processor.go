@@ -0,0 +1,332 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp provides a backend that exports audit logs as OpenTelemetry
+// log records over OTLP, e.g. to an OpenTelemetry Collector.
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+const (
+	// ScopeName is the instrumentation scope of the exported log records.
+	ScopeName = "github.com/abcxyz/lumberjack/clients/go/pkg/otlp"
+
+	// EventNameAttribute is the attribute holding the event name of a log
+	// record: the log name of the audit log type, e.g.
+	// "audit.abcxyz/data_access".
+	EventNameAttribute = "event.name"
+
+	// The prefixes of the attributes holding the fields of the audit log
+	// payload, the labels and the operation, e.g. "audit.payload.method_name",
+	// "audit.labels.env" and "audit.operation.id".
+	payloadAttributePrefix   = "audit.payload."
+	labelsAttributePrefix    = "audit.labels."
+	operationAttributePrefix = "audit.operation."
+)
+
+// Protocol is the OTLP transport protocol.
+type Protocol int
+
+const (
+	// ProtocolGRPC is OTLP over gRPC. This is the default.
+	ProtocolGRPC Protocol = iota
+
+	// ProtocolHTTP is OTLP over HTTP with protobuf payloads.
+	ProtocolHTTP
+)
+
+// Processor is a backend that converts each audit log request to an
+// OpenTelemetry log record and exports it synchronously over OTLP, so that
+// export failures are returned. The audit log payload fields, labels and
+// operation become attributes, the log type becomes the event name, and the
+// body is the method name.
+type Processor struct {
+	protocol    Protocol
+	endpointURL string
+	headers     map[string]string
+	timeout     time.Duration
+	exporter    sdklog.Exporter
+	resource    *resource.Resource
+
+	provider *sdklog.LoggerProvider
+	logger   log.Logger
+}
+
+// Option is a configuration option for NewProcessor.
+type Option func(p *Processor) error
+
+// WithEndpointURL sets the protocol and the URL of the OTLP endpoint, e.g.
+// "http://localhost:4317" for gRPC or "https://collector:4318/v1/logs" for
+// HTTP. The connection is insecure if the scheme is "http". By default, the
+// gRPC exporter is configured by the standard OTEL_EXPORTER_OTLP_*
+// environment variables.
+func WithEndpointURL(protocol Protocol, endpointURL string) Option {
+	return func(p *Processor) error {
+		u, err := url.Parse(endpointURL)
+		if err != nil {
+			return fmt.Errorf("failed to parse OTLP endpoint URL: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("OTLP endpoint URL %q must be an absolute http or https URL", endpointURL)
+		}
+		p.protocol, p.endpointURL = protocol, endpointURL
+		return nil
+	}
+}
+
+// WithHeaders adds the given headers, or gRPC metadata, to the export
+// requests.
+func WithHeaders(headers map[string]string) Option {
+	return func(p *Processor) error {
+		p.headers = headers
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each export request.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Processor) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+		p.timeout = d
+		return nil
+	}
+}
+
+// WithExporter sets the exporter of the log records, instead of an OTLP
+// exporter.
+func WithExporter(e sdklog.Exporter) Option {
+	return func(p *Processor) error {
+		if e == nil {
+			return fmt.Errorf("exporter must not be nil")
+		}
+		p.exporter = e
+		return nil
+	}
+}
+
+// WithResource sets the resource of the exported log records. The default is
+// resource.Default.
+func WithResource(r *resource.Resource) Option {
+	return func(p *Processor) error {
+		p.resource = r
+		return nil
+	}
+}
+
+// NewProcessor creates a new OTLP processor with the given options.
+func NewProcessor(ctx context.Context, opts ...Option) (*Processor, error) {
+	p := &Processor{resource: resource.Default()}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply OTLP processor options: %w", err)
+		}
+	}
+
+	if p.exporter == nil {
+		e, err := p.newExporter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.exporter = e
+	}
+
+	p.provider = sdklog.NewLoggerProvider(
+		sdklog.WithResource(p.resource),
+		sdklog.WithProcessor(&exportProcessor{exporter: p.exporter}))
+	p.logger = p.provider.Logger(ScopeName)
+	return p, nil
+}
+
+// newExporter creates the OTLP exporter. Its retries are disabled, as
+// retrying is up to the client, see the resilience package.
+func (p *Processor) newExporter(ctx context.Context) (sdklog.Exporter, error) {
+	switch p.protocol {
+	case ProtocolHTTP:
+		opts := []otlploghttp.Option{otlploghttp.WithRetry(otlploghttp.RetryConfig{Enabled: false})}
+		if p.endpointURL != "" {
+			opts = append(opts, otlploghttp.WithEndpointURL(p.endpointURL))
+		}
+		if p.headers != nil {
+			opts = append(opts, otlploghttp.WithHeaders(p.headers))
+		}
+		if p.timeout > 0 {
+			opts = append(opts, otlploghttp.WithTimeout(p.timeout))
+		}
+		e, err := otlploghttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP HTTP exporter: %w", err)
+		}
+		return e, nil
+	default:
+		opts := []otlploggrpc.Option{otlploggrpc.WithRetry(otlploggrpc.RetryConfig{Enabled: false})}
+		if p.endpointURL != "" {
+			opts = append(opts, otlploggrpc.WithEndpointURL(p.endpointURL))
+		}
+		if p.headers != nil {
+			opts = append(opts, otlploggrpc.WithHeaders(p.headers))
+		}
+		if p.timeout > 0 {
+			opts = append(opts, otlploggrpc.WithTimeout(p.timeout))
+		}
+		e, err := otlploggrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC exporter: %w", err)
+		}
+		return e, nil
+	}
+}
+
+// Process exports the audit log request as a log record.
+func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	r, err := toRecord(logReq)
+	if err != nil {
+		return err
+	}
+
+	var exportErr error
+	p.logger.Emit(context.WithValue(ctx, exportErrKey{}, &exportErr), r)
+	if exportErr != nil {
+		return fmt.Errorf("failed to export audit log over OTLP: %w", exportErr)
+	}
+	return nil
+}
+
+// Stop shuts down the exporter.
+func (p *Processor) Stop() error {
+	if err := p.provider.Shutdown(context.Background()); err != nil {
+		return fmt.Errorf("failed to shut down OTLP logger provider: %w", err)
+	}
+	return nil
+}
+
+// toRecord converts the audit log request to a log record.
+func toRecord(logReq *api.AuditLogRequest) (log.Record, error) {
+	var r log.Record
+	ts := time.Now()
+	if logReq.GetTimestamp() != nil {
+		ts = logReq.GetTimestamp().AsTime()
+	}
+	r.SetTimestamp(ts)
+	r.SetSeverity(log.SeverityInfo)
+	// The exporter doesn't support an empty body.
+	r.SetBody(log.StringValue(logReq.GetPayload().GetMethodName()))
+
+	eventName := api.LogName(logReq.GetType())
+	if eventName == "" {
+		eventName = logReq.GetType().String()
+	}
+	r.AddAttributes(log.String(EventNameAttribute, eventName))
+
+	if logReq.GetPayload() != nil {
+		b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(logReq.GetPayload())
+		if err != nil {
+			return r, fmt.Errorf("failed to marshal audit log payload: %w", err)
+		}
+		payload := &structpb.Struct{}
+		if err := protojson.Unmarshal(b, payload); err != nil {
+			return r, fmt.Errorf("failed to convert audit log payload: %w", err)
+		}
+		for k, v := range payload.GetFields() {
+			r.AddAttributes(log.KeyValue{Key: payloadAttributePrefix + k, Value: toValue(v)})
+		}
+	}
+
+	for k, v := range logReq.GetLabels() {
+		r.AddAttributes(log.String(labelsAttributePrefix+k, v))
+	}
+
+	if op := logReq.GetOperation(); op != nil {
+		r.AddAttributes(
+			log.String(operationAttributePrefix+"id", op.GetId()),
+			log.String(operationAttributePrefix+"producer", op.GetProducer()),
+			log.Bool(operationAttributePrefix+"first", op.GetFirst()),
+			log.Bool(operationAttributePrefix+"last", op.GetLast()))
+	}
+	return r, nil
+}
+
+// toValue converts a JSON value to a log attribute value.
+func toValue(v *structpb.Value) log.Value {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		return log.StringValue(k.StringValue)
+	case *structpb.Value_NumberValue:
+		return log.Float64Value(k.NumberValue)
+	case *structpb.Value_BoolValue:
+		return log.BoolValue(k.BoolValue)
+	case *structpb.Value_StructValue:
+		kvs := make([]log.KeyValue, 0, len(k.StructValue.GetFields()))
+		for name, f := range k.StructValue.GetFields() {
+			kvs = append(kvs, log.KeyValue{Key: name, Value: toValue(f)})
+		}
+		return log.MapValue(kvs...)
+	case *structpb.Value_ListValue:
+		vs := make([]log.Value, 0, len(k.ListValue.GetValues()))
+		for _, e := range k.ListValue.GetValues() {
+			vs = append(vs, toValue(e))
+		}
+		return log.SliceValue(vs...)
+	default:
+		return log.Value{}
+	}
+}
+
+// exportErrKey is the context key of the export error of a log record, so
+// that Process returns it, as the logger doesn't.
+type exportErrKey struct{}
+
+// exportProcessor exports each log record synchronously, and stores the
+// export error in the context of the record.
+type exportProcessor struct {
+	exporter sdklog.Exporter
+}
+
+// OnEmit exports the log record.
+func (e *exportProcessor) OnEmit(ctx context.Context, r *sdklog.Record) error {
+	err := e.exporter.Export(ctx, []sdklog.Record{*r})
+	if errp, ok := ctx.Value(exportErrKey{}).(*error); ok {
+		*errp = err
+		// Returned to Process, so don't report it to the global OpenTelemetry
+		// error handler too.
+		return nil
+	}
+	return err //nolint:wrapcheck // Reported as is.
+}
+
+// Shutdown shuts down the exporter.
+func (e *exportProcessor) Shutdown(ctx context.Context) error {
+	return e.exporter.Shutdown(ctx) //nolint:wrapcheck // Wrapped by Stop.
+}
+
+// ForceFlush flushes the exporter.
+func (e *exportProcessor) ForceFlush(ctx context.Context) error {
+	return e.exporter.ForceFlush(ctx) //nolint:wrapcheck // Nothing to add.
+}
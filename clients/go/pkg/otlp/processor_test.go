@@ -0,0 +1,318 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/google/go-cmp/cmp"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// fakeCollector is a local stand-in for an OpenTelemetry Collector. It records
+// the exported log records, and fails the exports with err.
+type fakeCollector struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	err error
+
+	mu      sync.Mutex
+	records []*logspb.LogRecord
+	scopes  []string
+}
+
+func (c *fakeCollector) Export(_ context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			for _, r := range sl.GetLogRecords() {
+				c.records = append(c.records, r)
+				c.scopes = append(c.scopes, sl.GetScope().GetName())
+			}
+		}
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// ServeHTTP serves the OTLP/HTTP protocol with protobuf payloads.
+func (c *fakeCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := c.Export(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(b)
+}
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "default_grpc",
+		},
+		{
+			name: "http",
+			opts: []Option{
+				WithEndpointURL(ProtocolHTTP, "https://collector.example.com:4318/v1/logs"),
+				WithHeaders(map[string]string{"authorization": "Bearer token"}),
+				WithTimeout(time.Second),
+			},
+		},
+		{
+			name:          "relative_endpoint_url",
+			opts:          []Option{WithEndpointURL(ProtocolGRPC, "localhost:4317")},
+			wantErrSubstr: "must be an absolute http or https URL",
+		},
+		{
+			name:          "invalid_timeout",
+			opts:          []Option{WithTimeout(-time.Second)},
+			wantErrSubstr: "timeout must be positive",
+		},
+		{
+			name:          "nil_exporter",
+			opts:          []Option{WithExporter(nil)},
+			wantErrSubstr: "exporter must not be nil",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewProcessor(t.Context(), tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if err := p.Stop(); err != nil {
+				t.Errorf("Stop() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	logReq := testutil.NewRequest(
+		testutil.WithMethodName("/test.Service/Get"),
+		testutil.WithLabels(map[string]string{"env": "dev"}))
+	logReq.Timestamp = timestamppb.New(ts)
+	logReq.Operation = &loggingpb.LogEntryOperation{Id: "op-1", Producer: "test", First: true}
+
+	wantRecord := &logspb.LogRecord{
+		TimeUnixNano:   uint64(ts.UnixNano()),
+		SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+		Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "/test.Service/Get"}},
+		Attributes: []*commonpb.KeyValue{
+			stringAttr("event.name", "audit.abcxyz/data_access"),
+			stringAttr("audit.payload.method_name", "/test.Service/Get"),
+			stringAttr("audit.payload.service_name", "test-service"),
+			stringAttr("audit.payload.resource_name", "test-resource"),
+			{
+				Key: "audit.payload.authentication_info",
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+					Values: []*commonpb.KeyValue{stringAttr("principal_email", "user@example.com")},
+				}}},
+			},
+			stringAttr("audit.labels.env", "dev"),
+			stringAttr("audit.operation.id", "op-1"),
+			stringAttr("audit.operation.producer", "test"),
+			boolAttr("audit.operation.first", true),
+			boolAttr("audit.operation.last", false),
+		},
+	}
+
+	cases := []struct {
+		name     string
+		protocol Protocol
+	}{
+		{
+			name:     "grpc",
+			protocol: ProtocolGRPC,
+		},
+		{
+			name:     "http",
+			protocol: ProtocolHTTP,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &fakeCollector{}
+			p := newTestProcessor(t, c, tc.protocol)
+
+			if err := p.Process(t.Context(), logReq); err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+
+			if got, want := len(c.records), 1; got != want {
+				t.Fatalf("collector got %d records, want %d", got, want)
+			}
+			if got, want := c.scopes[0], ScopeName; got != want {
+				t.Errorf("collector got scope %q, want %q", got, want)
+			}
+			opts := []cmp.Option{
+				protocmp.Transform(),
+				protocmp.IgnoreFields(&logspb.LogRecord{}, "observed_time_unix_nano"),
+				protocmp.SortRepeated(func(a, b *commonpb.KeyValue) bool { return a.GetKey() < b.GetKey() }),
+			}
+			if diff := cmp.Diff(wantRecord, c.records[0], opts...); diff != "" {
+				t.Errorf("collector got unexpected record (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessError(t *testing.T) {
+	t.Parallel()
+
+	c := &fakeCollector{err: status.Error(codes.Unavailable, "collector unavailable")}
+	p := newTestProcessor(t, c, ProtocolGRPC)
+
+	err := p.Process(t.Context(), testutil.NewRequest())
+	if diff := pkgtestutil.DiffErrString(err, "collector unavailable"); diff != "" {
+		t.Errorf("Process() got unexpected error: %s", diff)
+	}
+	if got, want := status.Code(err), codes.Unavailable; got != want {
+		t.Errorf("Process() got code %v, want %v", got, want)
+	}
+}
+
+func TestProcessor_LogTypes(t *testing.T) {
+	t.Parallel()
+
+	c := &fakeCollector{}
+	p := newTestProcessor(t, c, ProtocolGRPC)
+
+	logTypes := []api.AuditLogRequest_LogType{
+		api.AuditLogRequest_ADMIN_ACTIVITY,
+		api.AuditLogRequest_DATA_ACCESS,
+		api.AuditLogRequest_CONSENT_EVENT,
+		api.AuditLogRequest_SYSTEM_EVENT,
+	}
+	for _, lt := range logTypes {
+		logReq := testutil.NewRequest()
+		logReq.Type = lt
+		if err := p.Process(t.Context(), logReq); err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+	}
+
+	var got []string
+	for _, r := range c.records {
+		for _, kv := range r.GetAttributes() {
+			if kv.GetKey() == EventNameAttribute {
+				got = append(got, kv.GetValue().GetStringValue())
+			}
+		}
+	}
+	want := []string{
+		"audit.abcxyz/activity",
+		"audit.abcxyz/data_access",
+		"audit.abcxyz/consent",
+		"audit.abcxyz/system_event",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("event names got diff (-want, +got): %v", diff)
+	}
+}
+
+// newTestProcessor starts the collector over the given protocol and returns a
+// processor exporting to it.
+func newTestProcessor(tb testing.TB, c *fakeCollector, protocol Protocol) *Processor {
+	tb.Helper()
+
+	var endpointURL string
+	switch protocol {
+	case ProtocolHTTP:
+		srv := httptest.NewServer(c)
+		tb.Cleanup(srv.Close)
+		endpointURL = srv.URL + "/v1/logs"
+	default:
+		addr, _ := testutil.TestFakeGRPCServer(tb, func(s *grpc.Server) {
+			collogspb.RegisterLogsServiceServer(s, c)
+		})
+		endpointURL = "http://" + addr
+	}
+
+	p, err := NewProcessor(context.Background(), WithEndpointURL(protocol, endpointURL))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := p.Stop(); err != nil {
+			tb.Errorf("Stop() unexpected error: %v", err)
+		}
+	})
+	return p
+}
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func boolAttr(key string, value bool) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: value}}}
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pubsub provides a backend that publishes audit logs to a Cloud
+// Pub/Sub topic, e.g. to fan them out to downstream consumers.
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	pubsubsdk "cloud.google.com/go/pubsub"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/pkg/gcputil"
+	"github.com/abcxyz/pkg/logging"
+)
+
+// The attributes of the published messages, so that subscriptions can filter
+// on them.
+const (
+	// AttributeLogType is the attribute holding the log type, e.g.
+	// "DATA_ACCESS".
+	AttributeLogType = "log_type"
+
+	// AttributeServiceName is the attribute holding the service name of the
+	// audit log.
+	AttributeServiceName = "service_name"
+
+	// AttributeMethodName is the attribute holding the method name of the
+	// audit log.
+	AttributeMethodName = "method_name"
+)
+
+// Processor is a backend that publishes each audit log request to a Pub/Sub
+// topic. The message data is the audit log request as JSON.
+type Processor struct {
+	client *pubsubsdk.Client
+	// ownClient is whether the processor created the client, and must close
+	// it.
+	ownClient bool
+	topic     *pubsubsdk.Topic
+	// ordering sets the operation ID as the ordering key of the messages.
+	ordering bool
+	// bestEffort defaults to false and sets the default logging mode to
+	// fail-close.
+	bestEffort bool
+
+	// pending tracks the best effort publish results not yet received.
+	pending sync.WaitGroup
+}
+
+// Option is the option to set up a Pub/Sub processor.
+type Option func(p *Processor) error
+
+// WithPubSubClient provides a Pub/Sub client to the processor. The client is
+// not closed by Stop.
+func WithPubSubClient(client *pubsubsdk.Client) Option {
+	return func(p *Processor) error {
+		p.client = client
+		return nil
+	}
+}
+
+// WithOrdering publishes the messages with the operation ID of the audit log
+// request as the ordering key, so that the messages of an operation, e.g. of
+// a stream, are delivered in order to subscriptions with message ordering
+// enabled. Audit log requests without an operation are published unordered.
+func WithOrdering() Option {
+	return func(p *Processor) error {
+		p.ordering = true
+		return nil
+	}
+}
+
+// WithDefaultBestEffort sets the default logging mode of operation to best
+// effort. There are two possible logging modes of operation:
+//   - fail-close. Without this option, this is default. Process waits for
+//     the message to be published, and returns an error on failure.
+//   - best effort. Process returns once the message is queued for
+//     publishing, and failures are only logged.
+//
+// Individual log requests override the default with their mode.
+func WithDefaultBestEffort() Option {
+	return func(p *Processor) error {
+		p.bestEffort = true
+		return nil
+	}
+}
+
+// NewProcessor creates a new Pub/Sub processor publishing to the given topic,
+// either a topic ID in the project of the client or a full
+// "projects/<project>/topics/<topic>" name.
+func NewProcessor(ctx context.Context, topic string, opts ...Option) (*Processor, error) {
+	p := &Processor{}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply client options: %w", err)
+		}
+	}
+
+	projectID, topicID, err := parseTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the options didn't provide a Pub/Sub client, create one.
+	if p.client == nil {
+		if projectID == "" {
+			projectID = gcputil.ProjectID(ctx)
+		}
+		client, err := pubsubsdk.NewClient(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the default Pub/Sub client for project %v: %w", projectID, err)
+		}
+		p.client = client
+		p.ownClient = true
+	}
+
+	if projectID == "" {
+		p.topic = p.client.Topic(topicID)
+	} else {
+		p.topic = p.client.TopicInProject(topicID, projectID)
+	}
+	p.topic.EnableMessageOrdering = p.ordering
+	return p, nil
+}
+
+// parseTopic returns the project, if any, and the ID of the topic.
+func parseTopic(topic string) (string, string, error) {
+	if !strings.HasPrefix(topic, "projects/") {
+		if topic == "" || strings.Contains(topic, "/") {
+			return "", "", fmt.Errorf("invalid Pub/Sub topic %q", topic)
+		}
+		return "", topic, nil
+	}
+	parts := strings.Split(topic, "/")
+	if len(parts) != 4 || parts[1] == "" || parts[2] != "topics" || parts[3] == "" {
+		return "", "", fmt.Errorf("invalid Pub/Sub topic %q, want \"projects/<project>/topics/<topic>\"", topic)
+	}
+	return parts[1], parts[3], nil
+}
+
+// Process publishes the audit log request. Unless the log request is best
+// effort, it waits for the message to be published.
+func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	data, err := protojson.Marshal(logReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log request: %w", err)
+	}
+	msg := &pubsubsdk.Message{
+		Data: data,
+		Attributes: map[string]string{
+			AttributeLogType:     logReq.GetType().String(),
+			AttributeServiceName: logReq.GetPayload().GetServiceName(),
+			AttributeMethodName:  logReq.GetPayload().GetMethodName(),
+		},
+	}
+	if p.ordering {
+		msg.OrderingKey = logReq.GetOperation().GetId()
+	}
+
+	bestEffort := p.bestEffort
+	if logReq.GetMode() != api.AuditLogRequest_LOG_MODE_UNSPECIFIED {
+		bestEffort = (logReq.GetMode() == api.AuditLogRequest_BEST_EFFORT)
+	}
+
+	res := p.topic.Publish(ctx, msg)
+	if bestEffort {
+		p.pending.Add(1)
+		go func() {
+			defer p.pending.Done()
+			// The publish outlives the caller, so detach from its
+			// cancellation.
+			ctx := context.WithoutCancel(ctx)
+			if err := p.wait(ctx, res, msg.OrderingKey); err != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "failed to publish best effort audit log",
+					"error", err)
+			}
+		}()
+		return nil
+	}
+	return p.wait(ctx, res, msg.OrderingKey)
+}
+
+// wait waits for the publish result. After a failure, publishing is resumed
+// for the ordering key, which the client pauses to preserve the order.
+func (p *Processor) wait(ctx context.Context, res *pubsubsdk.PublishResult, orderingKey string) error {
+	if _, err := res.Get(ctx); err != nil {
+		if orderingKey != "" {
+			p.topic.ResumePublish(orderingKey)
+		}
+		return fmt.Errorf("failed to publish to Pub/Sub: %w", err)
+	}
+	return nil
+}
+
+// Stop publishes the queued messages, and closes the client if the processor
+// created it.
+func (p *Processor) Stop() error {
+	p.topic.Stop()
+	p.pending.Wait()
+	if p.ownClient {
+		if err := p.client.Close(); err != nil {
+			return fmt.Errorf("failed to close Pub/Sub client: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	pubsubsdk "cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+const (
+	testProject = "test-project"
+	testTopic   = "audit-logs"
+)
+
+// newTestClient starts a fake Pub/Sub server with the test topic and returns
+// a client connected to it.
+func newTestClient(tb testing.TB, opts ...pstest.ServerReactorOption) (*pstest.Server, *pubsubsdk.Client) {
+	tb.Helper()
+
+	srv := pstest.NewServer(opts...)
+	tb.Cleanup(func() { srv.Close() })
+
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+
+	client, err := pubsubsdk.NewClient(context.Background(), testProject, option.WithGRPCConn(conn))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { client.Close() })
+
+	if _, err := client.CreateTopic(context.Background(), testTopic); err != nil {
+		tb.Fatal(err)
+	}
+	return srv, client
+}
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		topic         string
+		wantTopic     string
+		wantErrSubstr string
+	}{
+		{
+			name:      "topic_id",
+			topic:     "audit-logs",
+			wantTopic: "projects/test-project/topics/audit-logs",
+		},
+		{
+			name:      "full_name",
+			topic:     "projects/other-project/topics/audit-logs",
+			wantTopic: "projects/other-project/topics/audit-logs",
+		},
+		{
+			name:          "empty",
+			topic:         "",
+			wantErrSubstr: `invalid Pub/Sub topic ""`,
+		},
+		{
+			name:          "invalid_full_name",
+			topic:         "projects/other-project/subscriptions/audit-logs",
+			wantErrSubstr: "want \"projects/<project>/topics/<topic>\"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, client := newTestClient(t)
+			p, err := NewProcessor(t.Context(), tc.topic, WithPubSubClient(client))
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				return
+			}
+			if got := p.topic.String(); got != tc.wantTopic {
+				t.Errorf("NewProcessor(%q) got topic %q, want %q", tc.topic, got, tc.wantTopic)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	withOperation := func(id string) *api.AuditLogRequest {
+		r := testutil.NewRequest()
+		r.Operation = &loggingpb.LogEntryOperation{Id: id}
+		return r
+	}
+	withMode := func(r *api.AuditLogRequest, mode api.AuditLogRequest_LogMode) *api.AuditLogRequest {
+		r.Mode = mode
+		return r
+	}
+
+	cases := []struct {
+		name             string
+		opts             []Option
+		logReqs          []*api.AuditLogRequest
+		wantOrderingKeys []string
+	}{
+		{
+			name:             "fail_close",
+			logReqs:          []*api.AuditLogRequest{testutil.NewRequest(), testutil.NewRequest(testutil.WithMethodName("m2"))},
+			wantOrderingKeys: []string{"", ""},
+		},
+		{
+			name:             "ordering_on_operation_id",
+			opts:             []Option{WithOrdering()},
+			logReqs:          []*api.AuditLogRequest{withOperation("op1"), testutil.NewRequest(), withOperation("op1")},
+			wantOrderingKeys: []string{"op1", "", "op1"},
+		},
+		{
+			name:             "no_ordering_by_default",
+			logReqs:          []*api.AuditLogRequest{withOperation("op1")},
+			wantOrderingKeys: []string{""},
+		},
+		{
+			name:             "best_effort_default",
+			opts:             []Option{WithDefaultBestEffort()},
+			logReqs:          []*api.AuditLogRequest{testutil.NewRequest()},
+			wantOrderingKeys: []string{""},
+		},
+		{
+			name:             "best_effort_request",
+			logReqs:          []*api.AuditLogRequest{withMode(testutil.NewRequest(), api.AuditLogRequest_BEST_EFFORT)},
+			wantOrderingKeys: []string{""},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv, client := newTestClient(t)
+			p, err := NewProcessor(ctx, testTopic, append(tc.opts, WithPubSubClient(client))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, logReq := range tc.logReqs {
+				if err := p.Process(ctx, logReq); err != nil {
+					t.Fatalf("Process() unexpected error: %v", err)
+				}
+			}
+			// Stop publishes the best effort messages.
+			if err := p.Stop(); err != nil {
+				t.Fatalf("Stop() unexpected error: %v", err)
+			}
+
+			msgs := srv.Messages()
+			if got, want := len(msgs), len(tc.logReqs); got != want {
+				t.Fatalf("got %d messages, want %d", got, want)
+			}
+			var gotOrderingKeys []string
+			for i, m := range msgs {
+				gotOrderingKeys = append(gotOrderingKeys, m.OrderingKey)
+
+				var got api.AuditLogRequest
+				if err := protojson.Unmarshal(m.Data, &got); err != nil {
+					t.Fatalf("message %d: invalid data: %v", i, err)
+				}
+				if diff := cmp.Diff(tc.logReqs[i], &got, protocmp.Transform()); diff != "" {
+					t.Errorf("message %d data got diff (-want, +got): %v", i, diff)
+				}
+				wantAttrs := map[string]string{
+					AttributeLogType:     "DATA_ACCESS",
+					AttributeServiceName: "test-service",
+					AttributeMethodName:  tc.logReqs[i].GetPayload().GetMethodName(),
+				}
+				if diff := cmp.Diff(wantAttrs, m.Attributes); diff != "" {
+					t.Errorf("message %d attributes got diff (-want, +got): %v", i, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.wantOrderingKeys, gotOrderingKeys); diff != "" {
+				t.Errorf("ordering keys got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_PublishFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name:          "fail_close",
+			wantErrSubstr: "failed to publish to Pub/Sub",
+		},
+		{
+			name: "best_effort",
+			opts: []Option{WithDefaultBestEffort()},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv, client := newTestClient(t)
+			srv.SetAutoPublishResponse(false)
+			srv.AddPublishResponse(nil, status.Error(codes.PermissionDenied, "fake error"))
+
+			p, err := NewProcessor(ctx, testTopic, append(tc.opts, WithPubSubClient(client), WithOrdering())...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if err := p.Stop(); err != nil {
+					t.Errorf("Stop() unexpected error: %v", err)
+				}
+			})
+
+			logReq := testutil.NewRequest()
+			logReq.Operation = &loggingpb.LogEntryOperation{Id: "op1"}
+			err = p.Process(ctx, logReq)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				if got, want := status.Code(err), codes.PermissionDenied; got != want {
+					t.Errorf("Process() got code %v, want %v", got, want)
+				}
+
+				// Publishing resumes for the ordering key after a failure.
+				srv.SetAutoPublishResponse(true)
+				if err := p.Process(ctx, logReq); err != nil {
+					t.Errorf("Process() after failure unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -29,39 +29,53 @@ import (
 	"google.golang.org/grpc/credentials/oauth"
 )
 
-// WithIDTokenAuth sets up the processor to do auth with ID token.
+// WithIDTokenAuth sets up the processor to do auth with ID token. Each target
+// gets ID tokens for its own audience, see NewProcessor.
 // TODO(b/201541513): It's not clear how to unit test this functionality.
 // We can at least cover it in the integration test.
 func WithIDTokenAuth(ctx context.Context) Option {
 	return func(p *Processor) error {
-		ts, err := idtoken.NewTokenSource(ctx, "https://"+strings.TrimSuffix(p.address, ":443"))
-		if err != nil {
-			return fmt.Errorf("failed idtoken.NewTokenSource: %w", err)
+		p.newAuth = func(audience string) (grpcAuthOptions, error) {
+			ts, err := idtoken.NewTokenSource(ctx, audience)
+			if err != nil {
+				return nil, fmt.Errorf("failed idtoken.NewTokenSource: %w", err)
+			}
+			return newIDTokenAuth(ts)
 		}
-		return setIDTokenAuth(p, ts)
+		return nil
 	}
 }
 
 // WithImpersonatedIDTokenAuth sets up the processor to do auth with impersonated
-// service account ID token.
+// service account ID token. Each target gets ID tokens for its own audience,
+// see NewProcessor.
 func WithImpersonatedIDTokenAuth(ctx context.Context, targetPrincipal string) Option {
 	return func(p *Processor) error {
-		ts, err := impersonate.IDTokenSource(ctx, impersonate.IDTokenConfig{
-			TargetPrincipal: targetPrincipal,
-			Audience:        "https://" + strings.TrimSuffix(p.address, ":443"),
-			IncludeEmail:    true,
-		})
-		if err != nil {
-			return fmt.Errorf("failed impersonate.IDTokenSource: %w", err)
+		p.newAuth = func(audience string) (grpcAuthOptions, error) {
+			ts, err := impersonate.IDTokenSource(ctx, impersonate.IDTokenConfig{
+				TargetPrincipal: targetPrincipal,
+				Audience:        audience,
+				IncludeEmail:    true,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed impersonate.IDTokenSource: %w", err)
+			}
+			return newIDTokenAuth(ts)
 		}
-		return setIDTokenAuth(p, ts)
+		return nil
 	}
 }
 
-func setIDTokenAuth(p *Processor, ts oauth2.TokenSource) error {
+// audience returns the ID token audience of the target address: its endpoint
+// with the https scheme and without the default port.
+func audience(address string) string {
+	return "https://" + strings.TrimSuffix(endpoint(address), ":443")
+}
+
+func newIDTokenAuth(ts oauth2.TokenSource) (*idTokenAuth, error) {
 	systemRoots, err := x509.SystemCertPool()
 	if err != nil {
-		return fmt.Errorf("failed to load system cert pool: %w", err)
+		return nil, fmt.Errorf("failed to load system cert pool: %w", err)
 	}
 
 	//nolint:gosec // We need to support TLS 1.2 for now (G402).
@@ -69,13 +83,12 @@ func setIDTokenAuth(p *Processor, ts oauth2.TokenSource) error {
 		RootCAs: systemRoots,
 	})
 
-	p.authOpts = &idTokenAuth{
+	return &idTokenAuth{
 		dialOptions: []grpc.DialOption{grpc.WithTransportCredentials(cred)},
 
 		// Persist a token source to reuse tokens.
 		tokenSource: ts,
-	}
-	return nil
+	}, nil
 }
 
 type idTokenAuth struct {
@@ -17,18 +17,38 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/internal/batcher"
+	"github.com/abcxyz/pkg/logging"
 )
 
+// DefaultMaxBatchSize is the default maximum number of audit log requests sent
+// in a single ProcessLogs call.
+const DefaultMaxBatchSize = 100
+
+// DefaultBatchTimeout is the default timeout of sending a batch collected with
+// WithBatching.
+const DefaultBatchTimeout = 10 * time.Second
+
 // Option is the option to set up a remote audit log processor.
 type Option func(*Processor) error
 
-// WithGRPCDialOptions allows provide raw grpc.DialOption for the underlying connection.
+// WithGRPCDialOptions allows provide raw grpc.DialOption for the underlying connections.
 func WithGRPCDialOptions(opts ...grpc.DialOption) Option {
 	return func(p *Processor) error {
 		p.rawDialOpts = opts
@@ -36,6 +56,42 @@ func WithGRPCDialOptions(opts ...grpc.DialOption) Option {
 	}
 }
 
+// WithBatching coalesces the log requests processed concurrently into a
+// single ProcessLogs call: a batch is sent once it holds maxSize log requests,
+// or maxDelay after its first log request. Each Process call waits for the
+// result of its own log request. A log request whose context is done is
+// removed from the batch if it wasn't sent yet, and otherwise waits for the
+// batch result, so that an error means it wasn't delivered. Without batching,
+// each log request is sent with ProcessLog.
+//
+// maxSize also bounds the size of the batches sent by ProcessBatch. The
+// default is DefaultMaxBatchSize.
+func WithBatching(maxSize int, maxDelay time.Duration) Option {
+	return func(p *Processor) error {
+		if maxSize < 1 {
+			return fmt.Errorf("max batch size must be positive")
+		}
+		if maxDelay <= 0 {
+			return fmt.Errorf("batch delay must be positive")
+		}
+		p.maxBatchSize, p.batchDelay = maxSize, maxDelay
+		return nil
+	}
+}
+
+// WithBatchTimeout sets the timeout of sending each batch collected with
+// WithBatching, so that a stuck remote service doesn't block Stop. The default
+// is DefaultBatchTimeout.
+func WithBatchTimeout(d time.Duration) Option {
+	return func(p *Processor) error {
+		if d <= 0 {
+			return fmt.Errorf("batch timeout must be positive")
+		}
+		p.batchTimeout = d
+		return nil
+	}
+}
+
 // WithDefaultAuth sets up the processor to connect to remote with the default auth setting.
 func WithDefaultAuth() Option {
 	return WithIDTokenAuth(context.Background())
@@ -50,14 +106,57 @@ type grpcAuthOptions interface {
 
 // Processor is the remote audit log processor.
 type Processor struct {
-	address     string
-	conn        *grpc.ClientConn
-	client      api.AuditLogAgentClient
-	authOpts    grpcAuthOptions
+	// addresses are the addresses of the targets, in priority order.
+	addresses []string
+	// newAuth returns the auth setting of a target for the given ID token
+	// audience, if any.
+	newAuth     func(audience string) (grpcAuthOptions, error)
 	rawDialOpts []grpc.DialOption
+	// tls is the TLS setting of the connections, see WithTLS.
+	tls *tlsReloader
+
+	// Load balancing settings, see WithLoadBalancingPolicy and
+	// WithEjectionDuration.
+	policy           LoadBalancingPolicy
+	ejectionDuration time.Duration
+	targets          []*target
+	// next is the round robin counter.
+	next atomic.Uint64
+
+	// Batching settings, see WithBatching.
+	maxBatchSize int
+	batchDelay   time.Duration
+	batchTimeout time.Duration
+
+	// batcher collects the batches if batching is enabled.
+	batcher *batcher.Batcher[*pendingLog]
+}
+
+// pendingLog is a log request waiting to be sent in a batch. The log request
+// is a copy, so that the caller can give up waiting for the batch.
+type pendingLog struct {
+	logReq *api.AuditLogRequest
+	done   chan error
+	// result is the result returned by the remote service, if any. It's set
+	// before done receives the error.
+	result *result
 }
 
-// NewProcessor creates a new remote audit log processor.
+// result is what the remote service returned for a log request.
+type result struct {
+	// logReq is the processed log request, if any.
+	logReq *api.AuditLogRequest
+	// updateMask holds the fields of logReq to merge, if any.
+	updateMask  *fieldmaskpb.FieldMask
+	diagnostics []*api.Diagnostic
+}
+
+// NewProcessor creates a new remote audit log processor. The address is
+// either a "host:port" address or a gRPC resolver target, e.g.
+// "dns:///agent.example.com:443" to spread the log requests over the backends
+// the name resolves to. More targets can be added with WithAddresses. With
+// ID token auth, each target gets ID tokens for the audience of its host,
+// e.g. "https://agent.example.com".
 //
 // E.g.
 //
@@ -65,62 +164,280 @@ type Processor struct {
 //	if err != nil { ... }
 //	defer p.Close()
 func NewProcessor(address string, opts ...Option) (*Processor, error) {
-	p := &Processor{address: address}
+	p := &Processor{
+		addresses:        []string{address},
+		maxBatchSize:     DefaultMaxBatchSize,
+		batchTimeout:     DefaultBatchTimeout,
+		ejectionDuration: DefaultEjectionDuration,
+	}
 	for _, o := range opts {
 		if err := o(p); err != nil {
 			return nil, fmt.Errorf("failed to set option: %w", err)
 		}
 	}
 
-	dialOpts := append(p.rawDialOpts, grpc.WithAuthority(address))
-	if p.authOpts != nil {
-		authDialOpts, err := p.authOpts.dialOpts()
+	for _, a := range p.addresses {
+		t, err := p.newTarget(a)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate gRPC auth dial options: %w", err)
+			// Don't leak the connections dialed so far.
+			return nil, errors.Join(err, p.closeTargets())
 		}
-		dialOpts = append(dialOpts, authDialOpts...)
-	} else {
-		// If no auth option is provided, fall back to insecure.
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		p.targets = append(p.targets, t)
 	}
-	conn, err := grpc.NewClient(address, dialOpts...)
-	if err != nil {
-		return nil, fmt.Errorf("dial remote log processor failed: %w", err)
+	if p.batchDelay > 0 {
+		p.batcher = batcher.New(p.maxBatchSize, p.batchDelay, p.flush)
 	}
-
-	p.conn = conn
-	p.client = api.NewAuditLogAgentClient(conn)
 	return p, nil
 }
 
-// Process processes the audit log request by calling a remote service.
+// Process processes the audit log request by calling a remote service, in a
+// batch if batching is enabled. The fields that the remote service set are
+// merged into the log request, see applyResult. The diagnostics that the
+// remote service returned are only logged, see ProcessDiagnostics.
 func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
-	var authCallOpts []grpc.CallOption
-	if p.authOpts != nil {
+	_, err := p.processOne(ctx, logReq)
+	return err
+}
+
+// processOne processes a single log request as Process does, and returns the
+// diagnostics that the remote service returned for it.
+func (p *Processor) processOne(ctx context.Context, logReq *api.AuditLogRequest) ([]*api.Diagnostic, error) {
+	if p.batcher != nil {
+		return p.processBatched(ctx, logReq)
+	}
+
+	var resp *api.AuditLogResponse
+	if err := p.call(ctx, func(ctx context.Context, t *target, callOpts []grpc.CallOption) error {
 		var err error
-		authCallOpts, err = p.authOpts.callOpts()
+		resp, err = t.client.ProcessLog(ctx, logReq, callOpts...)
 		if err != nil {
-			return fmt.Errorf("failed to generate gRPC auth call options: %w", err)
+			return fmt.Errorf("remote log processing failed: %w", err)
 		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-	resp, err := p.client.ProcessLog(ctx, logReq, authCallOpts...)
-	if err != nil {
-		return fmt.Errorf("remote log processing failed: %w", err)
+
+	r := &result{
+		logReq:      resp.GetResult(),
+		updateMask:  resp.GetUpdateMask(),
+		diagnostics: resp.GetDiagnostics(),
+	}
+	return r.diagnostics, applyResult(ctx, logReq, r)
+}
+
+// ProcessBatch processes the log requests by calling the remote service with
+// as many ProcessLogs calls as needed to respect the max batch size.
+func (p *Processor) ProcessBatch(ctx context.Context, logReqs []*api.AuditLogRequest) error {
+	_, err := p.ProcessDiagnostics(ctx, logReqs)
+	return err
+}
+
+// ProcessDiagnostics processes the log requests as ProcessBatch does, and
+// returns the diagnostics that the remote service returned for each of them,
+// in order, even for the failed ones. A single log request is processed as
+// Process does.
+func (p *Processor) ProcessDiagnostics(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*api.Diagnostic, error) {
+	diags := make([][]*api.Diagnostic, len(logReqs))
+	if len(logReqs) == 1 {
+		var err error
+		diags[0], err = p.processOne(ctx, logReqs[0])
+		return diags, err
 	}
 
-	if resp.GetResult() != nil {
-		logReq.Labels = resp.GetResult().GetLabels()
-		logReq.Payload = resp.GetResult().GetPayload()
-		logReq.Type = resp.GetResult().GetType()
+	errs := make([]error, len(logReqs))
+	var failed bool
+	for start := 0; start < len(logReqs); start += p.maxBatchSize {
+		end := min(start+p.maxBatchSize, len(logReqs))
+		results, chunkErrs := p.send(ctx, logReqs[start:end])
+		for i, err := range chunkErrs {
+			if r := results[i]; r != nil {
+				diags[start+i] = r.diagnostics
+			}
+			if err == nil {
+				err = applyResult(ctx, logReqs[start+i], results[i])
+			} else {
+				logDiagnostics(ctx, results[i])
+			}
+			if err != nil {
+				errs[start+i], failed = err, true
+			}
+		}
 	}
 
-	return nil
+	if failed {
+		return diags, &auditerrors.BatchError{Errs: errs}
+	}
+	return diags, nil
 }
 
-// Stop stops the processor.
+// Stop sends the pending batch, waits for the batches being sent, and closes
+// the connections. The processor can't be used afterwards.
 func (p *Processor) Stop() error {
-	if err := p.conn.Close(); err != nil {
-		return fmt.Errorf("failed to close grpc connection: %w", err)
+	if p.batcher != nil {
+		p.batcher.Stop()
+	}
+	return p.closeTargets()
+}
+
+// closeTargets closes the connections of the targets.
+func (p *Processor) closeTargets() error {
+	var merr error
+	for _, t := range p.targets {
+		if err := t.conn.Close(); err != nil {
+			merr = errors.Join(merr, fmt.Errorf("failed to close grpc connection to %q: %w", t.address, err))
+		}
+	}
+	return merr
+}
+
+// processBatched adds the log request to the pending batch and waits for its
+// result, and returns its diagnostics.
+func (p *Processor) processBatched(ctx context.Context, logReq *api.AuditLogRequest) ([]*api.Diagnostic, error) {
+	clone, ok := proto.Clone(logReq).(*api.AuditLogRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected *api.AuditLogRequest, got %T", clone)
+	}
+	l := &pendingLog{logReq: clone, done: make(chan error, 1)}
+	if !p.batcher.Add(l) {
+		return nil, fmt.Errorf("remote processor is stopped")
+	}
+	var err error
+	select {
+	case err = <-l.done:
+	case <-ctx.Done():
+		if p.batcher.Remove(l) {
+			return nil, fmt.Errorf("failed to wait for remote log processing batch: %w", ctx.Err())
+		}
+		// The batch is being sent, so wait for its result, which is bounded
+		// by the batch timeout, rather than fail a log request that may be
+		// delivered and then retried.
+		err = <-l.done
+	}
+	var diags []*api.Diagnostic
+	if l.result != nil {
+		diags = l.result.diagnostics
+	}
+	if err != nil {
+		logDiagnostics(ctx, l.result)
+		return diags, err
+	}
+	return diags, applyResult(ctx, logReq, l.result)
+}
+
+// flush sends the batch and notifies its log requests of their result. The
+// batch outlives the callers, so it's sent without their context, bounded by
+// the batch timeout instead.
+func (p *Processor) flush(batch []*pendingLog) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.batchTimeout)
+	defer cancel()
+
+	logReqs := make([]*api.AuditLogRequest, 0, len(batch))
+	for _, l := range batch {
+		logReqs = append(logReqs, l.logReq)
+	}
+	results, errs := p.send(ctx, logReqs)
+	for i, l := range batch {
+		l.result = results[i]
+		l.done <- errs[i]
+	}
+}
+
+// send calls ProcessLogs with the log requests, and returns the result and the
+// error of each of them.
+func (p *Processor) send(ctx context.Context, logReqs []*api.AuditLogRequest) ([]*result, []error) {
+	results := make([]*result, len(logReqs))
+	errs := make([]error, len(logReqs))
+	fail := func(err error) ([]*result, []error) {
+		for i := range errs {
+			errs[i] = err
+		}
+		return results, errs
+	}
+
+	var resp *api.ProcessLogsResponse
+	if err := p.call(ctx, func(ctx context.Context, t *target, callOpts []grpc.CallOption) error {
+		var err error
+		resp, err = t.client.ProcessLogs(ctx, &api.ProcessLogsRequest{Requests: logReqs}, callOpts...)
+		if err != nil {
+			return fmt.Errorf("remote log processing failed: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return fail(err)
+	}
+	if got, want := len(resp.GetResults()), len(logReqs); got != want {
+		return fail(status.Errorf(codes.Internal, "remote returned %d results for %d log requests", got, want))
+	}
+
+	for i, r := range resp.GetResults() {
+		results[i] = &result{diagnostics: r.GetDiagnostics()}
+		if st := r.GetStatus(); st.GetCode() != int32(codes.OK) {
+			errs[i] = fmt.Errorf("remote log processing failed: %w", status.ErrorProto(st))
+			continue
+		}
+		results[i].logReq = r.GetResult()
+		results[i].updateMask = r.GetUpdateMask()
+	}
+	return results, errs
+}
+
+// applyResult logs the diagnostics of the result and updates the log
+// request with the processed log request, if any. Only the fields in the
+// update mask are merged, and a masked field unset in the processed log
+// request is cleared. Without an update mask, the labels, the payload and the
+// type are merged, as agents predating the mask expect.
+func applyResult(ctx context.Context, logReq *api.AuditLogRequest, r *result) error {
+	logDiagnostics(ctx, r)
+	if r == nil || r.logReq == nil {
+		return nil
+	}
+
+	if r.updateMask == nil {
+		logReq.Labels = r.logReq.GetLabels()
+		logReq.Payload = r.logReq.GetPayload()
+		logReq.Type = r.logReq.GetType()
+		return nil
+	}
+
+	if !r.updateMask.IsValid(logReq) {
+		return fmt.Errorf("remote returned an invalid update mask %q", r.updateMask.GetPaths())
+	}
+	for _, path := range r.updateMask.GetPaths() {
+		mergeField(logReq.ProtoReflect(), r.logReq.ProtoReflect(), strings.Split(path, "."))
 	}
 	return nil
 }
+
+// mergeField sets the field at the path of dst to its value in src, or clears
+// it if it's unset in src. The path must be valid.
+func mergeField(dst, src protoreflect.Message, path []string) {
+	fd := dst.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if len(path) > 1 {
+		mergeField(dst.Mutable(fd).Message(), src.Get(fd).Message(), path[1:])
+		return
+	}
+	if src.Has(fd) {
+		dst.Set(fd, src.Get(fd))
+	} else {
+		dst.Clear(fd)
+	}
+}
+
+// logDiagnostics logs the diagnostics that the remote service returned for a
+// log request.
+func logDiagnostics(ctx context.Context, r *result) {
+	if r == nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	for _, d := range r.diagnostics {
+		level := slog.LevelInfo
+		if d.GetSeverity() == api.Diagnostic_WARNING {
+			level = slog.LevelWarn
+		}
+		logger.Log(ctx, level, "remote log processing reported a diagnostic",
+			"severity", d.GetSeverity(),
+			"message", d.GetMessage())
+	}
+}
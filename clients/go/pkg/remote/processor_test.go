@@ -16,9 +16,12 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -26,9 +29,13 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
 )
 
 type fakeServer struct {
@@ -82,6 +89,19 @@ func TestProcessor_Process_Insecure(t *testing.T) {
 				"foo": "bar",
 				"abc": "123",
 			})),
+	}, {
+		name: "success_update_mask",
+		server: &fakeServer{
+			resp: &api.AuditLogResponse{
+				Result: testutil.NewRequest(
+					testutil.WithLabels(map[string]string{"abc": "123"}),
+					testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mode"}},
+			},
+		},
+		req:           testutil.NewRequest(testutil.WithLabels(map[string]string{"foo": "bar"})),
+		wantSentReq:   testutil.NewRequest(testutil.WithLabels(map[string]string{"foo": "bar"})),
+		wantResultReq: testutil.NewRequest(testutil.WithLabels(map[string]string{"foo": "bar"}), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
 	}, {
 		name: "server_error",
 		server: &fakeServer{
@@ -138,3 +158,455 @@ func TestProcessor_Process_Insecure(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyResult(t *testing.T) {
+	t.Parallel()
+
+	ts := timestamppb.New(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	cases := []struct {
+		name          string
+		req           *api.AuditLogRequest
+		result        *result
+		wantReq       *api.AuditLogRequest
+		wantErrSubstr string
+	}{{
+		name:    "no_result",
+		req:     testutil.NewRequest(),
+		result:  &result{},
+		wantReq: testutil.NewRequest(),
+	}, {
+		name: "legacy_without_mask",
+		req:  testutil.NewRequest(),
+		result: &result{
+			logReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest(testutil.WithMethodName("processed"), testutil.WithLabels(map[string]string{"foo": "bar"}))
+				r.Timestamp = ts
+				return r
+			}(),
+		},
+		// The timestamp isn't merged without a mask.
+		wantReq: testutil.NewRequest(testutil.WithMethodName("processed"), testutil.WithLabels(map[string]string{"foo": "bar"})),
+	}, {
+		name: "masked_fields",
+		req:  testutil.NewRequest(),
+		result: &result{
+			logReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest(testutil.WithMethodName("processed"), testutil.WithLabels(map[string]string{"foo": "bar"}))
+				r.Timestamp = ts
+				return r
+			}(),
+			updateMask: &fieldmaskpb.FieldMask{Paths: []string{"timestamp", "labels"}},
+		},
+		wantReq: func() *api.AuditLogRequest {
+			r := testutil.NewRequest(testutil.WithLabels(map[string]string{"foo": "bar"}))
+			r.Timestamp = ts
+			return r
+		}(),
+	}, {
+		name: "masked_nested_field",
+		req:  testutil.NewRequest(),
+		result: &result{
+			logReq:     testutil.NewRequest(testutil.WithMethodName("processed"), testutil.WithServiceName("processed")),
+			updateMask: &fieldmaskpb.FieldMask{Paths: []string{"payload.method_name"}},
+		},
+		wantReq: testutil.NewRequest(testutil.WithMethodName("processed")),
+	}, {
+		name: "masked_unset_field_cleared",
+		req:  testutil.NewRequest(testutil.WithLabels(map[string]string{"foo": "bar"})),
+		result: &result{
+			logReq:     testutil.NewRequest(),
+			updateMask: &fieldmaskpb.FieldMask{Paths: []string{"labels"}},
+		},
+		wantReq: testutil.NewRequest(),
+	}, {
+		name: "empty_mask",
+		req:  testutil.NewRequest(),
+		result: &result{
+			logReq:     testutil.NewRequest(testutil.WithMethodName("processed")),
+			updateMask: &fieldmaskpb.FieldMask{},
+		},
+		wantReq: testutil.NewRequest(),
+	}, {
+		name: "invalid_mask",
+		req:  testutil.NewRequest(),
+		result: &result{
+			logReq:     testutil.NewRequest(testutil.WithMethodName("processed")),
+			updateMask: &fieldmaskpb.FieldMask{Paths: []string{"unknown"}},
+		},
+		wantReq:       testutil.NewRequest(),
+		wantErrSubstr: `invalid update mask ["unknown"]`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := applyResult(t.Context(), tc.req, tc.result)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("applyResult() got unexpected error substring: %v", diff)
+			}
+			if diff := cmp.Diff(tc.wantReq, tc.req, protocmp.Transform()); diff != "" {
+				t.Errorf("applyResult() request (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// batchServer is a fake remote service processing batches. It fails the log
+// requests whose method is in failMethods, and labels the other ones as
+// processed.
+type batchServer struct {
+	api.UnimplementedAuditLogAgentServer
+
+	failMethods map[string]codes.Code
+	// hang makes ProcessLogs wait until the call is canceled.
+	hang bool
+	// diagnostics are returned for every log request.
+	diagnostics []*api.Diagnostic
+
+	mu         sync.Mutex
+	batchSizes []int
+}
+
+func (s *batchServer) ProcessLogs(ctx context.Context, req *api.ProcessLogsRequest) (*api.ProcessLogsResponse, error) {
+	s.mu.Lock()
+	s.batchSizes = append(s.batchSizes, len(req.GetRequests()))
+	s.mu.Unlock()
+
+	if s.hang {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	resp := &api.ProcessLogsResponse{}
+	for _, logReq := range req.GetRequests() {
+		if c, ok := s.failMethods[logReq.GetPayload().GetMethodName()]; ok {
+			resp.Results = append(resp.Results, &api.ProcessLogsResult{
+				Status:      status.New(c, "injected err").Proto(),
+				Diagnostics: s.diagnostics,
+			})
+			continue
+		}
+		logReq.Labels = map[string]string{"processed": "true"}
+		resp.Results = append(resp.Results, &api.ProcessLogsResult{
+			Result:      logReq,
+			Diagnostics: s.diagnostics,
+		})
+	}
+	return resp, nil
+}
+
+func (s *batchServer) gotBatchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.batchSizes...)
+}
+
+func newBatchTestProcessor(tb testing.TB, s *batchServer, opts ...Option) *Processor {
+	tb.Helper()
+
+	addr, _ := testutil.TestFakeGRPCServer(tb, func(gs *grpc.Server) {
+		api.RegisterAuditLogAgentServer(gs, s)
+	})
+	p, err := NewProcessor(addr, opts...)
+	if err != nil {
+		tb.Fatalf("NewProcessor() failed: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := p.Stop(); err != nil {
+			tb.Errorf("failed to stop processor: %v", err)
+		}
+	})
+	return p
+}
+
+func TestProcessor_ProcessBatch(t *testing.T) {
+	t.Parallel()
+
+	s := &batchServer{failMethods: map[string]codes.Code{"m2": codes.Unavailable}}
+	p := newBatchTestProcessor(t, s, WithBatching(2, time.Hour))
+
+	reqs := []*api.AuditLogRequest{
+		testutil.NewRequest(testutil.WithMethodName("m1")),
+		testutil.NewRequest(testutil.WithMethodName("m2")),
+		testutil.NewRequest(testutil.WithMethodName("m3")),
+	}
+	err := p.ProcessBatch(t.Context(), reqs)
+
+	var batchErr *auditerrors.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("ProcessBatch() got error %v, want a *auditerrors.BatchError", err)
+	}
+	gotCodes := make([]codes.Code, 0, len(batchErr.Errs))
+	for _, err := range batchErr.Errs {
+		gotCodes = append(gotCodes, status.Code(err))
+	}
+	if diff := cmp.Diff([]codes.Code{codes.OK, codes.Unavailable, codes.OK}, gotCodes); diff != "" {
+		t.Errorf("ProcessBatch() error codes (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 1}, s.gotBatchSizes()); diff != "" {
+		t.Errorf("server batch sizes (-want,+got):\n%s", diff)
+	}
+
+	wantReqs := []*api.AuditLogRequest{
+		testutil.NewRequest(testutil.WithMethodName("m1"), testutil.WithLabels(map[string]string{"processed": "true"})),
+		testutil.NewRequest(testutil.WithMethodName("m2")),
+		testutil.NewRequest(testutil.WithMethodName("m3"), testutil.WithLabels(map[string]string{"processed": "true"})),
+	}
+	if diff := cmp.Diff(wantReqs, reqs, protocmp.Transform()); diff != "" {
+		t.Errorf("ProcessBatch() requests (-want,+got):\n%s", diff)
+	}
+}
+
+func TestProcessor_ProcessDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	warning := &api.Diagnostic{Severity: api.Diagnostic_WARNING, Message: "injected warning"}
+
+	t.Run("batch", func(t *testing.T) {
+		t.Parallel()
+
+		s := &batchServer{
+			failMethods: map[string]codes.Code{"m2": codes.Unavailable},
+			diagnostics: []*api.Diagnostic{warning},
+		}
+		p := newBatchTestProcessor(t, s)
+
+		reqs := []*api.AuditLogRequest{
+			testutil.NewRequest(testutil.WithMethodName("m1")),
+			testutil.NewRequest(testutil.WithMethodName("m2")),
+		}
+		diags, err := p.ProcessDiagnostics(t.Context(), reqs)
+
+		var batchErr *auditerrors.BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("ProcessDiagnostics() got error %v, want a *auditerrors.BatchError", err)
+		}
+		// The failed log request also gets its diagnostics.
+		want := [][]*api.Diagnostic{{warning}, {warning}}
+		if diff := cmp.Diff(want, diags, protocmp.Transform()); diff != "" {
+			t.Errorf("ProcessDiagnostics() diagnostics (-want,+got):\n%s", diff)
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		t.Parallel()
+
+		s := &fakeServer{
+			resp: &api.AuditLogResponse{
+				Result:      testutil.NewRequest(),
+				Diagnostics: []*api.Diagnostic{warning},
+			},
+		}
+		addr, _ := testutil.TestFakeGRPCServer(t, func(gs *grpc.Server) {
+			api.RegisterAuditLogAgentServer(gs, s)
+		})
+		p, err := NewProcessor(addr)
+		if err != nil {
+			t.Fatalf("NewProcessor() failed: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := p.Stop(); err != nil {
+				t.Errorf("failed to stop processor: %v", err)
+			}
+		})
+
+		diags, err := p.ProcessDiagnostics(t.Context(), []*api.AuditLogRequest{testutil.NewRequest()})
+		if err != nil {
+			t.Fatalf("ProcessDiagnostics() unexpected error: %v", err)
+		}
+		want := [][]*api.Diagnostic{{warning}}
+		if diff := cmp.Diff(want, diags, protocmp.Transform()); diff != "" {
+			t.Errorf("ProcessDiagnostics() diagnostics (-want,+got):\n%s", diff)
+		}
+	})
+}
+
+func TestProcessor_Batching(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full_batch", func(t *testing.T) {
+		t.Parallel()
+
+		s := &batchServer{failMethods: map[string]codes.Code{"m0": codes.PermissionDenied}}
+		p := newBatchTestProcessor(t, s, WithBatching(3, time.Hour))
+
+		reqs := make([]*api.AuditLogRequest, 3)
+		errs := make([]error, 3)
+		var wg sync.WaitGroup
+		for i := range reqs {
+			reqs[i] = testutil.NewRequest(testutil.WithMethodName(fmt.Sprintf("m%d", i)))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = p.Process(t.Context(), reqs[i])
+			}()
+		}
+		wg.Wait()
+
+		if got, want := status.Code(errs[0]), codes.PermissionDenied; got != want {
+			t.Errorf("Process() got code %v, want %v", got, want)
+		}
+		for i := 1; i < 3; i++ {
+			if errs[i] != nil {
+				t.Errorf("Process() unexpected error: %v", errs[i])
+			}
+			if got, want := reqs[i].GetLabels()["processed"], "true"; got != want {
+				t.Errorf("Process() got processed label %q, want %q", got, want)
+			}
+		}
+		if diff := cmp.Diff([]int{3}, s.gotBatchSizes()); diff != "" {
+			t.Errorf("server batch sizes (-want,+got):\n%s", diff)
+		}
+	})
+
+	t.Run("batch_delay", func(t *testing.T) {
+		t.Parallel()
+
+		s := &batchServer{}
+		p := newBatchTestProcessor(t, s, WithBatching(10, 10*time.Millisecond))
+
+		if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+			t.Errorf("Process() unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]int{1}, s.gotBatchSizes()); diff != "" {
+			t.Errorf("server batch sizes (-want,+got):\n%s", diff)
+		}
+	})
+
+	t.Run("stop_sends_pending_batch", func(t *testing.T) {
+		t.Parallel()
+
+		s := &batchServer{}
+		addr, _ := testutil.TestFakeGRPCServer(t, func(gs *grpc.Server) {
+			api.RegisterAuditLogAgentServer(gs, s)
+		})
+		p, err := NewProcessor(addr, WithBatching(10, time.Hour))
+		if err != nil {
+			t.Fatalf("NewProcessor() failed: %v", err)
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- p.Process(t.Context(), testutil.NewRequest())
+		}()
+		for {
+			if p.batcher.Len() == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := p.Stop(); err != nil {
+			t.Fatalf("Stop() unexpected error: %v", err)
+		}
+		if err := <-errCh; err != nil {
+			t.Errorf("Process() unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]int{1}, s.gotBatchSizes()); diff != "" {
+			t.Errorf("server batch sizes (-want,+got):\n%s", diff)
+		}
+		if err := p.Process(t.Context(), testutil.NewRequest()); err == nil {
+			t.Errorf("Process() after Stop() got no error")
+		}
+	})
+
+	t.Run("cancel_removes_pending_log", func(t *testing.T) {
+		t.Parallel()
+
+		s := &batchServer{}
+		addr, _ := testutil.TestFakeGRPCServer(t, func(gs *grpc.Server) {
+			api.RegisterAuditLogAgentServer(gs, s)
+		})
+		p, err := NewProcessor(addr, WithBatching(10, time.Hour))
+		if err != nil {
+			t.Fatalf("NewProcessor() failed: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- p.Process(ctx, testutil.NewRequest())
+		}()
+		for {
+			if p.batcher.Len() == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Errorf("Process() got error %v, want %v", err, context.Canceled)
+		}
+		if err := p.Stop(); err != nil {
+			t.Fatalf("Stop() unexpected error: %v", err)
+		}
+		// The cancelled log request is not sent.
+		if got := s.gotBatchSizes(); len(got) != 0 {
+			t.Errorf("server got batch sizes %v, want none", got)
+		}
+	})
+
+	t.Run("batch_timeout", func(t *testing.T) {
+		t.Parallel()
+
+		s := &batchServer{hang: true}
+		p := newBatchTestProcessor(t, s, WithBatching(10, time.Millisecond), WithBatchTimeout(10*time.Millisecond))
+
+		if got, want := status.Code(p.Process(t.Context(), testutil.NewRequest())), codes.DeadlineExceeded; got != want {
+			t.Errorf("Process() got code %v, want %v", got, want)
+		}
+	})
+}
+
+func TestNewProcessor_Batching(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		maxSize  int
+		maxDelay time.Duration
+		timeout  time.Duration
+		wantErr  string
+	}{{
+		name:     "valid",
+		maxSize:  10,
+		maxDelay: time.Second,
+	}, {
+		name:     "zero_size",
+		maxDelay: time.Second,
+		wantErr:  "max batch size must be positive",
+	}, {
+		name:    "zero_delay",
+		maxSize: 10,
+		wantErr: "batch delay must be positive",
+	}, {
+		name:     "negative_timeout",
+		maxSize:  10,
+		maxDelay: time.Second,
+		timeout:  -time.Second,
+		wantErr:  "batch timeout must be positive",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []Option{WithBatching(tc.maxSize, tc.maxDelay)}
+			if tc.timeout != 0 {
+				opts = append(opts, WithBatchTimeout(tc.timeout))
+			}
+			p, err := NewProcessor("localhost:0", opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error substring: %v", diff)
+			}
+			if p != nil {
+				if err := p.Stop(); err != nil {
+					t.Errorf("failed to stop processor: %v", err)
+				}
+			}
+		})
+	}
+}
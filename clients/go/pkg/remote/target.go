@@ -0,0 +1,237 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // Enables client-side health checking.
+	"google.golang.org/grpc/status"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// DefaultEjectionDuration is the default duration for which an unavailable
+// target is ejected.
+const DefaultEjectionDuration = 30 * time.Second
+
+// LoadBalancingPolicy is how the processor spreads the log requests over its
+// targets.
+type LoadBalancingPolicy int
+
+const (
+	// PriorityFailover sends the log requests to the first healthy target, in
+	// the order of the addresses. This is the default.
+	PriorityFailover LoadBalancingPolicy = iota
+
+	// RoundRobin spreads the log requests evenly over the healthy targets.
+	RoundRobin
+)
+
+// WithAddresses adds targets to the processor, after the address given to
+// NewProcessor, e.g. the agents of other regions to fail over to.
+func WithAddresses(addresses ...string) Option {
+	return func(p *Processor) error {
+		for _, a := range addresses {
+			if a == "" {
+				return fmt.Errorf("remote address must not be empty")
+			}
+		}
+		p.addresses = append(p.addresses, addresses...)
+		return nil
+	}
+}
+
+// WithLoadBalancingPolicy sets how the log requests are spread over the
+// targets, and over the backends of a resolver target. The default is
+// PriorityFailover.
+func WithLoadBalancingPolicy(policy LoadBalancingPolicy) Option {
+	return func(p *Processor) error {
+		if policy != PriorityFailover && policy != RoundRobin {
+			return fmt.Errorf("unknown load balancing policy %d", policy)
+		}
+		p.policy = policy
+		return nil
+	}
+}
+
+// WithEjectionDuration sets how long a target that failed with
+// codes.Unavailable is ejected. Ejected targets are only called once all the
+// healthy targets failed. The default is DefaultEjectionDuration.
+func WithEjectionDuration(d time.Duration) Option {
+	return func(p *Processor) error {
+		if d <= 0 {
+			return fmt.Errorf("ejection duration must be positive")
+		}
+		p.ejectionDuration = d
+		return nil
+	}
+}
+
+// target is a remote service the processor calls, with its own connection
+// and auth setting.
+type target struct {
+	address  string
+	conn     *grpc.ClientConn
+	client   api.AuditLogAgentClient
+	authOpts grpcAuthOptions
+
+	// ejectedUntil is the Unix time in nanoseconds until which the target is
+	// ejected, or 0 if it's healthy.
+	ejectedUntil atomic.Int64
+}
+
+// newTarget dials the target address.
+func (p *Processor) newTarget(address string) (*target, error) {
+	t := &target{address: address}
+	if p.newAuth != nil {
+		authOpts, err := p.newAuth(audience(address))
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up auth for %q: %w", address, err)
+		}
+		t.authOpts = authOpts
+	}
+
+	authority := endpoint(address)
+	if p.tls != nil && p.tls.cfg.ServerName != "" {
+		// gRPC derives the TLS server name from the authority.
+		authority = p.tls.cfg.ServerName
+	}
+	// The raw dial options come last to override the defaults.
+	dialOpts := []grpc.DialOption{
+		grpc.WithDefaultServiceConfig(p.serviceConfig()),
+		grpc.WithAuthority(authority),
+	}
+	switch {
+	case p.tls != nil:
+		// The TLS setting replaces the default TLS of the auth option.
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(p.tls.transportCredentials(authority)))
+	case t.authOpts != nil:
+		authDialOpts, err := t.authOpts.dialOpts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate gRPC auth dial options: %w", err)
+		}
+		dialOpts = append(dialOpts, authDialOpts...)
+	default:
+		// If no auth or TLS option is provided, fall back to insecure.
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	dialOpts = append(dialOpts, p.rawDialOpts...)
+
+	conn, err := grpc.NewClient(address, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial remote log processor failed: %w", err)
+	}
+	t.conn = conn
+	t.client = api.NewAuditLogAgentClient(conn)
+	return t, nil
+}
+
+// serviceConfig returns the gRPC service config spreading the requests over
+// the backends of a resolver target with the load balancing policy. The
+// backends that fail gRPC health checks are skipped; backends not
+// implementing the health service are considered healthy.
+func (p *Processor) serviceConfig() string {
+	lb := "pick_first"
+	if p.policy == RoundRobin {
+		lb = "round_robin"
+	}
+	return fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}],"healthCheckConfig":{"serviceName":""}}`, lb)
+}
+
+// callOpts returns the gRPC call options of the target's auth setting, if
+// any.
+func (t *target) callOpts() ([]grpc.CallOption, error) {
+	if t.authOpts == nil {
+		return nil, nil
+	}
+	opts, err := t.authOpts.callOpts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate gRPC auth call options: %w", err)
+	}
+	return opts, nil
+}
+
+// call calls f with the targets in the order of the load balancing policy,
+// until a target doesn't fail with codes.Unavailable. Such a target is
+// ejected for the ejection duration, and the ejected targets are only called
+// once all the healthy ones failed.
+func (p *Processor) call(ctx context.Context, f func(ctx context.Context, t *target, callOpts []grpc.CallOption) error) error {
+	var merr error
+	for _, t := range p.order(time.Now()) {
+		callOpts, err := t.callOpts()
+		if err != nil {
+			return err
+		}
+		err = f(ctx, t, callOpts)
+		if status.Code(err) != codes.Unavailable {
+			if err == nil {
+				t.ejectedUntil.Store(0)
+			}
+			return err
+		}
+		t.ejectedUntil.Store(time.Now().Add(p.ejectionDuration).UnixNano())
+		merr = errors.Join(merr, err)
+	}
+	return merr
+}
+
+// order returns the targets in the order to call them: the healthy targets
+// in the order of the load balancing policy, then the ejected targets from
+// the one whose ejection ends first.
+func (p *Processor) order(now time.Time) []*target {
+	healthy := make([]*target, 0, len(p.targets))
+	var ejected []*target
+	for _, t := range p.targets {
+		if t.ejectedUntil.Load() > now.UnixNano() {
+			ejected = append(ejected, t)
+			continue
+		}
+		healthy = append(healthy, t)
+	}
+
+	if p.policy == RoundRobin && len(healthy) > 1 {
+		n := int(p.next.Add(1) % uint64(len(healthy)))
+		healthy = slices.Concat(healthy[n:], healthy[:n])
+	}
+	slices.SortStableFunc(ejected, func(a, b *target) int {
+		return cmp.Compare(a.ejectedUntil.Load(), b.ejectedUntil.Load())
+	})
+	return append(healthy, ejected...)
+}
+
+// endpoint returns the endpoint of a target address, without the resolver
+// scheme of gRPC targets such as "dns:///agent.example.com:443".
+func endpoint(address string) string {
+	if !strings.Contains(address, "://") {
+		return address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return address
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
@@ -0,0 +1,356 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// countingServer counts the log requests it processes, and fails them with
+// the code if it's set.
+type countingServer struct {
+	api.UnimplementedAuditLogAgentServer
+
+	code  atomic.Uint32
+	count atomic.Int64
+}
+
+func (s *countingServer) ProcessLog(_ context.Context, _ *api.AuditLogRequest) (*api.AuditLogResponse, error) {
+	s.count.Add(1)
+	if c := codes.Code(s.code.Load()); c != codes.OK {
+		return nil, status.Error(c, "injected err")
+	}
+	return &api.AuditLogResponse{}, nil
+}
+
+func startCountingServer(tb testing.TB) (string, *countingServer) {
+	tb.Helper()
+
+	s := &countingServer{}
+	addr, _ := testutil.TestFakeGRPCServer(tb, func(gs *grpc.Server) {
+		api.RegisterAuditLogAgentServer(gs, s)
+	})
+	return addr, s
+}
+
+// closedAddress returns the address of a port nothing listens on.
+func closedAddress(tb testing.TB) string {
+	tb.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		tb.Fatalf("net.Listen(tcp, localhost:0) failed: %v", err)
+	}
+	addr := lis.Addr().String()
+	if err := lis.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return addr
+}
+
+func newTargetsTestProcessor(tb testing.TB, addrs []string, opts ...Option) *Processor {
+	tb.Helper()
+
+	p, err := NewProcessor(addrs[0], append([]Option{WithAddresses(addrs[1:]...)}, opts...)...)
+	if err != nil {
+		tb.Fatalf("NewProcessor() failed: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := p.Stop(); err != nil {
+			tb.Errorf("failed to stop processor: %v", err)
+		}
+	})
+	return p
+}
+
+func TestProcessor_LoadBalancing(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		policy     LoadBalancingPolicy
+		calls      int
+		wantCounts []int64
+	}{{
+		name:       "priority_failover",
+		policy:     PriorityFailover,
+		calls:      4,
+		wantCounts: []int64{4, 0, 0},
+	}, {
+		name:       "round_robin",
+		policy:     RoundRobin,
+		calls:      6,
+		wantCounts: []int64{2, 2, 2},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var addrs []string
+			var servers []*countingServer
+			for range 3 {
+				addr, s := startCountingServer(t)
+				addrs = append(addrs, addr)
+				servers = append(servers, s)
+			}
+			p := newTargetsTestProcessor(t, addrs, WithLoadBalancingPolicy(tc.policy))
+
+			for range tc.calls {
+				if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+					t.Fatalf("Process() unexpected error: %v", err)
+				}
+			}
+			gotCounts := make([]int64, 0, len(servers))
+			for _, s := range servers {
+				gotCounts = append(gotCounts, s.count.Load())
+			}
+			if diff := cmp.Diff(tc.wantCounts, gotCounts); diff != "" {
+				t.Errorf("server counts (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_Failover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unreachable_target", func(t *testing.T) {
+		t.Parallel()
+
+		addr, s := startCountingServer(t)
+		p := newTargetsTestProcessor(t, []string{closedAddress(t), addr})
+
+		for range 2 {
+			if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+		}
+		if got, want := s.count.Load(), int64(2); got != want {
+			t.Errorf("fallback server got %d log requests, want %d", got, want)
+		}
+		if p.targets[0].ejectedUntil.Load() == 0 {
+			t.Errorf("unreachable target was not ejected")
+		}
+	})
+
+	t.Run("ejection_ends", func(t *testing.T) {
+		t.Parallel()
+
+		addr1, s1 := startCountingServer(t)
+		addr2, s2 := startCountingServer(t)
+		s1.code.Store(uint32(codes.Unavailable))
+		p := newTargetsTestProcessor(t, []string{addr1, addr2}, WithEjectionDuration(50*time.Millisecond))
+
+		// The first target is ejected, so the next log request goes to the
+		// second target only.
+		for range 2 {
+			if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+		}
+		if got, want := []int64{s1.count.Load(), s2.count.Load()}, []int64{1, 2}; !cmp.Equal(got, want) {
+			t.Errorf("server counts got %v, want %v", got, want)
+		}
+
+		// Once the ejection ends, the first target is called again.
+		s1.code.Store(uint32(codes.OK))
+		time.Sleep(100 * time.Millisecond)
+		if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+		if got, want := []int64{s1.count.Load(), s2.count.Load()}, []int64{2, 2}; !cmp.Equal(got, want) {
+			t.Errorf("server counts got %v, want %v", got, want)
+		}
+		if got := p.targets[0].ejectedUntil.Load(); got != 0 {
+			t.Errorf("recovered target is still ejected until %v", time.Unix(0, got))
+		}
+	})
+
+	t.Run("all_unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		addr1, s1 := startCountingServer(t)
+		addr2, s2 := startCountingServer(t)
+		s1.code.Store(uint32(codes.Unavailable))
+		s2.code.Store(uint32(codes.Unavailable))
+		p := newTargetsTestProcessor(t, []string{addr1, addr2})
+
+		err := p.Process(t.Context(), testutil.NewRequest())
+		if got, want := status.Code(err), codes.Unavailable; got != want {
+			t.Errorf("Process() got code %v, want %v", got, want)
+		}
+		if got, want := []int64{s1.count.Load(), s2.count.Load()}, []int64{1, 1}; !cmp.Equal(got, want) {
+			t.Errorf("server counts got %v, want %v", got, want)
+		}
+
+		// The ejected targets are still called when no target is healthy.
+		if err := p.Process(t.Context(), testutil.NewRequest()); err == nil {
+			t.Errorf("Process() got no error")
+		}
+		if got, want := []int64{s1.count.Load(), s2.count.Load()}, []int64{2, 2}; !cmp.Equal(got, want) {
+			t.Errorf("server counts got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("no_failover_on_other_errors", func(t *testing.T) {
+		t.Parallel()
+
+		addr1, s1 := startCountingServer(t)
+		addr2, s2 := startCountingServer(t)
+		s1.code.Store(uint32(codes.InvalidArgument))
+		p := newTargetsTestProcessor(t, []string{addr1, addr2})
+
+		err := p.Process(t.Context(), testutil.NewRequest())
+		if got, want := status.Code(err), codes.InvalidArgument; got != want {
+			t.Errorf("Process() got code %v, want %v", got, want)
+		}
+		if got, want := []int64{s1.count.Load(), s2.count.Load()}, []int64{1, 0}; !cmp.Equal(got, want) {
+			t.Errorf("server counts got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("resolver_target", func(t *testing.T) {
+		t.Parallel()
+
+		addr, s := startCountingServer(t)
+		p := newTargetsTestProcessor(t, []string{"passthrough:///" + addr}, WithLoadBalancingPolicy(RoundRobin))
+
+		if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+		if got, want := s.count.Load(), int64(1); got != want {
+			t.Errorf("server got %d log requests, want %d", got, want)
+		}
+	})
+}
+
+// insecureAuth is an auth setting without credentials.
+type insecureAuth struct{}
+
+func (insecureAuth) dialOpts() ([]grpc.DialOption, error) {
+	return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+}
+
+func (insecureAuth) callOpts() ([]grpc.CallOption, error) {
+	return nil, nil
+}
+
+func TestNewProcessor_Audiences(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	withAuth := func(p *Processor) error {
+		p.newAuth = func(audience string) (grpcAuthOptions, error) {
+			got = append(got, audience)
+			return insecureAuth{}, nil
+		}
+		return nil
+	}
+	newTargetsTestProcessor(t, []string{
+		"agent.us-central1.example.com:443",
+		"agent.europe-west1.example.com:8443",
+		"dns:///agent.example.com:443",
+	}, withAuth)
+
+	want := []string{
+		"https://agent.us-central1.example.com",
+		"https://agent.europe-west1.example.com:8443",
+		"https://agent.example.com",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("audiences (-want,+got):\n%s", diff)
+	}
+}
+
+func TestNewProcessor_LoadBalancingOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{{
+		name: "valid",
+		opts: []Option{
+			WithAddresses("localhost:1", "dns:///localhost:2"),
+			WithLoadBalancingPolicy(RoundRobin),
+			WithEjectionDuration(time.Minute),
+		},
+	}, {
+		name:          "empty_address",
+		opts:          []Option{WithAddresses("")},
+		wantErrSubstr: "remote address must not be empty",
+	}, {
+		name:          "unknown_policy",
+		opts:          []Option{WithLoadBalancingPolicy(LoadBalancingPolicy(42))},
+		wantErrSubstr: "unknown load balancing policy 42",
+	}, {
+		name:          "zero_ejection_duration",
+		opts:          []Option{WithEjectionDuration(0)},
+		wantErrSubstr: "ejection duration must be positive",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewProcessor("localhost:0", tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error substring: %v", diff)
+			}
+			if p != nil {
+				if err := p.Stop(); err != nil {
+					t.Errorf("failed to stop processor: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		address string
+		want    string
+	}{
+		{address: "localhost:8080", want: "localhost:8080"},
+		{address: "agent.example.com:443", want: "agent.example.com:443"},
+		{address: "dns:///agent.example.com:443", want: "agent.example.com:443"},
+		{address: "dns://8.8.8.8/agent.example.com:443", want: "agent.example.com:443"},
+		{address: "xds:///agent", want: "agent"},
+	}
+
+	for _, tc := range cases {
+		if got := endpoint(tc.address); got != tc.want {
+			t.Errorf("endpoint(%q) got %q, want %q", tc.address, got, tc.want)
+		}
+	}
+}
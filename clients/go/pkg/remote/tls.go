@@ -0,0 +1,235 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSConfig configures the TLS connection to the remote service, e.g. for
+// mutual TLS within a service mesh. The files are reloaded when they are
+// modified, so that rotated certificates are used without a restart.
+type TLSConfig struct {
+	// CAFile is the PEM file of the CA certificates to verify the remote
+	// service with. If empty, the system cert pool is used.
+	CAFile string
+
+	// CertFile and KeyFile are the PEM files of the client certificate and
+	// its private key to present to the remote service. Both or neither must
+	// be set.
+	CertFile string
+	KeyFile  string
+
+	// ServerName overrides the server name sent with SNI and used to verify
+	// the remote service certificate, and the authority of the requests. If
+	// empty, it's the host of the address.
+	ServerName string
+}
+
+// WithTLS connects to the remote service over TLS with the given settings
+// instead of insecurely. It can be combined with the ID token auth options,
+// whose tokens are then sent over this connection.
+func WithTLS(cfg *TLSConfig) Option {
+	return func(p *Processor) error {
+		if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+			return fmt.Errorf("TLS client certificate and key files must be set together")
+		}
+		r := &tlsReloader{cfg: *cfg}
+		// Load the files upfront to fail early on invalid files.
+		if err := r.reload(); err != nil {
+			return err
+		}
+		p.tls = r
+		return nil
+	}
+}
+
+// tlsReloader provides the TLS settings of the connection, reloading the CA
+// and client certificate files whenever they are modified. A failed reload,
+// e.g. while a certificate and its key are being replaced, keeps the
+// previous settings and is retried on the next handshake.
+type tlsReloader struct {
+	cfg TLSConfig
+
+	mu sync.Mutex
+	// The loaded settings and the modification times of their files.
+	pool    *x509.CertPool
+	cert    *tls.Certificate
+	modTime map[string]time.Time
+}
+
+// transportCredentials returns the gRPC credentials of the connection to the
+// given authority.
+func (r *tlsReloader) transportCredentials(authority string) credentials.TransportCredentials {
+	// The server name is set as the authority of the connection, which gRPC
+	// uses as the TLS server name.
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if r.cfg.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.clientCertificate()
+		}
+	}
+	if r.cfg.CAFile != "" {
+		// The CA pool can change between handshakes, so the server
+		// certificate is verified against the current pool instead of a
+		// fixed RootCAs.
+		cfg.InsecureSkipVerify = true //nolint:gosec // Verified by VerifyConnection.
+		// No SNI is sent for IP addresses, so the connection state lacks the
+		// server name and the name to verify is taken from the authority.
+		serverName := authority
+		if host, _, err := net.SplitHostPort(authority); err == nil {
+			serverName = host
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return r.verifyConnection(cs, serverName)
+		}
+	}
+	return credentials.NewTLS(cfg)
+}
+
+// clientCertificate returns the current client certificate.
+func (r *tlsReloader) clientCertificate() (*tls.Certificate, error) {
+	_ = r.reload() // The previous certificate is used if the reload fails.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, nil
+}
+
+// verifyConnection verifies the server certificate chain and name against
+// the current CA pool. The server name is either a DNS name or an IP address.
+func (r *tlsReloader) verifyConnection(cs tls.ConnectionState, serverName string) error {
+	_ = r.reload() // The previous pool is used if the reload fails.
+	r.mu.Lock()
+	pool := r.pool
+	r.mu.Unlock()
+
+	if serverName == "" {
+		return fmt.Errorf("no server name to verify remote service certificate")
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("remote service presented no certificate")
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       strings.Trim(serverName, "[]"),
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+		return fmt.Errorf("failed to verify remote service certificate: %w", err)
+	}
+	return nil
+}
+
+// reload loads the files that were modified since they were last loaded.
+func (r *tlsReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var merr error
+	if r.cfg.CAFile != "" {
+		if changed, err := r.changedLocked(r.cfg.CAFile); err != nil {
+			merr = errors.Join(merr, err)
+		} else if changed {
+			if err := r.loadCAsLocked(); err != nil {
+				merr = errors.Join(merr, err)
+			}
+		}
+	}
+	if r.cfg.CertFile != "" {
+		certChanged, certErr := r.changedLocked(r.cfg.CertFile)
+		keyChanged, keyErr := r.changedLocked(r.cfg.KeyFile)
+		switch {
+		case certErr != nil || keyErr != nil:
+			merr = errors.Join(merr, certErr, keyErr)
+		case certChanged || keyChanged:
+			if err := r.loadCertLocked(); err != nil {
+				merr = errors.Join(merr, err)
+			}
+		}
+	}
+	return merr
+}
+
+// changedLocked reports whether the file was modified since it was last
+// loaded. It must be called with mu held.
+func (r *tlsReloader) changedLocked(name string) (bool, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat TLS file: %w", err)
+	}
+	last, ok := r.modTime[name]
+	return !ok || !fi.ModTime().Equal(last), nil
+}
+
+// loadCAsLocked loads the CA file. It must be called with mu held.
+func (r *tlsReloader) loadCAsLocked() error {
+	name := r.cfg.CAFile
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fmt.Errorf("failed to stat TLS CA file: %w", err)
+	}
+	pem, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read TLS CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no CA certificate found in %q", name)
+	}
+	r.pool = pool
+	r.setModTimeLocked(name, fi.ModTime())
+	return nil
+}
+
+// loadCertLocked loads the client certificate and key files. It must be
+// called with mu held.
+func (r *tlsReloader) loadCertLocked() error {
+	certInfo, err := os.Stat(r.cfg.CertFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat TLS certificate file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat TLS key file: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS client certificate: %w", err)
+	}
+	r.cert = &cert
+	r.setModTimeLocked(r.cfg.CertFile, certInfo.ModTime())
+	r.setModTimeLocked(r.cfg.KeyFile, keyInfo.ModTime())
+	return nil
+}
+
+func (r *tlsReloader) setModTimeLocked(name string, t time.Time) {
+	if r.modTime == nil {
+		r.modTime = make(map[string]time.Time)
+	}
+	r.modTime[name] = t
+}
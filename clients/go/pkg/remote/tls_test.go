@@ -0,0 +1,434 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// testCA is a certificate authority issuing test certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(tb testing.TB) *testCA {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns the PEM certificate and key of a leaf certificate for the
+// given DNS name.
+func (ca *testCA) issue(tb testing.TB, dnsName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if ip := net.ParseIP(dnsName); ip != nil {
+		tmpl.DNSNames = nil
+		tmpl.IPAddresses = []net.IP{ip}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes the file with the given modification time offset, so that
+// rewrites are detected regardless of the file system time resolution.
+func writeFile(tb testing.TB, name string, data []byte, age time.Duration) {
+	tb.Helper()
+
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		tb.Fatal(err)
+	}
+	mt := time.Now().Add(-age)
+	if err := os.Chtimes(name, mt, mt); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// authServer records the authorization metadata of the requests.
+type authServer struct {
+	fakeServer
+
+	mu   sync.Mutex
+	auth []string
+}
+
+func (s *authServer) ProcessLog(ctx context.Context, logReq *api.AuditLogRequest) (*api.AuditLogResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.auth = append(s.auth, md.Get("authorization")...)
+	s.mu.Unlock()
+	return s.fakeServer.ProcessLog(ctx, logReq)
+}
+
+// startTLSServer starts a gRPC server on 127.0.0.1 requiring client
+// certificates issued by the client CA.
+func startTLSServer(tb testing.TB, serverCA, clientCA *testCA, dnsName string, s api.AuditLogAgentServer) string {
+	tb.Helper()
+
+	certPEM, keyPEM := serverCA.issue(tb, dnsName, x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+
+	gs := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})))
+	tb.Cleanup(gs.Stop)
+	api.RegisterAuditLogAgentServer(gs, s)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("net.Listen(tcp, 127.0.0.1:0) failed: %v", err)
+	}
+	go func() {
+		if err := gs.Serve(lis); err != nil {
+			tb.Logf("serve failed: %v", err)
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestProcessor_Process_TLS(t *testing.T) {
+	t.Parallel()
+
+	const serverName = "agent.mesh.internal"
+	serverCA := newTestCA(t)
+	clientCA := newTestCA(t)
+	otherCA := newTestCA(t)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, serverCA.pem, 0)
+	otherCAFile := filepath.Join(dir, "other-ca.pem")
+	writeFile(t, otherCAFile, otherCA.pem, 0)
+	certPEM, keyPEM := clientCA.issue(t, "client.mesh.internal", x509.ExtKeyUsageClientAuth)
+	certFile := filepath.Join(dir, "cert.pem")
+	writeFile(t, certFile, certPEM, 0)
+	keyFile := filepath.Join(dir, "key.pem")
+	writeFile(t, keyFile, keyPEM, 0)
+
+	withToken := func(p *Processor) error {
+		p.newAuth = func(string) (grpcAuthOptions, error) {
+			return newIDTokenAuth(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"}))
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name          string
+		certName      string
+		opts          []Option
+		wantAuth      []string
+		wantErrSubstr string
+	}{{
+		name: "mtls",
+		opts: []Option{WithTLS(&TLSConfig{
+			CAFile:     caFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: serverName,
+		})},
+	}, {
+		name: "mtls_with_id_token",
+		opts: []Option{
+			withToken,
+			WithTLS(&TLSConfig{
+				CAFile:     caFile,
+				CertFile:   certFile,
+				KeyFile:    keyFile,
+				ServerName: serverName,
+			}),
+		},
+		wantAuth: []string{"Bearer test-token"},
+	}, {
+		name: "no_client_certificate",
+		opts: []Option{WithTLS(&TLSConfig{
+			CAFile:     caFile,
+			ServerName: serverName,
+		})},
+		wantErrSubstr: "remote log processing failed",
+	}, {
+		name: "untrusted_server",
+		opts: []Option{WithTLS(&TLSConfig{
+			CAFile:     otherCAFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: serverName,
+		})},
+		wantErrSubstr: "failed to verify remote service certificate",
+	}, {
+		name: "wrong_server_name",
+		opts: []Option{WithTLS(&TLSConfig{
+			CAFile:     caFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "other.mesh.internal",
+		})},
+		wantErrSubstr: "failed to verify remote service certificate",
+	}, {
+		name:     "ip_target",
+		certName: "127.0.0.1",
+		opts: []Option{WithTLS(&TLSConfig{
+			CAFile:   caFile,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		})},
+	}, {
+		name: "ip_target_wrong_name",
+		opts: []Option{WithTLS(&TLSConfig{
+			CAFile:   caFile,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		})},
+		wantErrSubstr: "failed to verify remote service certificate",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			certName := tc.certName
+			if certName == "" {
+				certName = serverName
+			}
+			s := &authServer{fakeServer: fakeServer{resp: &api.AuditLogResponse{}}}
+			addr := startTLSServer(t, serverCA, clientCA, certName, s)
+
+			p, err := NewProcessor(addr, tc.opts...)
+			if err != nil {
+				t.Fatalf("NewProcessor() failed: %v", err)
+			}
+			t.Cleanup(func() {
+				if err := p.Stop(); err != nil {
+					t.Errorf("failed to stop processor: %v", err)
+				}
+			})
+
+			err = p.Process(t.Context(), testutil.NewRequest())
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("Process() got unexpected error substring: %v", diff)
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if got, want := len(s.auth), len(tc.wantAuth); got != want {
+				t.Fatalf("server got %d authorization headers, want %d", got, want)
+			}
+			for i := range s.auth {
+				if got, want := s.auth[i], tc.wantAuth[i]; got != want {
+					t.Errorf("server got authorization %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, ca.pem, 0)
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	writeFile(t, invalidFile, []byte("not a certificate"), 0)
+
+	cases := []struct {
+		name          string
+		cfg           *TLSConfig
+		wantErrSubstr string
+	}{{
+		name: "system_roots",
+		cfg:  &TLSConfig{},
+	}, {
+		name: "ca_file",
+		cfg:  &TLSConfig{CAFile: caFile},
+	}, {
+		name:          "missing_ca_file",
+		cfg:           &TLSConfig{CAFile: filepath.Join(dir, "missing.pem")},
+		wantErrSubstr: "failed to stat TLS file",
+	}, {
+		name:          "invalid_ca_file",
+		cfg:           &TLSConfig{CAFile: invalidFile},
+		wantErrSubstr: "no CA certificate found",
+	}, {
+		name:          "cert_without_key",
+		cfg:           &TLSConfig{CertFile: caFile},
+		wantErrSubstr: "must be set together",
+	}, {
+		name:          "invalid_key_pair",
+		cfg:           &TLSConfig{CertFile: caFile, KeyFile: invalidFile},
+		wantErrSubstr: "failed to load TLS client certificate",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewProcessor("localhost:0", WithTLS(tc.cfg))
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error substring: %v", diff)
+			}
+			if p != nil {
+				if err := p.Stop(); err != nil {
+					t.Errorf("failed to stop processor: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTLSReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, ca.pem, time.Hour)
+	cert1, key1 := ca.issue(t, "client-1", x509.ExtKeyUsageClientAuth)
+	cert2, key2 := ca.issue(t, "client-2", x509.ExtKeyUsageClientAuth)
+	certFile := filepath.Join(dir, "cert.pem")
+	writeFile(t, certFile, cert1, time.Hour)
+	keyFile := filepath.Join(dir, "key.pem")
+	writeFile(t, keyFile, key1, time.Hour)
+
+	p := &Processor{}
+	if err := WithTLS(&TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile})(p); err != nil {
+		t.Fatal(err)
+	}
+	r := p.tls
+
+	commonName := func() string {
+		t.Helper()
+		c, err := r.clientCertificate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(c.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+	if got, want := commonName(), "client-1"; got != want {
+		t.Errorf("client certificate got %q, want %q", got, want)
+	}
+
+	// Halfway through a rotation the certificate doesn't match the key, so
+	// the previous certificate is kept.
+	writeFile(t, certFile, cert2, 30*time.Minute)
+	if got, want := commonName(), "client-1"; got != want {
+		t.Errorf("client certificate got %q, want %q", got, want)
+	}
+
+	writeFile(t, keyFile, key2, 30*time.Minute)
+	if got, want := commonName(), "client-2"; got != want {
+		t.Errorf("client certificate got %q, want %q", got, want)
+	}
+
+	// A new CA is used to verify the next connections.
+	otherCA := newTestCA(t)
+	writeFile(t, caFile, otherCA.pem, 0)
+	serverPEM, _ := otherCA.issue(t, "agent", x509.ExtKeyUsageServerAuth)
+	block, _ := pem.Decode(serverPEM)
+	serverCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := tls.ConnectionState{PeerCertificates: []*x509.Certificate{serverCert}}
+	if err := r.verifyConnection(cs, "agent"); err != nil {
+		t.Errorf("verifyConnection() unexpected error: %v", err)
+	}
+}
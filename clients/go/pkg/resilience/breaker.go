@@ -0,0 +1,139 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// StateClosed lets all log requests through to the backend.
+	StateClosed State = iota
+
+	// StateOpen rejects all log requests without calling the backend.
+	StateOpen
+
+	// StateHalfOpen lets a single trial log request through to the backend.
+	// The breaker closes if the trial succeeds and opens again otherwise.
+	StateHalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "CLOSED"
+	case StateOpen:
+		return "OPEN"
+	case StateHalfOpen:
+		return "HALF_OPEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// transition is a change of the breaker state.
+type transition struct {
+	from, to State
+}
+
+// breaker is a consecutive failures circuit breaker. Its methods return the
+// state transitions they caused, so that the caller can run the hooks
+// without holding the lock.
+type breaker struct {
+	threshold   uint64
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures uint64
+	openedAt time.Time
+	trial    bool
+}
+
+// allow reports whether a log request may be sent to the backend. In the
+// half-open state, only the first caller is allowed, as the trial.
+func (b *breaker) allow() (bool, []transition) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var ts []transition
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		ts = append(ts, b.setState(StateHalfOpen))
+	}
+
+	switch b.state {
+	case StateClosed:
+		return true, ts
+	case StateHalfOpen:
+		if b.trial {
+			return false, ts
+		}
+		b.trial = true
+		return true, ts
+	default:
+		return false, ts
+	}
+}
+
+// success records a successful log request.
+func (b *breaker) success() []transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state == StateClosed {
+		return nil
+	}
+	return []transition{b.setState(StateClosed)}
+}
+
+// failure records a failed log request, which opens the breaker if it's a
+// failed trial or if the threshold of consecutive failures is reached.
+func (b *breaker) failure() []transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+		return []transition{b.setState(StateOpen)}
+	}
+	return nil
+}
+
+// release gives up the trial of a half-open breaker without recording an
+// outcome, e.g. when the trial log request was invalid.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// setState must be called with the lock held.
+func (b *breaker) setState(s State) transition {
+	t := transition{from: b.state, to: s}
+	b.state = s
+	b.trial = false
+	if s == StateOpen {
+		b.openedAt = b.now()
+	}
+	return t
+}
@@ -0,0 +1,403 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resilience provides a log processor that wraps a backend with
+// retries and a circuit breaker, so that transient backend failures don't
+// fail the audited requests.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+)
+
+const (
+	defaultMaxAttempts   = 3
+	defaultMinBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff    = 2 * time.Second
+	defaultJitterPercent = 10
+)
+
+// ErrCircuitOpen is returned when a log request is rejected because the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// DefaultRetryableCodes are the gRPC codes retried by default.
+var DefaultRetryableCodes = []codes.Code{
+	codes.Unavailable,
+	codes.ResourceExhausted,
+	codes.Aborted,
+	codes.DeadlineExceeded,
+}
+
+// StateChangeHook is called on every state transition of the circuit
+// breaker. Hooks run synchronously on the goroutine of the log request that
+// caused the transition, so they should return quickly.
+type StateChangeHook func(ctx context.Context, from, to State)
+
+// Processor is a log processor that retries the log requests that a backend
+// failed with a retryable gRPC code, with exponential backoff and jitter.
+// Optionally, a circuit breaker stops calling the backend after repeated
+// failures, and lets a trial log request through once the open timeout has
+// passed.
+type Processor struct {
+	backend audit.LogProcessor
+
+	maxAttempts    uint64
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	jitterPercent  uint64
+	retryableCodes map[codes.Code]struct{}
+
+	breaker *breaker
+	hooks   []StateChangeHook
+}
+
+// Option is the option to set up a resilience processor.
+type Option func(p *Processor) error
+
+// WithMaxAttempts sets how many times a log request is sent to the backend,
+// including the first attempt. The default is 3.
+func WithMaxAttempts(n uint64) Option {
+	return func(p *Processor) error {
+		if n == 0 {
+			return fmt.Errorf("max attempts must be positive")
+		}
+		p.maxAttempts = n
+		return nil
+	}
+}
+
+// WithBackoff sets the exponential backoff between attempts. The delay
+// starts at minBackoff, doubles after every attempt up to maxBackoff, and
+// varies by the given jitter percentage. The defaults are 100ms, 2s and 10%.
+func WithBackoff(minBackoff, maxBackoff time.Duration, jitterPercent uint64) Option {
+	return func(p *Processor) error {
+		if minBackoff <= 0 || maxBackoff < minBackoff {
+			return fmt.Errorf("invalid backoff [%s, %s]", minBackoff, maxBackoff)
+		}
+		if jitterPercent > 100 {
+			return fmt.Errorf("jitter percent must be at most 100, got %d", jitterPercent)
+		}
+		p.minBackoff = minBackoff
+		p.maxBackoff = maxBackoff
+		p.jitterPercent = jitterPercent
+		return nil
+	}
+}
+
+// WithRetryableCodes replaces the gRPC codes that are retried, see
+// DefaultRetryableCodes.
+func WithRetryableCodes(cs ...codes.Code) Option {
+	return func(p *Processor) error {
+		p.retryableCodes = make(map[codes.Code]struct{}, len(cs))
+		for _, c := range cs {
+			p.retryableCodes[c] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithCircuitBreaker enables the circuit breaker. It opens after the given
+// number of consecutive failed log requests, and half-opens after the open
+// timeout. Only failures with a retryable code count, since other failures
+// are caused by the log request rather than the backend.
+func WithCircuitBreaker(failureThreshold uint64, openTimeout time.Duration) Option {
+	return func(p *Processor) error {
+		if failureThreshold == 0 {
+			return fmt.Errorf("failure threshold must be positive")
+		}
+		if openTimeout <= 0 {
+			return fmt.Errorf("open timeout must be positive, got %s", openTimeout)
+		}
+		p.breaker = &breaker{
+			threshold:   failureThreshold,
+			openTimeout: openTimeout,
+			now:         time.Now,
+		}
+		return nil
+	}
+}
+
+// WithStateChangeHook adds a hook that observes the state transitions of the
+// circuit breaker.
+func WithStateChangeHook(h StateChangeHook) Option {
+	return func(p *Processor) error {
+		p.hooks = append(p.hooks, h)
+		return nil
+	}
+}
+
+// NewProcessor creates a new resilience processor wrapping the given
+// backend.
+//
+// E.g.
+//
+//	p, err := NewProcessor(backend,
+//		WithMaxAttempts(5),
+//		WithCircuitBreaker(10, 30*time.Second))
+//	if err != nil { ... }
+//	defer p.Stop()
+func NewProcessor(backend audit.LogProcessor, opts ...Option) (*Processor, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("backend must not be nil")
+	}
+
+	p := &Processor{
+		backend:       backend,
+		maxAttempts:   defaultMaxAttempts,
+		minBackoff:    defaultMinBackoff,
+		maxBackoff:    defaultMaxBackoff,
+		jitterPercent: defaultJitterPercent,
+	}
+	if err := WithRetryableCodes(DefaultRetryableCodes...)(p); err != nil {
+		return nil, fmt.Errorf("failed to set default retryable codes: %w", err)
+	}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to set option: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Process sends the log request to the backend, retrying retryable failures.
+// It returns ErrCircuitOpen without calling the backend when the circuit
+// breaker is open.
+func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	if p.breaker != nil {
+		ok, ts := p.breaker.allow()
+		p.runHooks(ctx, ts)
+		if !ok {
+			return fmt.Errorf("failed to send log request to backend: %w", ErrCircuitOpen)
+		}
+	}
+
+	err := p.processWithRetry(ctx, logReq)
+
+	if p.breaker != nil {
+		switch {
+		case err == nil:
+			p.runHooks(ctx, p.breaker.success())
+		case p.retryable(err):
+			p.runHooks(ctx, p.breaker.failure())
+		default:
+			p.breaker.release()
+		}
+	}
+	return err
+}
+
+// processWithRetry calls the backend until it succeeds, fails with a
+// non-retryable error, or runs out of attempts.
+func (p *Processor) processWithRetry(ctx context.Context, logReq *api.AuditLogRequest) error {
+	var lastErr error
+	err := retry.Do(ctx, p.newBackoff(), func(ctx context.Context) error {
+		lastErr = p.backend.Process(ctx, logReq)
+		if lastErr != nil && p.retryable(lastErr) {
+			return retry.RetryableError(lastErr)
+		}
+		return lastErr
+	})
+	// When the context is done between attempts, the retry library only
+	// returns the context error, so add the backend error back.
+	if err != nil && lastErr != nil && !errors.Is(err, lastErr) {
+		return fmt.Errorf("stopped retrying backend: %w: %w", err, lastErr)
+	}
+	return err
+}
+
+// ProcessBatch sends the log requests to the backend, retrying the log
+// requests that failed with a retryable error. A BatchLogProcessor backend
+// receives them with a single ProcessBatch call per attempt, and other
+// backends receive them one at a time with Process. The circuit breaker
+// counts each call as a single log request.
+func (p *Processor) ProcessBatch(ctx context.Context, logReqs []*api.AuditLogRequest) error {
+	_, err := p.processBatch(ctx, logReqs)
+	return err
+}
+
+// ProcessDiagnostics processes the log requests as ProcessBatch does, and
+// returns the diagnostics of the last attempt of each of them, see
+// audit.DiagnosticLogProcessor. There are no diagnostics unless the backend is
+// a DiagnosticLogProcessor.
+func (p *Processor) ProcessDiagnostics(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*api.Diagnostic, error) {
+	return p.processBatch(ctx, logReqs)
+}
+
+// processBatch implements ProcessBatch and ProcessDiagnostics. A single log
+// request is sent with Process, unless the backend returns diagnostics.
+func (p *Processor) processBatch(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*api.Diagnostic, error) {
+	_, withDiags := p.backend.(audit.DiagnosticLogProcessor)
+	bp, ok := p.backend.(audit.BatchLogProcessor)
+	if !ok || (len(logReqs) == 1 && !withDiags) {
+		errs := make([]error, len(logReqs))
+		var failed bool
+		for i, logReq := range logReqs {
+			errs[i] = p.Process(ctx, logReq)
+			failed = failed || errs[i] != nil
+		}
+		if failed {
+			return nil, &audit.BatchError{Errs: errs}
+		}
+		return nil, nil
+	}
+
+	if p.breaker != nil {
+		ok, ts := p.breaker.allow()
+		p.runHooks(ctx, ts)
+		if !ok {
+			return nil, fmt.Errorf("failed to send log requests to backend: %w", ErrCircuitOpen)
+		}
+	}
+
+	errs, diags := p.processBatchWithRetry(ctx, bp, logReqs)
+
+	var failed, retryableFailed, succeeded bool
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded = true
+		case p.retryable(err):
+			failed, retryableFailed = true, true
+		default:
+			failed = true
+		}
+	}
+	if p.breaker != nil {
+		switch {
+		case retryableFailed:
+			p.runHooks(ctx, p.breaker.failure())
+		case succeeded:
+			p.runHooks(ctx, p.breaker.success())
+		default:
+			p.breaker.release()
+		}
+	}
+	if failed {
+		return diags, &audit.BatchError{Errs: errs}
+	}
+	return diags, nil
+}
+
+// processBatchWithRetry calls the batch backend until all the log requests
+// succeeded or failed with a non-retryable error, or it runs out of attempts.
+// Only the log requests that failed with a retryable error are retried. It
+// returns the error of each log request, and the diagnostics of its last
+// attempt if the backend is a DiagnosticLogProcessor.
+func (p *Processor) processBatchWithRetry(ctx context.Context, bp audit.BatchLogProcessor, logReqs []*api.AuditLogRequest) ([]error, [][]*api.Diagnostic) {
+	errs := make([]error, len(logReqs))
+	diags := make([][]*api.Diagnostic, len(logReqs))
+	// The indexes of the log requests to send.
+	idx := make([]int, len(logReqs))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	err := retry.Do(ctx, p.newBackoff(), func(ctx context.Context) error {
+		reqs := make([]*api.AuditLogRequest, 0, len(idx))
+		for _, i := range idx {
+			reqs = append(reqs, logReqs[i])
+		}
+		var reqDiags [][]*api.Diagnostic
+		var err error
+		if dp, ok := bp.(audit.DiagnosticLogProcessor); ok {
+			reqDiags, err = dp.ProcessDiagnostics(ctx, reqs)
+		} else {
+			err = bp.ProcessBatch(ctx, reqs)
+		}
+
+		var batchErr *audit.BatchError
+		perEntry := errors.As(err, &batchErr) && len(batchErr.Errs) == len(reqs)
+		var next []int
+		for j, i := range idx {
+			errs[i] = err
+			if perEntry {
+				errs[i] = batchErr.Errs[j]
+			}
+			if len(reqDiags) == len(reqs) {
+				diags[i] = reqDiags[j]
+			}
+			if errs[i] != nil && p.retryable(errs[i]) {
+				next = append(next, i)
+			}
+		}
+		idx = next
+		if len(idx) > 0 {
+			return retry.RetryableError(errs[idx[0]])
+		}
+		return nil
+	})
+	// When the context is done between attempts, the retry library only
+	// returns the context error, so add it to the backend errors.
+	if err != nil && errors.Is(err, ctx.Err()) {
+		for _, i := range idx {
+			if errs[i] == nil {
+				// The log request was never sent.
+				errs[i] = fmt.Errorf("stopped retrying backend: %w", err)
+				continue
+			}
+			errs[i] = fmt.Errorf("stopped retrying backend: %w: %w", err, errs[i])
+		}
+	}
+	return errs, diags
+}
+
+// newBackoff returns the backoff between the attempts of a call.
+func (p *Processor) newBackoff() retry.Backoff {
+	b := retry.NewExponential(p.minBackoff)
+	b = retry.WithCappedDuration(p.maxBackoff, b)
+	if p.jitterPercent > 0 {
+		b = retry.WithJitterPercent(p.jitterPercent, b)
+	}
+	return retry.WithMaxRetries(p.maxAttempts-1, b)
+}
+
+// retryable reports whether the error has a retryable gRPC code. Skipped log
+// requests are never retried.
+func (p *Processor) retryable(err error) bool {
+	if errors.Is(err, auditerrors.ErrPreconditionFailed) {
+		return false
+	}
+	_, ok := p.retryableCodes[status.Code(err)]
+	return ok
+}
+
+func (p *Processor) runHooks(ctx context.Context, ts []transition) {
+	for _, t := range ts {
+		for _, h := range p.hooks {
+			h(ctx, t.from, t.to)
+		}
+	}
+}
+
+// Stop stops the wrapped backend, if it's stoppable.
+func (p *Processor) Stop() error {
+	if s, ok := p.backend.(audit.StoppableProcessor); ok {
+		if err := s.Stop(); err != nil {
+			return fmt.Errorf("failed to stop backend: %w", err)
+		}
+	}
+	return nil
+}
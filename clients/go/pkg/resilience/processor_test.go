@@ -0,0 +1,507 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// fakeBackend returns the given errors in order, then nil.
+type fakeBackend struct {
+	mu      sync.Mutex
+	errs    []error
+	calls   int
+	stopped bool
+}
+
+func (b *fakeBackend) Process(_ context.Context, _ *api.AuditLogRequest) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls++
+	if len(b.errs) == 0 {
+		return nil
+	}
+	err := b.errs[0]
+	b.errs = b.errs[1:]
+	return err
+}
+
+func (b *fakeBackend) Stop() error {
+	b.stopped = true
+	return nil
+}
+
+func repeat(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+var (
+	errUnavailable = fmt.Errorf("remote log processing failed: %w", status.Error(codes.Unavailable, "injected"))
+	errInvalidArg  = status.Error(codes.InvalidArgument, "injected")
+)
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "valid_options",
+			opts: []Option{
+				WithMaxAttempts(5),
+				WithBackoff(time.Millisecond, time.Second, 50),
+				WithRetryableCodes(codes.Unavailable),
+				WithCircuitBreaker(3, time.Minute),
+			},
+		},
+		{
+			name:          "invalid_max_attempts",
+			opts:          []Option{WithMaxAttempts(0)},
+			wantErrSubstr: "max attempts must be positive",
+		},
+		{
+			name:          "invalid_backoff",
+			opts:          []Option{WithBackoff(time.Second, time.Millisecond, 0)},
+			wantErrSubstr: "invalid backoff",
+		},
+		{
+			name:          "invalid_jitter",
+			opts:          []Option{WithBackoff(time.Millisecond, time.Second, 101)},
+			wantErrSubstr: "jitter percent must be at most 100",
+		},
+		{
+			name:          "invalid_failure_threshold",
+			opts:          []Option{WithCircuitBreaker(0, time.Minute)},
+			wantErrSubstr: "failure threshold must be positive",
+		},
+		{
+			name:          "invalid_open_timeout",
+			opts:          []Option{WithCircuitBreaker(1, 0)},
+			wantErrSubstr: "open timeout must be positive",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewProcessor(&fakeBackend{}, tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error substring: %v", diff)
+			}
+		})
+	}
+}
+
+func TestProcess_Retry(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		errs      []error
+		opts      []Option
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			wantCalls: 1,
+		},
+		{
+			name:      "retry_until_success",
+			errs:      repeat(errUnavailable, 2),
+			wantCalls: 3,
+		},
+		{
+			name:      "out_of_attempts",
+			errs:      repeat(errUnavailable, 5),
+			wantCalls: 3,
+			wantErr:   errUnavailable,
+		},
+		{
+			name:      "not_retryable",
+			errs:      []error{errInvalidArg},
+			wantCalls: 1,
+			wantErr:   errInvalidArg,
+		},
+		{
+			name:      "precondition_failed_not_retried",
+			errs:      []error{auditerrors.ErrPreconditionFailed},
+			wantCalls: 1,
+			wantErr:   auditerrors.ErrPreconditionFailed,
+		},
+		{
+			name:      "custom_retryable_codes",
+			errs:      []error{errInvalidArg, errUnavailable},
+			opts:      []Option{WithRetryableCodes(codes.InvalidArgument)},
+			wantCalls: 2,
+			wantErr:   errUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &fakeBackend{errs: tc.errs}
+			opts := append([]Option{WithBackoff(time.Millisecond, time.Millisecond, 0)}, tc.opts...)
+			p, err := NewProcessor(b, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := p.Process(t.Context(), testutil.NewRequest()); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Process() got error %v, want %v", err, tc.wantErr)
+			}
+			if b.calls != tc.wantCalls {
+				t.Errorf("backend got %d calls, want %d", b.calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestProcess_RetryContextDone(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBackend{errs: repeat(errUnavailable, 10)}
+	p, err := NewProcessor(b, WithMaxAttempts(10), WithBackoff(time.Hour, time.Hour, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	err = p.Process(ctx, testutil.NewRequest())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Process() got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("Process() got error %v, want wrapped %v", err, errUnavailable)
+	}
+}
+
+func TestProcess_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	type transition struct {
+		From, To State
+	}
+
+	var mu sync.Mutex
+	now := time.Unix(0, 0)
+	var gotTransitions []transition
+
+	b := &fakeBackend{}
+	p, err := NewProcessor(b,
+		WithMaxAttempts(1),
+		WithCircuitBreaker(2, time.Minute),
+		WithStateChangeHook(func(_ context.Context, from, to State) {
+			gotTransitions = append(gotTransitions, transition{from, to})
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.breaker.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	steps := []struct {
+		name      string
+		errs      []error
+		advance   time.Duration
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "non_retryable_failure_not_counted", errs: []error{errInvalidArg}, wantErr: errInvalidArg, wantCalls: 1},
+		{name: "first_failure", errs: []error{errUnavailable}, wantErr: errUnavailable, wantCalls: 2},
+		{name: "second_failure_opens", errs: []error{errUnavailable}, wantErr: errUnavailable, wantCalls: 3},
+		{name: "open_rejects", wantErr: ErrCircuitOpen, wantCalls: 3},
+		{name: "still_open", advance: 30 * time.Second, wantErr: ErrCircuitOpen, wantCalls: 3},
+		{name: "failed_trial_reopens", advance: 30 * time.Second, errs: []error{errUnavailable}, wantErr: errUnavailable, wantCalls: 4},
+		{name: "reopened_rejects", wantErr: ErrCircuitOpen, wantCalls: 4},
+		{name: "successful_trial_closes", advance: time.Minute, wantCalls: 5},
+		{name: "closed", wantCalls: 6},
+	}
+	for _, s := range steps {
+		advance(s.advance)
+		b.errs = s.errs
+		if err := p.Process(t.Context(), testutil.NewRequest()); !errors.Is(err, s.wantErr) {
+			t.Errorf("%s: Process() got error %v, want %v", s.name, err, s.wantErr)
+		}
+		if b.calls != s.wantCalls {
+			t.Errorf("%s: backend got %d calls, want %d", s.name, b.calls, s.wantCalls)
+		}
+	}
+
+	wantTransitions := []transition{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}
+	if diff := cmp.Diff(wantTransitions, gotTransitions); diff != "" {
+		t.Errorf("state transitions got diff (-want, +got): %v", diff)
+	}
+}
+
+// fakeBatchBackend returns the given batch results in order, then nil. A
+// result is either a whole batch error or the errors of each log request.
+type fakeBatchBackend struct {
+	fakeBackend
+
+	results    []batchResult
+	batchSizes []int
+}
+
+type batchResult struct {
+	err  error
+	errs []error
+}
+
+func (b *fakeBatchBackend) ProcessBatch(_ context.Context, logReqs []*api.AuditLogRequest) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batchSizes = append(b.batchSizes, len(logReqs))
+	if len(b.results) == 0 {
+		return nil
+	}
+	r := b.results[0]
+	b.results = b.results[1:]
+	if r.errs != nil {
+		return &audit.BatchError{Errs: r.errs}
+	}
+	return r.err
+}
+
+// fakeDiagnosticBackend is a fakeBatchBackend that returns its diagnostics
+// for every log request.
+type fakeDiagnosticBackend struct {
+	fakeBatchBackend
+
+	diagnostics []*api.Diagnostic
+}
+
+func (b *fakeDiagnosticBackend) ProcessDiagnostics(ctx context.Context, logReqs []*api.AuditLogRequest) ([][]*api.Diagnostic, error) {
+	diags := make([][]*api.Diagnostic, len(logReqs))
+	for i := range diags {
+		diags[i] = b.diagnostics
+	}
+	return diags, b.ProcessBatch(ctx, logReqs)
+}
+
+func TestProcessBatch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name           string
+		results        []batchResult
+		wantBatchSizes []int
+		wantErrs       []error
+	}{
+		{
+			name:           "success",
+			wantBatchSizes: []int{3},
+			wantErrs:       []error{nil, nil, nil},
+		},
+		{
+			name: "retry_retryable_entries",
+			results: []batchResult{
+				{errs: []error{nil, errUnavailable, errInvalidArg}},
+			},
+			wantBatchSizes: []int{3, 1},
+			wantErrs:       []error{nil, nil, errInvalidArg},
+		},
+		{
+			name: "retry_whole_batch",
+			results: []batchResult{
+				{err: errUnavailable},
+			},
+			wantBatchSizes: []int{3, 3},
+			wantErrs:       []error{nil, nil, nil},
+		},
+		{
+			name: "out_of_attempts",
+			results: []batchResult{
+				{errs: []error{errUnavailable, nil, nil}},
+				{errs: []error{errUnavailable}},
+				{errs: []error{errUnavailable}},
+			},
+			wantBatchSizes: []int{3, 1, 1},
+			wantErrs:       []error{errUnavailable, nil, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &fakeBatchBackend{results: tc.results}
+			p, err := NewProcessor(b, WithBackoff(time.Millisecond, time.Millisecond, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reqs := []*api.AuditLogRequest{testutil.NewRequest(), testutil.NewRequest(), testutil.NewRequest()}
+			err = p.ProcessBatch(t.Context(), reqs)
+
+			gotErrs := make([]error, len(reqs))
+			var batchErr *audit.BatchError
+			if errors.As(err, &batchErr) {
+				gotErrs = batchErr.Errs
+			} else if err != nil {
+				t.Fatalf("ProcessBatch() got error %v, want *audit.BatchError", err)
+			}
+			for i := range tc.wantErrs {
+				if !errors.Is(gotErrs[i], tc.wantErrs[i]) {
+					t.Errorf("ProcessBatch() entry %d got error %v, want %v", i, gotErrs[i], tc.wantErrs[i])
+				}
+			}
+			if diff := cmp.Diff(tc.wantBatchSizes, b.batchSizes); diff != "" {
+				t.Errorf("batch sizes got diff (-want, +got): %v", diff)
+			}
+			if b.calls != 0 {
+				t.Errorf("backend got %d Process calls, want 0", b.calls)
+			}
+		})
+	}
+}
+
+func TestProcessBatch_NotBatchBackend(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBackend{errs: []error{errInvalidArg}}
+	p, err := NewProcessor(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.ProcessBatch(t.Context(), []*api.AuditLogRequest{testutil.NewRequest(), testutil.NewRequest()})
+	var batchErr *audit.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("ProcessBatch() got error %v, want *audit.BatchError", err)
+	}
+	if !errors.Is(batchErr.Errs[0], errInvalidArg) || batchErr.Errs[1] != nil {
+		t.Errorf("ProcessBatch() got errors %v, want [%v <nil>]", batchErr.Errs, errInvalidArg)
+	}
+	if b.calls != 2 {
+		t.Errorf("backend got %d calls, want 2", b.calls)
+	}
+}
+
+func TestProcessBatch_ClientLogBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	b := &fakeBatchBackend{}
+	p, err := NewProcessor(b, WithCircuitBreaker(3, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := audit.NewClient(ctx, audit.WithBackend(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := []*api.AuditLogRequest{testutil.NewRequest(), testutil.NewRequest(), testutil.NewRequest()}
+	for i, err := range c.LogBatch(ctx, reqs) {
+		if err != nil {
+			t.Errorf("LogBatch() entry %d unexpected error: %v", i, err)
+		}
+	}
+	if diff := cmp.Diff([]int{3}, b.batchSizes); diff != "" {
+		t.Errorf("batch sizes got diff (-want, +got): %v", diff)
+	}
+}
+
+func TestProcessDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	warning := &api.Diagnostic{Severity: api.Diagnostic_WARNING, Message: "injected warning"}
+	b := &fakeDiagnosticBackend{
+		fakeBatchBackend: fakeBatchBackend{results: []batchResult{{errs: []error{nil, errUnavailable}}}},
+		diagnostics:      []*api.Diagnostic{warning},
+	}
+	p, err := NewProcessor(b, WithBackoff(time.Millisecond, time.Millisecond, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := []*api.AuditLogRequest{testutil.NewRequest(), testutil.NewRequest()}
+	diags, err := p.ProcessDiagnostics(t.Context(), reqs)
+	if err != nil {
+		t.Fatalf("ProcessDiagnostics() unexpected error: %v", err)
+	}
+	want := [][]*api.Diagnostic{{warning}, {warning}}
+	if diff := cmp.Diff(want, diags, protocmp.Transform()); diff != "" {
+		t.Errorf("ProcessDiagnostics() diagnostics (-want, +got): %v", diff)
+	}
+	// The retried log request is sent alone.
+	if diff := cmp.Diff([]int{2, 1}, b.batchSizes); diff != "" {
+		t.Errorf("batch sizes got diff (-want, +got): %v", diff)
+	}
+}
+
+func TestStop(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBackend{}
+	p, err := NewProcessor(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error: %v", err)
+	}
+	if !b.stopped {
+		t.Errorf("expected wrapped backend to be stopped")
+	}
+}
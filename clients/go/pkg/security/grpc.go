@@ -51,11 +51,16 @@ func (j *FromRawJWT) RequestPrincipal(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("gRPC metadata in incoming context is missing")
 	}
 
-	idToken, err := j.findJWT(md)
+	// Keys in grpc metadata are all lowercases.
+	idToken, err := j.findJWT(md.Get)
 	if err != nil {
 		return "", err
 	}
+	return principalFromJWT(idToken)
+}
 
+// principalFromJWT returns the email claim of the JWT, without verifying it.
+func principalFromJWT(idToken string) (string, error) {
 	token, err := jwt.ParseString(idToken, jwt.WithVerify(false))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse jwt: %w", err)
@@ -77,11 +82,11 @@ func (j *FromRawJWT) RequestPrincipal(ctx context.Context) (string, error) {
 	return email, nil
 }
 
-// findJWT looks for a JWT from the gRPC metadata that matches the rules.
-func (j *FromRawJWT) findJWT(md grpcmetadata.MD) (string, error) {
+// findJWT looks for a JWT that matches the rules, in the values of each key
+// returned by the given lookup, e.g. in gRPC metadata or HTTP headers.
+func (j *FromRawJWT) findJWT(values func(key string) []string) (string, error) {
 	for _, fj := range j.FromRawJWT {
-		// Keys in grpc metadata are all lowercases.
-		vals := md.Get(fj.Key)
+		vals := values(fj.Key)
 		if len(vals) == 0 {
 			continue
 		}
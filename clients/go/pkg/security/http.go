@@ -0,0 +1,36 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"net/http"
+)
+
+// HTTPContext is an interface that retrieves the principal from the headers
+// of an HTTP request, like GRPCContext does for gRPC.
+type HTTPContext interface {
+	HTTPRequestPrincipal(*http.Request) (string, error)
+}
+
+// HTTPRequestPrincipal extracts the JWT principal from the headers of the HTTP
+// request. Header keys are matched case insensitively. This method does not
+// verify the JWT.
+func (j *FromRawJWT) HTTPRequestPrincipal(r *http.Request) (string, error) {
+	idToken, err := j.findJWT(r.Header.Values)
+	if err != nil {
+		return "", err
+	}
+	return principalFromJWT(idToken)
+}
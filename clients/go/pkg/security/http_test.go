@@ -0,0 +1,96 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+func TestFromRawJWT_HTTPRequestPrincipal(t *testing.T) {
+	t.Parallel()
+
+	jwt := testutil.JWTFromClaims(t, map[string]interface{}{
+		"email": "user@example.com",
+	})
+
+	tests := []struct {
+		name          string
+		headers       http.Header
+		fromRawJWT    []*v1alpha1.FromRawJWT
+		want          string
+		wantErrSubstr string
+	}{
+		{
+			name:    "valid_jwt",
+			headers: http.Header{"Authorization": []string{"Bearer " + jwt}},
+			fromRawJWT: []*v1alpha1.FromRawJWT{{
+				Key:    "authorization",
+				Prefix: "Bearer ",
+			}},
+			want: "user@example.com",
+		},
+		{
+			name:    "multi_jwts",
+			headers: http.Header{"X-Jwt-Assertion": []string{jwt}},
+			fromRawJWT: []*v1alpha1.FromRawJWT{{
+				Key:    "authorization",
+				Prefix: "Bearer ",
+			}, {
+				Key: "x-jwt-assertion",
+			}},
+			want: "user@example.com",
+		},
+		{
+			name:    "error_from_inexistent_jwt_key",
+			headers: http.Header{},
+			fromRawJWT: []*v1alpha1.FromRawJWT{{
+				Key:    "authorization",
+				Prefix: "Bearer ",
+			}},
+			wantErrSubstr: "no JWT found matching rules",
+		},
+		{
+			name:    "error_from_unparsable_jwt",
+			headers: http.Header{"Authorization": []string{"bananas"}},
+			fromRawJWT: []*v1alpha1.FromRawJWT{{
+				Key: "authorization",
+			}},
+			wantErrSubstr: "failed to parse jwt",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header = tc.headers
+
+			j := &FromRawJWT{FromRawJWT: tc.fromRawJWT}
+			got, err := j.HTTPRequestPrincipal(r)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("j.HTTPRequestPrincipal() got unexpected error substring: %v", diff)
+			}
+			if got != tc.want {
+				t.Errorf("j.HTTPRequestPrincipal() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,680 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spool provides a log processor that durably spools audit log
+// requests to a local directory before handing them to another backend, so
+// that short backend outages don't fail the audited requests.
+package spool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/pkg/logging"
+)
+
+const (
+	defaultMaxBytes        = 1 << 30
+	defaultMaxSegmentBytes = 16 << 20
+	defaultMinBackoff      = 100 * time.Millisecond
+	defaultMaxBackoff      = time.Minute
+	defaultFlushTimeout    = 10 * time.Second
+)
+
+var (
+	// ErrFull is returned when a log request doesn't fit in the spool
+	// because the spool reached its size limit.
+	ErrFull = errors.New("spool is full")
+
+	// ErrStopped is returned when a log request is sent to a stopped spool.
+	ErrStopped = errors.New("spool is stopped")
+)
+
+// DefaultPermanentCodes are the gRPC codes of the backend failures that
+// replaying would never fix, e.g. a webhook answering with an HTTP 4xx.
+var DefaultPermanentCodes = []codes.Code{
+	codes.InvalidArgument,
+	codes.NotFound,
+	codes.PermissionDenied,
+	codes.FailedPrecondition,
+	codes.OutOfRange,
+	codes.Unimplemented,
+}
+
+// Processor is a log processor that wraps a backend with a local write-ahead
+// spool. Process returns once the log request is written and synced to disk,
+// and the spooled log requests are replayed to the wrapped backend in order
+// by a background goroutine, which retries with backoff while the backend is
+// failing. Spooled log requests survive restarts of the process.
+type Processor struct {
+	backend audit.LogProcessor
+	dir     string
+
+	maxBytes        int64
+	maxSegmentBytes int64
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+	flushTimeout    time.Duration
+	permanentCodes  map[codes.Code]struct{}
+
+	// mu guards the writer state and the spool size, which are shared with
+	// the replay goroutine.
+	mu          sync.Mutex
+	stopped     bool
+	writer      *os.File
+	writeSeq    uint64
+	writeOffset int64
+	totalBytes  int64
+
+	// The reader state is only used by the replay goroutine.
+	reader     *os.File
+	readSeq    uint64
+	readOffset int64
+
+	notify   chan struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	cancel   context.CancelFunc
+	flushErr error
+}
+
+// Option is the option to set up a spool processor.
+type Option func(p *Processor) error
+
+// WithMaxBytes limits the total size of the spool on disk. Once the limit is
+// reached, Process returns ErrFull until spooled log requests are replayed.
+// The default is 1 GiB.
+func WithMaxBytes(n int64) Option {
+	return func(p *Processor) error {
+		if n <= 0 {
+			return fmt.Errorf("max bytes must be positive, got %d", n)
+		}
+		p.maxBytes = n
+		return nil
+	}
+}
+
+// WithMaxSegmentBytes sets the size after which the spool starts a new
+// segment file. Replayed segments are deleted as a whole. The default is
+// 16 MiB.
+func WithMaxSegmentBytes(n int64) Option {
+	return func(p *Processor) error {
+		if n <= 0 {
+			return fmt.Errorf("max segment bytes must be positive, got %d", n)
+		}
+		p.maxSegmentBytes = n
+		return nil
+	}
+}
+
+// WithReplayBackoff sets the exponential backoff used to retry replaying to
+// a failing backend. The defaults are 100ms and 1m.
+func WithReplayBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(p *Processor) error {
+		if minBackoff <= 0 || maxBackoff < minBackoff {
+			return fmt.Errorf("invalid replay backoff [%s, %s]", minBackoff, maxBackoff)
+		}
+		p.minBackoff = minBackoff
+		p.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
+// WithFlushTimeout bounds how long Stop tries to replay the remaining
+// spooled log requests. The default is 10s.
+func WithFlushTimeout(d time.Duration) Option {
+	return func(p *Processor) error {
+		p.flushTimeout = d
+		return nil
+	}
+}
+
+// WithPermanentCodes replaces the gRPC codes of the backend failures that are
+// not retried, see DefaultPermanentCodes. The log requests failing with them
+// are moved to the dead letter file instead.
+func WithPermanentCodes(cs ...codes.Code) Option {
+	return func(p *Processor) error {
+		p.permanentCodes = make(map[codes.Code]struct{}, len(cs))
+		for _, c := range cs {
+			p.permanentCodes[c] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// NewProcessor creates a spool processor in the given directory that replays
+// log requests to the given backend. The directory is created if it doesn't
+// exist, and log requests spooled by a previous processor in the same
+// directory are replayed.
+//
+// E.g.
+//
+//	p, err := spool.NewProcessor(ctx, "/var/spool/lumberjack", remoteProcessor)
+//	if err != nil { ... }
+//	defer p.Stop()
+func NewProcessor(ctx context.Context, dir string, backend audit.LogProcessor, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		backend:         backend,
+		dir:             dir,
+		maxBytes:        defaultMaxBytes,
+		maxSegmentBytes: defaultMaxSegmentBytes,
+		minBackoff:      defaultMinBackoff,
+		maxBackoff:      defaultMaxBackoff,
+		flushTimeout:    defaultFlushTimeout,
+		notify:          make(chan struct{}, 1),
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+	if err := WithPermanentCodes(DefaultPermanentCodes...)(p); err != nil {
+		return nil, fmt.Errorf("failed to set default permanent codes: %w", err)
+	}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply spool options: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, spoolDirFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	if err := p.recover(ctx); err != nil {
+		return nil, fmt.Errorf("failed to recover spool: %w", err)
+	}
+
+	// The replay goroutine outlives the given context, but keeps its values,
+	// e.g. the logger.
+	rctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.cancel = cancel
+	go p.replay(rctx)
+	return p, nil
+}
+
+// Process durably spools the log request and returns. The log request is
+// replayed to the wrapped backend in the background.
+func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	b, err := proto.Marshal(logReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log request: %w", err)
+	}
+	if len(b) > maxRecordSize {
+		return fmt.Errorf("log request of %d bytes exceeds the max spool record size of %d bytes", len(b), maxRecordSize)
+	}
+
+	if err := p.append(encodeRecord(b)); err != nil {
+		return fmt.Errorf("failed to spool log request: %w", err)
+	}
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Stop stops accepting log requests and tries to replay the remaining spooled
+// log requests within the flush timeout. Log requests that could not be
+// replayed stay on disk and are replayed by the next processor using the same
+// directory. The wrapped backend is stopped as well.
+func (p *Processor) Stop() error {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return nil
+	}
+	p.stopped = true
+	p.mu.Unlock()
+
+	close(p.stopCh)
+	timer := time.NewTimer(p.flushTimeout)
+	defer timer.Stop()
+	select {
+	case <-p.doneCh:
+	case <-timer.C:
+		p.cancel()
+		<-p.doneCh
+	}
+	p.cancel()
+
+	var merr error
+	if p.flushErr != nil {
+		merr = errors.Join(merr, fmt.Errorf("failed to flush spool, remaining log requests are kept on disk: %w", p.flushErr))
+	}
+	if p.reader != nil {
+		if err := p.reader.Close(); err != nil {
+			merr = errors.Join(merr, fmt.Errorf("failed to close spool reader: %w", err))
+		}
+	}
+	p.mu.Lock()
+	if err := p.writer.Close(); err != nil {
+		merr = errors.Join(merr, fmt.Errorf("failed to close spool writer: %w", err))
+	}
+	p.mu.Unlock()
+
+	if stoppable, ok := p.backend.(audit.StoppableProcessor); ok {
+		if err := stoppable.Stop(); err != nil {
+			merr = errors.Join(merr, fmt.Errorf("failed to stop spooled backend: %w", err))
+		}
+	}
+	return merr
+}
+
+// recover restores the spool state from the directory. A torn record at the
+// end of the last segment, e.g. from a crash during a write, is truncated. If
+// the last segment is corrupted before its end, it's left as is for the replay
+// to quarantine it, and a new segment is started.
+func (p *Processor) recover(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	seqs, err := listSegments(p.dir)
+	if err != nil {
+		return err
+	}
+	cpSeq, cpOffset, err := p.readCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Resume replaying from the checkpoint, or from the first segment after
+	// it if that segment is gone.
+	p.readSeq = cpSeq
+	p.readOffset = cpOffset
+	sizes := make(map[uint64]int64, len(seqs))
+	for _, seq := range seqs {
+		if seq < p.readSeq {
+			// The segment was replayed, but not removed.
+			if err := os.Remove(p.segmentPath(seq)); err != nil {
+				return fmt.Errorf("failed to remove replayed segment: %w", err)
+			}
+			continue
+		}
+		if seq > p.readSeq && len(sizes) == 0 {
+			p.readSeq = seq
+			p.readOffset = 0
+		}
+		fi, err := os.Stat(p.segmentPath(seq))
+		if err != nil {
+			return fmt.Errorf("failed to stat segment: %w", err)
+		}
+		sizes[seq] = fi.Size()
+		p.totalBytes += fi.Size()
+	}
+
+	if len(sizes) == 0 {
+		p.writeSeq = max(p.readSeq, 1)
+		p.readSeq = p.writeSeq
+		p.readOffset = 0
+		return p.createSegment()
+	}
+
+	last := seqs[len(seqs)-1]
+	f, err := os.OpenFile(p.segmentPath(last), os.O_RDWR, segmentFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open segment: %w", err)
+	}
+	end, err := scanSegment(f, sizes[last])
+	switch {
+	case errors.Is(err, errCorruptRecord):
+		logger.ErrorContext(ctx, "spool segment is corrupted before its end, starting a new segment",
+			"segment", segmentName(last),
+			"offset", end,
+			"error", err)
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to close segment: %w", err)
+		}
+		p.writeSeq = last + 1
+		if err := p.createSegment(); err != nil {
+			return err
+		}
+	case err != nil:
+		return errors.Join(fmt.Errorf("failed to scan segment: %w", err), f.Close())
+	case end < sizes[last]:
+		logger.WarnContext(ctx, "truncating torn records at the end of the spool",
+			"segment", segmentName(last),
+			"offset", end,
+			"size", sizes[last])
+		if err := f.Truncate(end); err != nil {
+			return errors.Join(fmt.Errorf("failed to truncate segment: %w", err), f.Close())
+		}
+		if err := f.Sync(); err != nil {
+			return errors.Join(fmt.Errorf("failed to sync segment: %w", err), f.Close())
+		}
+		p.totalBytes -= sizes[last] - end
+		sizes[last] = end
+		fallthrough
+	default:
+		p.writer = f
+		p.writeSeq = last
+		p.writeOffset = end
+	}
+
+	if p.readOffset > sizes[p.readSeq] {
+		p.readOffset = 0
+	}
+	return nil
+}
+
+// append writes the record to the current segment and syncs it to disk.
+func (p *Processor) append(rec []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return ErrStopped
+	}
+	size := int64(len(rec))
+	if p.totalBytes+size > p.maxBytes {
+		return ErrFull
+	}
+	if p.writeOffset > 0 && p.writeOffset+size > p.maxSegmentBytes {
+		if err := p.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.writer.WriteAt(rec, p.writeOffset); err != nil {
+		// Drop the partially written record to keep the segment valid.
+		return errors.Join(fmt.Errorf("failed to write record: %w", err), p.writer.Truncate(p.writeOffset))
+	}
+	if err := p.writer.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync record: %w", err), p.writer.Truncate(p.writeOffset))
+	}
+	p.writeOffset += size
+	p.totalBytes += size
+	return nil
+}
+
+// rotateLocked closes the current segment and starts the next one. The
+// caller must hold p.mu.
+func (p *Processor) rotateLocked() error {
+	if err := p.writer.Close(); err != nil {
+		return fmt.Errorf("failed to close segment: %w", err)
+	}
+	p.writeSeq++
+	return p.createSegment()
+}
+
+// createSegment creates the segment file for p.writeSeq and opens it for
+// writing.
+func (p *Processor) createSegment() error {
+	f, err := os.OpenFile(p.segmentPath(p.writeSeq), os.O_RDWR|os.O_CREATE|os.O_EXCL, segmentFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create segment: %w", err)
+	}
+	if err := syncDir(p.dir); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	p.writer = f
+	p.writeOffset = 0
+	return nil
+}
+
+// replay delivers the spooled log requests to the backend until the
+// processor is stopped, then tries to deliver the remaining ones once more.
+func (p *Processor) replay(ctx context.Context) {
+	defer close(p.doneCh)
+	logger := logging.FromContext(ctx)
+
+	backoff := p.newBackoff()
+	for {
+		notifyCh := p.notify
+		var retryCh <-chan time.Time
+		if err := p.deliverPending(ctx); err != nil {
+			d, _ := backoff.Next()
+			logger.WarnContext(ctx, "failed to replay spooled audit logs; retrying",
+				"retry_in", d,
+				"error", err)
+			// Wait for the backoff rather than for new log requests.
+			notifyCh = nil
+			retryCh = time.After(d)
+		} else {
+			backoff = p.newBackoff()
+		}
+
+		select {
+		case <-p.stopCh:
+			p.flushErr = p.deliverPending(ctx)
+			return
+		case <-notifyCh:
+		case <-retryCh:
+		}
+	}
+}
+
+func (p *Processor) newBackoff() retry.Backoff {
+	b := retry.NewExponential(p.minBackoff)
+	b = retry.WithJitterPercent(10, b)
+	return retry.WithCappedDuration(p.maxBackoff, b)
+}
+
+// deliverPending delivers the spooled log requests in order until there are
+// none left or the backend fails.
+func (p *Processor) deliverPending(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("replay interrupted: %w", err)
+		}
+
+		payload, next, err := p.nextRecord(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		logReq := &api.AuditLogRequest{}
+		if err := proto.Unmarshal(payload, logReq); err != nil {
+			logger.ErrorContext(ctx, "dropping spooled audit log request that cannot be decoded",
+				"error", err)
+		} else if err := p.backend.Process(ctx, logReq); err != nil {
+			switch {
+			case errors.Is(err, auditerrors.ErrPreconditionFailed):
+				// The backend chose to skip the log request.
+			case errors.Is(err, auditerrors.ErrInvalidRequest), p.permanent(err):
+				// Retrying would never succeed, so the log request is kept
+				// aside for inspection rather than blocking the replay.
+				logger.ErrorContext(ctx, "moving spooled audit log request rejected by backend to dead letter file",
+					"file", deadLetterFile,
+					"error", err)
+				if err := appendDeadLetter(p.dir, payload); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("failed to replay spooled log request: %w", err)
+			}
+		}
+
+		p.readOffset = next
+		if err := p.writeCheckpoint(); err != nil {
+			return err
+		}
+	}
+}
+
+// permanent reports whether the backend failure is permanent, see
+// WithPermanentCodes.
+func (p *Processor) permanent(err error) bool {
+	_, ok := p.permanentCodes[status.Code(err)]
+	return ok
+}
+
+// nextRecord returns the next spooled record to deliver and the offset after
+// it, or io.EOF if all spooled records were delivered. Fully delivered
+// segments are removed, and corrupted segments are quarantined.
+func (p *Processor) nextRecord(ctx context.Context) ([]byte, int64, error) {
+	for {
+		p.mu.Lock()
+		writeSeq, writeOffset := p.writeSeq, p.writeOffset
+		p.mu.Unlock()
+
+		if p.readSeq == writeSeq && p.readOffset >= writeOffset {
+			return nil, 0, io.EOF
+		}
+
+		if p.reader == nil {
+			f, err := os.Open(p.segmentPath(p.readSeq))
+			if errors.Is(err, fs.ErrNotExist) && p.readSeq < writeSeq {
+				p.readSeq++
+				p.readOffset = 0
+				continue
+			}
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to open segment: %w", err)
+			}
+			p.reader = f
+		}
+
+		payload, next, err := readRecordAt(p.reader, p.readOffset)
+		switch {
+		case err == nil:
+			return payload, next, nil
+		case errors.Is(err, io.EOF) && p.readSeq < writeSeq:
+			if err := p.finishSegment(false); err != nil {
+				return nil, 0, err
+			}
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, errCorruptRecord):
+			logging.FromContext(ctx).ErrorContext(ctx, "quarantining corrupted spool segment",
+				"segment", segmentName(p.readSeq),
+				"error", err)
+			if p.readSeq == writeSeq {
+				// Stop writing to the corrupted segment first.
+				p.mu.Lock()
+				err := p.rotateLocked()
+				p.mu.Unlock()
+				if err != nil {
+					return nil, 0, err
+				}
+			}
+			if err := p.finishSegment(true); err != nil {
+				return nil, 0, err
+			}
+		default:
+			return nil, 0, fmt.Errorf("failed to read spooled record: %w", err)
+		}
+	}
+}
+
+// finishSegment removes, or quarantines, the segment being read and moves on
+// to the next one.
+func (p *Processor) finishSegment(quarantine bool) error {
+	fi, err := p.reader.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat segment: %w", err)
+	}
+	if err := p.reader.Close(); err != nil {
+		return fmt.Errorf("failed to close segment: %w", err)
+	}
+	p.reader = nil
+
+	if quarantine {
+		err = quarantineSegment(p.dir, p.readSeq)
+	} else {
+		err = os.Remove(p.segmentPath(p.readSeq))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove segment: %w", err)
+	}
+
+	p.mu.Lock()
+	p.totalBytes -= fi.Size()
+	p.mu.Unlock()
+
+	p.readSeq++
+	p.readOffset = 0
+	return p.writeCheckpoint()
+}
+
+// readCheckpoint returns the position of the next record to deliver, as
+// persisted by writeCheckpoint. An unreadable checkpoint restarts the replay
+// from the first segment, which may deliver some log requests twice.
+func (p *Processor) readCheckpoint(ctx context.Context) (uint64, int64, error) {
+	b, err := os.ReadFile(filepath.Join(p.dir, checkpointFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var seq uint64
+	var offset int64
+	if _, err := fmt.Sscanf(string(b), "%d %d", &seq, &offset); err != nil || offset < 0 {
+		logging.FromContext(ctx).WarnContext(ctx, "ignoring invalid spool checkpoint",
+			"checkpoint", string(b),
+			"error", err)
+		return 0, 0, nil
+	}
+	return seq, offset, nil
+}
+
+// writeCheckpoint atomically and durably persists the position of the next
+// record to deliver: the checkpoint is written to a temporary file, which is
+// synced before it replaces the previous checkpoint, and the directory is
+// synced so that the rename survives a crash.
+func (p *Processor) writeCheckpoint() error {
+	path := filepath.Join(p.dir, checkpointFile)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, segmentFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%d %d\n", p.readSeq, p.readOffset); err != nil {
+		return errors.Join(fmt.Errorf("failed to write checkpoint: %w", err), f.Close())
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync checkpoint: %w", err), f.Close())
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return syncDir(p.dir)
+}
+
+func (p *Processor) segmentPath(seq uint64) string {
+	return filepath.Join(p.dir, segmentName(seq))
+}
+
+// syncDir syncs the directory so that newly created files survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open spool directory: %w", err)
+	}
+	if err := d.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync spool directory: %w", err), d.Close())
+	}
+	if err := d.Close(); err != nil {
+		return fmt.Errorf("failed to close spool directory: %w", err)
+	}
+	return nil
+}
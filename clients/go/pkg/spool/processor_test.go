@@ -0,0 +1,495 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/pkg/logging"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// fakeBackend records the method names of the delivered log requests. It
+// fails the first `failures` calls, or all calls if failures is negative, and
+// rejects the log requests of the methods in reject with the given error.
+type fakeBackend struct {
+	mu       sync.Mutex
+	failures int
+	reject   map[string]error
+	got      []string
+	stopped  bool
+}
+
+func (b *fakeBackend) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures != 0 {
+		b.failures--
+		return status.Error(codes.Unavailable, "injected error")
+	}
+	if err, ok := b.reject[logReq.GetPayload().GetMethodName()]; ok {
+		return err
+	}
+	b.got = append(b.got, logReq.GetPayload().GetMethodName())
+	return nil
+}
+
+func (b *fakeBackend) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopped = true
+	return nil
+}
+
+func (b *fakeBackend) delivered() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.got...)
+}
+
+// spoolRequests spools log requests with the method names m0, m1, ...
+func spoolRequests(tb testing.TB, p *Processor, n int) []string {
+	tb.Helper()
+
+	var methods []string
+	for i := range n {
+		m := fmt.Sprintf("m%d", i)
+		if err := p.Process(tb.Context(), testutil.NewRequest(testutil.WithMethodName(m))); err != nil {
+			tb.Fatalf("Process() unexpected error: %v", err)
+		}
+		methods = append(methods, m)
+	}
+	return methods
+}
+
+// waitForDelivered waits until the backend received n log requests.
+func waitForDelivered(tb testing.TB, b *fakeBackend, n int) {
+	tb.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(b.delivered()) < n {
+		if time.Now().After(deadline) {
+			tb.Fatalf("timed out waiting for %d delivered log requests, got %d", n, len(b.delivered()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "valid_options",
+			opts: []Option{
+				WithMaxBytes(1 << 20),
+				WithMaxSegmentBytes(1 << 10),
+				WithReplayBackoff(time.Millisecond, time.Second),
+				WithFlushTimeout(time.Second),
+				WithPermanentCodes(codes.InvalidArgument),
+			},
+		},
+		{
+			name:          "invalid_max_bytes",
+			opts:          []Option{WithMaxBytes(0)},
+			wantErrSubstr: "max bytes must be positive",
+		},
+		{
+			name:          "invalid_max_segment_bytes",
+			opts:          []Option{WithMaxSegmentBytes(-1)},
+			wantErrSubstr: "max segment bytes must be positive",
+		},
+		{
+			name:          "invalid_backoff",
+			opts:          []Option{WithReplayBackoff(time.Second, time.Millisecond)},
+			wantErrSubstr: "invalid replay backoff",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewProcessor(t.Context(), t.TempDir(), &fakeBackend{}, tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error substring: %v", diff)
+			}
+			if err == nil {
+				if err := p.Stop(); err != nil {
+					t.Errorf("Stop() unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessor_ReplaysInOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	cases := []struct {
+		name     string
+		failures int
+		opts     []Option
+	}{
+		{
+			name: "healthy_backend",
+		},
+		{
+			name:     "recovering_backend",
+			failures: 3,
+		},
+		{
+			name: "many_segments",
+			opts: []Option{WithMaxSegmentBytes(100)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			b := &fakeBackend{failures: tc.failures}
+			opts := append([]Option{WithReplayBackoff(time.Millisecond, 10*time.Millisecond)}, tc.opts...)
+			p, err := NewProcessor(ctx, dir, b, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := spoolRequests(t, p, 10)
+			waitForDelivered(t, b, len(want))
+
+			if err := p.Stop(); err != nil {
+				t.Errorf("Stop() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(want, b.delivered()); diff != "" {
+				t.Errorf("delivered log requests got diff (-want, +got): %v", diff)
+			}
+			if !b.stopped {
+				t.Errorf("expected wrapped backend to be stopped")
+			}
+
+			// Delivered segments are removed, except the one being written.
+			segs, err := listSegments(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(segs); got != 1 {
+				t.Errorf("got %d segments left, want 1", got)
+			}
+		})
+	}
+}
+
+func TestProcessor_DeadLetter(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	cases := []struct {
+		name           string
+		err            error
+		opts           []Option
+		wantDeadLetter bool
+	}{
+		{
+			name:           "invalid_request",
+			err:            fmt.Errorf("backend: %w", auditerrors.ErrInvalidRequest),
+			wantDeadLetter: true,
+		},
+		{
+			name:           "invalid_argument",
+			err:            status.Error(codes.InvalidArgument, "injected error"),
+			wantDeadLetter: true,
+		},
+		{
+			name:           "wrapped_http_status",
+			err:            fmt.Errorf("webhook request failed: %w", status.Error(codes.PermissionDenied, "HTTP 403")),
+			wantDeadLetter: true,
+		},
+		{
+			name: "precondition_failed",
+			err:  fmt.Errorf("backend: %w", auditerrors.ErrPreconditionFailed),
+		},
+		{
+			name:           "custom_permanent_codes",
+			err:            status.Error(codes.Internal, "injected error"),
+			opts:           []Option{WithPermanentCodes(codes.Internal)},
+			wantDeadLetter: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			b := &fakeBackend{reject: map[string]error{"m1": tc.err}}
+			p, err := NewProcessor(ctx, dir, b, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The replay moves past the rejected log request.
+			spoolRequests(t, p, 3)
+			waitForDelivered(t, b, 2)
+			if err := p.Stop(); err != nil {
+				t.Errorf("Stop() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff([]string{"m0", "m2"}, b.delivered()); diff != "" {
+				t.Errorf("delivered log requests got diff (-want, +got): %v", diff)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, deadLetterFile))
+			if !tc.wantDeadLetter {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("expected no dead letter file, got error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read dead letter file: %v", err)
+			}
+			payload, next, err := readRecordAt(bytes.NewReader(data), 0)
+			if err != nil {
+				t.Fatalf("failed to read dead letter record: %v", err)
+			}
+			if got, want := next, int64(len(data)); got != want {
+				t.Errorf("dead letter file got %d bytes of records, want %d", got, want)
+			}
+			logReq := &api.AuditLogRequest{}
+			if err := proto.Unmarshal(payload, logReq); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := logReq.GetPayload().GetMethodName(), "m1"; got != want {
+				t.Errorf("dead letter log request got method %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestProcessor_SurvivesRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	dir := t.TempDir()
+
+	// The backend is down for the lifetime of the first processor.
+	p, err := NewProcessor(ctx, dir, &fakeBackend{failures: -1},
+		WithReplayBackoff(time.Millisecond, 10*time.Millisecond),
+		WithFlushTimeout(50*time.Millisecond),
+		WithMaxSegmentBytes(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := spoolRequests(t, p, 5)
+	if diff := pkgtestutil.DiffErrString(p.Stop(), "failed to flush spool"); diff != "" {
+		t.Errorf("Stop() got unexpected error substring: %v", diff)
+	}
+	if err := p.Process(ctx, testutil.NewRequest()); !errors.Is(err, ErrStopped) {
+		t.Errorf("Process() after Stop got error %v, want %v", err, ErrStopped)
+	}
+
+	// Simulate a torn write at the end of the spool.
+	segs, err := listSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, segmentName(segs[len(segs)-1])), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0x10, 0x00, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next processor replays the spooled log requests once the backend is
+	// back.
+	b := &fakeBackend{}
+	p, err = NewProcessor(ctx, dir, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, spoolRequests(t, p, 1)...)
+	if err := p.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, b.delivered()); diff != "" {
+		t.Errorf("delivered log requests got diff (-want, +got): %v", diff)
+	}
+}
+
+func TestProcessor_CorruptSegment(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	dir := t.TempDir()
+
+	p, err := NewProcessor(ctx, dir, &fakeBackend{failures: -1},
+		WithFlushTimeout(10*time.Millisecond),
+		WithMaxSegmentBytes(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With the tiny segment size, each log request has its own segment.
+	want := spoolRequests(t, p, 3)
+	if err := p.Stop(); err == nil {
+		t.Errorf("Stop() expected flush error")
+	}
+
+	// Flip a byte in the payload of the first segment.
+	segs, err := listSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := filepath.Join(dir, segmentName(segs[0]))
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(first, data, segmentFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &fakeBackend{}
+	p, err = NewProcessor(ctx, dir, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want[1:], b.delivered()); diff != "" {
+		t.Errorf("delivered log requests got diff (-want, +got): %v", diff)
+	}
+	if _, err := os.Stat(first + corruptSuffix); err != nil {
+		t.Errorf("expected corrupted segment to be quarantined: %v", err)
+	}
+}
+
+func TestProcessor_CorruptRecordMidSegment(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+	dir := t.TempDir()
+
+	p, err := NewProcessor(ctx, dir, &fakeBackend{failures: -1},
+		WithFlushTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// All the log requests are in the same segment.
+	want := spoolRequests(t, p, 3)
+	if err := p.Stop(); err == nil {
+		t.Errorf("Stop() expected flush error")
+	}
+
+	// Flip a byte in the payload of the second record.
+	segs, err := listSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg := filepath.Join(dir, segmentName(segs[len(segs)-1]))
+	data, err := os.ReadFile(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := readRecordAt(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[second+recordHeaderSize] ^= 0xff
+	if err := os.WriteFile(seg, data, segmentFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// The records before the corruption are replayed and the segment is
+	// quarantined as a whole, rather than truncated.
+	b := &fakeBackend{}
+	p, err = NewProcessor(ctx, dir, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want[:1], spoolRequests(t, p, 1)...)
+	if err := p.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, b.delivered()); diff != "" {
+		t.Errorf("delivered log requests got diff (-want, +got): %v", diff)
+	}
+	got, err := os.ReadFile(seg + corruptSuffix)
+	if err != nil {
+		t.Fatalf("expected corrupted segment to be quarantined: %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("quarantined segment got diff (-want, +got): %v", diff)
+	}
+}
+
+func TestProcessor_MaxBytes(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	p, err := NewProcessor(ctx, t.TempDir(), &fakeBackend{failures: -1},
+		WithMaxBytes(500),
+		WithFlushTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		// The backend is down, so the flush is expected to fail.
+		_ = p.Stop()
+	})
+
+	var gotErr error
+	for range 100 {
+		if gotErr = p.Process(ctx, testutil.NewRequest()); gotErr != nil {
+			break
+		}
+	}
+	if !errors.Is(gotErr, ErrFull) {
+		t.Errorf("Process() got error %v, want %v", gotErr, ErrFull)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// A spool directory holds a sequence of segment files. Each segment is a
+// sequence of records, and each record is framed as:
+//
+//	| length (4 bytes) | CRC-32C of payload (4 bytes) | payload (length bytes) |
+//
+// with both integers in little endian. The payload is a serialized
+// AuditLogRequest proto.
+const (
+	recordHeaderSize = 8
+
+	// maxRecordSize bounds the length read from a record header, so that a
+	// corrupted length is detected rather than allocated.
+	maxRecordSize = 64 << 20
+
+	segmentSuffix    = ".spool"
+	corruptSuffix    = ".corrupt"
+	checkpointFile   = "checkpoint"
+	deadLetterFile   = "deadletter"
+	segmentFilePerm  = 0o600
+	spoolDirFilePerm = 0o700
+)
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	segmentNameRegexp = regexp.MustCompile(`^(\d{20})\.spool$`)
+
+	// errCorruptRecord is returned when a record fails the integrity checks.
+	errCorruptRecord = errors.New("corrupt spool record")
+)
+
+// segmentName returns the file name of the segment with the given sequence
+// number. Names are zero padded so that they sort in sequence order.
+func segmentName(seq uint64) string {
+	return fmt.Sprintf("%020d%s", seq, segmentSuffix)
+}
+
+// listSegments returns the sequence numbers of the segments in dir in
+// ascending order.
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+	var seqs []uint64
+	for _, e := range entries {
+		m := segmentNameRegexp.FindStringSubmatch(e.Name())
+		if m == nil || !e.Type().IsRegular() {
+			continue
+		}
+		seq, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse segment name %q: %w", e.Name(), err)
+		}
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+	return seqs, nil
+}
+
+// encodeRecord frames the payload as a record.
+func encodeRecord(payload []byte) []byte {
+	b := make([]byte, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(payload))) //nolint:gosec // Bounded by maxRecordSize.
+	binary.LittleEndian.PutUint32(b[4:8], crc32.Checksum(payload, crcTable))
+	copy(b[recordHeaderSize:], payload)
+	return b
+}
+
+// readRecordAt reads the record at the given offset and returns its payload
+// and the offset of the next record. It returns io.EOF if there is no record
+// at the offset, io.ErrUnexpectedEOF if the record is truncated, and
+// errCorruptRecord if the record fails the integrity checks.
+func readRecordAt(r io.ReaderAt, off int64) ([]byte, int64, error) {
+	var header [recordHeaderSize]byte
+	if n, err := r.ReadAt(header[:], off); n < recordHeaderSize {
+		switch {
+		case !errors.Is(err, io.EOF):
+			return nil, off, fmt.Errorf("failed to read record header at offset %d: %w", off, err)
+		case n == 0:
+			return nil, off, io.EOF
+		default:
+			return nil, off, fmt.Errorf("failed to read record header at offset %d: %w", off, io.ErrUnexpectedEOF)
+		}
+	}
+
+	size := binary.LittleEndian.Uint32(header[0:4])
+	sum := binary.LittleEndian.Uint32(header[4:8])
+	if size > maxRecordSize {
+		return nil, off, fmt.Errorf("record at offset %d has invalid length %d: %w", off, size, errCorruptRecord)
+	}
+
+	payload := make([]byte, size)
+	if n, err := r.ReadAt(payload, off+recordHeaderSize); n < len(payload) {
+		if !errors.Is(err, io.EOF) {
+			return nil, off, fmt.Errorf("failed to read record payload at offset %d: %w", off, err)
+		}
+		return nil, off, fmt.Errorf("failed to read record payload at offset %d: %w", off, io.ErrUnexpectedEOF)
+	}
+	if crc32.Checksum(payload, crcTable) != sum {
+		return nil, off, fmt.Errorf("record at offset %d has mismatched checksum: %w", off, errCorruptRecord)
+	}
+	return payload, off + recordHeaderSize + int64(size), nil
+}
+
+// scanSegment scans the records of the segment of the given size and returns
+// the offset right after the last valid record. A torn record at the end of
+// the segment, e.g. from a crash during a write, ends the scan. A corrupted
+// record followed by more data returns errCorruptRecord instead, as the
+// records after it may still be valid.
+func scanSegment(r io.ReaderAt, size int64) (int64, error) {
+	var off int64
+	for {
+		_, next, err := readRecordAt(r, off)
+		switch {
+		case err == nil:
+			off = next
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			return off, nil
+		case errors.Is(err, errCorruptRecord) && recordEnd(r, off) == size:
+			// The last record was only partly written, e.g. the file was
+			// extended but the payload didn't reach the disk.
+			return off, nil
+		default:
+			return off, err
+		}
+	}
+}
+
+// recordEnd returns the offset right after the record at the given offset, as
+// given by its header, or -1 if the header can't be read or its length is
+// invalid.
+func recordEnd(r io.ReaderAt, off int64) int64 {
+	var header [recordHeaderSize]byte
+	if n, _ := r.ReadAt(header[:], off); n < recordHeaderSize {
+		return -1
+	}
+	size := binary.LittleEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return -1
+	}
+	return off + recordHeaderSize + int64(size)
+}
+
+// quarantineSegment renames the segment so that it's no longer replayed, but
+// is kept for inspection.
+func quarantineSegment(dir string, seq uint64) error {
+	p := filepath.Join(dir, segmentName(seq))
+	if err := os.Rename(p, p+corruptSuffix); err != nil {
+		return fmt.Errorf("failed to quarantine segment: %w", err)
+	}
+	return nil
+}
+
+// appendDeadLetter durably appends the payload, framed as a record, to the
+// dead letter file, which keeps the log requests that the backend rejected
+// for inspection.
+func appendDeadLetter(dir string, payload []byte) error {
+	f, err := os.OpenFile(filepath.Join(dir, deadLetterFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, segmentFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	if _, err := f.Write(encodeRecord(payload)); err != nil {
+		return errors.Join(fmt.Errorf("failed to write dead letter file: %w", err), f.Close())
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync dead letter file: %w", err), f.Close())
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close dead letter file: %w", err)
+	}
+	return syncDir(dir)
+}
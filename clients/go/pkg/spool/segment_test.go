@@ -0,0 +1,173 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+func TestReadRecordAt(t *testing.T) {
+	t.Parallel()
+
+	valid := encodeRecord([]byte("hello"))
+
+	badChecksum := bytes.Clone(valid)
+	badChecksum[len(badChecksum)-1] ^= 0xff
+
+	badLength := bytes.Clone(valid)
+	binary.LittleEndian.PutUint32(badLength[0:4], maxRecordSize+1)
+
+	cases := []struct {
+		name        string
+		data        []byte
+		offset      int64
+		wantPayload []byte
+		wantNext    int64
+		wantErr     error
+	}{
+		{
+			name:        "valid_record",
+			data:        valid,
+			wantPayload: []byte("hello"),
+			wantNext:    int64(len(valid)),
+		},
+		{
+			name:        "second_record",
+			data:        append(bytes.Clone(valid), encodeRecord([]byte("world"))...),
+			offset:      int64(len(valid)),
+			wantPayload: []byte("world"),
+			wantNext:    2 * int64(len(valid)),
+		},
+		{
+			name:    "no_record",
+			data:    valid,
+			offset:  int64(len(valid)),
+			wantErr: io.EOF,
+		},
+		{
+			name:    "truncated_header",
+			data:    valid[:recordHeaderSize-1],
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "truncated_payload",
+			data:    valid[:len(valid)-1],
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "mismatched_checksum",
+			data:    badChecksum,
+			wantErr: errCorruptRecord,
+		},
+		{
+			name:    "invalid_length",
+			data:    badLength,
+			wantErr: errCorruptRecord,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotPayload, gotNext, err := readRecordAt(bytes.NewReader(tc.data), tc.offset)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("readRecordAt() got error %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.wantPayload, gotPayload); diff != "" {
+				t.Errorf("readRecordAt() payload got diff (-want, +got): %v", diff)
+			}
+			if gotNext != tc.wantNext {
+				t.Errorf("readRecordAt() next offset got %d, want %d", gotNext, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestScanSegment(t *testing.T) {
+	t.Parallel()
+
+	rec := encodeRecord([]byte("hello"))
+	data := append(bytes.Clone(rec), rec...)
+
+	// A record whose payload doesn't match its checksum.
+	corrupt := bytes.Clone(rec)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	cases := []struct {
+		name          string
+		data          []byte
+		want          int64
+		wantErrSubstr string
+	}{
+		{
+			name: "empty",
+			data: nil,
+			want: 0,
+		},
+		{
+			name: "all_valid",
+			data: data,
+			want: int64(len(data)),
+		},
+		{
+			name: "torn_tail",
+			data: append(bytes.Clone(data), rec[:5]...),
+			want: int64(len(data)),
+		},
+		{
+			name: "corrupt_tail",
+			data: append(bytes.Clone(data), corrupt...),
+			want: int64(len(data)),
+		},
+		{
+			name:          "corrupt_middle",
+			data:          append(append(bytes.Clone(rec), corrupt...), rec...),
+			want:          int64(len(rec)),
+			wantErrSubstr: "mismatched checksum",
+		},
+		{
+			name:          "invalid_length_middle",
+			data:          append(append(bytes.Clone(rec), 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0), rec...),
+			want:          int64(len(rec)),
+			wantErrSubstr: "invalid length",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := scanSegment(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("scanSegment() got unexpected error substring: %v", diff)
+			}
+			if got != tc.want {
+				t.Errorf("scanSegment() got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stdout provides a backend that writes audit logs as JSON lines, e.g.
+// to stdout, for logging agents to collect or for local development.
+package stdout
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/logentry"
+)
+
+// Processor is a backend that writes each audit log request as one JSON line
+// in the shape of a Cloud Logging LogEntry, see logentry.MarshalLine.
+type Processor struct {
+	// mu guards writes, so that concurrent lines don't interleave.
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Option is a configuration option for NewProcessor.
+type Option func(p *Processor) error
+
+// WithWriter sets where the JSON lines are written. The default is stdout.
+func WithWriter(w io.Writer) Option {
+	return func(p *Processor) error {
+		if w == nil {
+			return fmt.Errorf("writer must not be nil")
+		}
+		p.w = w
+		return nil
+	}
+}
+
+// WithStderr writes the JSON lines to stderr instead of stdout.
+func WithStderr() Option {
+	return WithWriter(os.Stderr)
+}
+
+// NewProcessor creates a new stdout processor with the given options.
+func NewProcessor(opts ...Option) (*Processor, error) {
+	p := &Processor{w: os.Stdout}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply stdout processor options: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Process writes the audit log request as a JSON line.
+func (p *Processor) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	b, err := logentry.MarshalLine(logReq)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(b); err != nil {
+		return fmt.Errorf("failed to write log entry: %w", err)
+	}
+	return nil
+}
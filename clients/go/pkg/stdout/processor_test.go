@@ -0,0 +1,148 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stdout
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/logentry"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "stderr",
+			opts: []Option{WithStderr()},
+		},
+		{
+			name:          "nil_writer",
+			opts:          []Option{WithWriter(nil)},
+			wantErrSubstr: "writer must not be nil",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewProcessor(tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	ts := timestamppb.New(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	logReq := testutil.NewRequest(
+		testutil.WithPrincipal("user@example.com"),
+		testutil.WithMethodName("/test.Service/Get"),
+		testutil.WithLabels(map[string]string{"env": "dev"}))
+	logReq.Timestamp = ts
+	logReq.Operation = &loggingpb.LogEntryOperation{Id: "op-1", Producer: "test", First: true}
+
+	want, err := logentry.FromRequest(logReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	p, err := NewProcessor(WithWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Process(t.Context(), logReq); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	line, rest, ok := bytes.Cut(buf.Bytes(), []byte("\n"))
+	if !ok || len(rest) != 0 {
+		t.Fatalf("Process() got output %q, want a single line", buf.String())
+	}
+	var got loggingpb.LogEntry
+	if err := protojson.Unmarshal(line, &got); err != nil {
+		t.Fatalf("failed to unmarshal line %q: %v", line, err)
+	}
+	if diff := cmp.Diff(want, &got, protocmp.Transform()); diff != "" {
+		t.Errorf("Process() unexpected log entry (-want, +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const n = 100
+
+	var buf bytes.Buffer
+	p, err := NewProcessor(WithWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logReq := testutil.NewRequest()
+			logReq.Type = api.AuditLogRequest_ADMIN_ACTIVITY
+			if err := p.Process(t.Context(), logReq); err != nil {
+				t.Errorf("Process() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var lines int
+	s := bufio.NewScanner(&buf)
+	for s.Scan() {
+		var entry loggingpb.LogEntry
+		if err := protojson.Unmarshal(s.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to unmarshal line %q: %v", s.Bytes(), err)
+		}
+		if got, want := entry.GetLogName(), "audit.abcxyz/activity"; got != want {
+			t.Errorf("log name got %q, want %q", got, want)
+		}
+		lines++
+	}
+	if lines != n {
+		t.Errorf("got %d lines, want %d", lines, n)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// Facility is the syslog facility of the messages.
+type Facility int
+
+// The syslog facilities, see RFC 5424 section 6.2.1.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp",
+	"cron", "authpriv", "ftp", "ntp", "audit", "alert", "clock", "local0",
+	"local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// ParseFacility returns the facility with the given name, e.g. "local0".
+func ParseFacility(name string) (Facility, error) {
+	for i, n := range facilityNames {
+		if strings.EqualFold(n, name) {
+			return Facility(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown syslog facility %q", name)
+}
+
+// String returns the name of the facility.
+func (f Facility) String() string {
+	if f < 0 || int(f) >= len(facilityNames) {
+		return strconv.Itoa(int(f))
+	}
+	return facilityNames[f]
+}
+
+// The syslog severities of the messages, see RFC 5424 section 6.2.1.
+const (
+	severityWarning       = 4
+	severityInformational = 6
+)
+
+const (
+	// DefaultStructuredDataID is the default SD-ID of the structured data
+	// element holding the audit log fields. 32473 is the private enterprise
+	// number reserved for documentation, see RFC 5612.
+	DefaultStructuredDataID = "audit@32473"
+
+	// nilValue is the RFC 5424 NILVALUE of an unknown header field.
+	nilValue = "-"
+
+	// The maximum lengths of the header fields.
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+	maxProcIDLen   = 128
+	maxMsgIDLen    = 32
+
+	// bom marks the MSG part as UTF-8.
+	bom = "\xef\xbb\xbf"
+)
+
+// header holds the RFC 5424 header fields that don't depend on the message.
+type header struct {
+	facility Facility
+	hostname string
+	appName  string
+	procID   string
+	sdID     string
+}
+
+// format formats the audit log request as an RFC 5424 message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID principal="..." ...] BOM JSON
+//
+// The MSGID is the log type, the structured data holds the principal, method,
+// resource, service and status of the audit log, and the MSG part is the
+// audit log payload as JSON.
+func (h *header) format(logReq *api.AuditLogRequest, now time.Time) ([]byte, error) {
+	payload := logReq.GetPayload()
+
+	severity := severityInformational
+	if c := payload.GetStatus().GetCode(); c != int32(code.Code_OK) {
+		severity = severityWarning
+	}
+
+	ts := now
+	if logReq.GetTimestamp() != nil {
+		ts = logReq.GetTimestamp().AsTime()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		int(h.facility)*8+severity,
+		ts.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(h.hostname, maxHostnameLen),
+		headerField(h.appName, maxAppNameLen),
+		headerField(h.procID, maxProcIDLen),
+		headerField(logReq.GetType().String(), maxMsgIDLen))
+
+	b.WriteString("[")
+	b.WriteString(h.sdID)
+	params := []sdParam{
+		{"principal", payload.GetAuthenticationInfo().GetPrincipalEmail()},
+		{"method", payload.GetMethodName()},
+		{"resource", payload.GetResourceName()},
+		{"service", payload.GetServiceName()},
+	}
+	if s := payload.GetStatus(); s != nil {
+		params = append(params,
+			sdParam{"status_code", code.Code(s.GetCode()).String()},
+			sdParam{"status_message", s.GetMessage()})
+	}
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, " %s=\"%s\"", p.name, escapeParamValue(p.value))
+	}
+	b.WriteString("]")
+
+	if payload != nil {
+		msg, err := protojson.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit log payload: %w", err)
+		}
+		b.WriteString(" ")
+		b.WriteString(bom)
+		b.Write(msg)
+	}
+	return []byte(b.String()), nil
+}
+
+// sdParam is a structured data parameter.
+type sdParam struct {
+	name, value string
+}
+
+// headerField returns the value as a header field: printable US-ASCII
+// without spaces, truncated to the maximum length, or the NILVALUE if empty.
+func headerField(v string, maxLen int) string {
+	f := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, v)
+	if len(f) > maxLen {
+		f = f[:maxLen]
+	}
+	if f == "" {
+		return nilValue
+	}
+	return f
+}
+
+// escapeParamValue escapes the '"', '\' and ']' characters of a structured
+// data parameter value.
+func escapeParamValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
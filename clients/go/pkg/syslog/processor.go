@@ -0,0 +1,325 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package syslog provides a backend that sends audit logs as RFC 5424 syslog
+// messages over UDP, TCP or TLS.
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+)
+
+// Network is the transport of the syslog messages.
+type Network string
+
+const (
+	// NetworkUDP sends each message in a datagram, see RFC 5426.
+	NetworkUDP Network = "udp"
+
+	// NetworkTCP sends the messages over TCP with octet counting framing, see
+	// RFC 6587.
+	NetworkTCP Network = "tcp"
+
+	// NetworkTLS sends the messages over TLS with octet counting framing, see
+	// RFC 5425.
+	NetworkTLS Network = "tls"
+)
+
+const (
+	// DefaultAppName is the default APP-NAME of the messages.
+	DefaultAppName = "lumberjack"
+
+	// DefaultTimeout is the default timeout of connecting and of writing a
+	// message.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Processor is a backend that sends each audit log request as an RFC 5424
+// syslog message, see the format of the messages in header.format.
+//
+// The connection is established on the first message. If sending a message
+// fails, or the server closed a TCP or TLS connection, the processor
+// reconnects and sends the message again once. Failures are returned with
+// the gRPC Unavailable code, so that the resilience package retries them.
+type Processor struct {
+	network   Network
+	address   string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	header    *header
+
+	// now returns the current time, it's replaced in tests.
+	now func() time.Time
+
+	// mu guards the connection, so that messages don't interleave.
+	mu   sync.Mutex
+	conn net.Conn
+	// connDone is closed once the server closed a TCP or TLS connection.
+	connDone chan struct{}
+	stopped  bool
+}
+
+// Option is a configuration option for NewProcessor.
+type Option func(p *Processor) error
+
+// WithFacility sets the facility of the messages. The default is
+// FacilityAuth.
+func WithFacility(f Facility) Option {
+	return func(p *Processor) error {
+		if f < FacilityKern || f > FacilityLocal7 {
+			return fmt.Errorf("invalid syslog facility %d", f)
+		}
+		p.header.facility = f
+		return nil
+	}
+}
+
+// WithAppName sets the APP-NAME of the messages. The default is
+// DefaultAppName.
+func WithAppName(name string) Option {
+	return func(p *Processor) error {
+		if name == "" {
+			return fmt.Errorf("app name must not be empty")
+		}
+		p.header.appName = name
+		return nil
+	}
+}
+
+// WithHostname sets the HOSTNAME of the messages. The default is the host
+// name reported by the kernel.
+func WithHostname(hostname string) Option {
+	return func(p *Processor) error {
+		p.header.hostname = hostname
+		return nil
+	}
+}
+
+// WithStructuredDataID sets the SD-ID of the structured data element holding
+// the audit log fields. It must have the "name@<private enterprise number>"
+// form. The default is DefaultStructuredDataID.
+func WithStructuredDataID(id string) Option {
+	return func(p *Processor) error {
+		name, pen, ok := strings.Cut(id, "@")
+		if !ok || name == "" || len(id) > 32 || strings.ContainsAny(id, ` ="]`) {
+			return fmt.Errorf("invalid structured data ID %q", id)
+		}
+		if _, err := strconv.ParseUint(strings.ReplaceAll(pen, ".", ""), 10, 64); err != nil {
+			return fmt.Errorf("invalid structured data ID %q: %w", id, err)
+		}
+		p.header.sdID = id
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS config of NetworkTLS connections, e.g. to trust
+// a private CA. By default, the system roots are trusted.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(p *Processor) error {
+		p.tlsConfig = c
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of connecting and of writing a message. The
+// default is DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Processor) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+		p.timeout = d
+		return nil
+	}
+}
+
+// NewProcessor creates a new syslog processor sending messages to the given
+// "host:port" address over the network.
+func NewProcessor(network Network, address string, opts ...Option) (*Processor, error) {
+	switch network {
+	case NetworkUDP, NetworkTCP, NetworkTLS:
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q", network)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", address, err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	p := &Processor{
+		network: network,
+		address: address,
+		timeout: DefaultTimeout,
+		header: &header{
+			facility: FacilityAuth,
+			hostname: hostname,
+			appName:  DefaultAppName,
+			procID:   strconv.Itoa(os.Getpid()),
+			sdID:     DefaultStructuredDataID,
+		},
+		now: time.Now,
+	}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply syslog processor options: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Process sends the audit log request as a syslog message.
+func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	msg, err := p.header.format(logReq, p.now())
+	if err != nil {
+		return err
+	}
+	if p.network != NetworkUDP {
+		// Octet counting framing.
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return fmt.Errorf("syslog processor is stopped")
+	}
+
+	// A failed write may be due to a stale connection, so reconnect and try
+	// once more.
+	if err := p.write(ctx, msg); err != nil {
+		p.closeConn()
+		if retryErr := p.write(ctx, msg); retryErr != nil {
+			p.closeConn()
+			return fmt.Errorf("failed to send syslog message: %w",
+				status.Error(codes.Unavailable, errors.Join(err, retryErr).Error()))
+		}
+	}
+	return nil
+}
+
+// Stop closes the connection. The processor can't be used afterwards.
+func (p *Processor) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	if err != nil {
+		return fmt.Errorf("failed to close syslog connection: %w", err)
+	}
+	return nil
+}
+
+// write writes the message, connecting first if needed. It must be called
+// with mu held.
+func (p *Processor) write(ctx context.Context, msg []byte) error {
+	if p.conn != nil && isDone(p.connDone) {
+		p.closeConn()
+	}
+	if p.conn == nil {
+		conn, err := p.dial(ctx)
+		if err != nil {
+			return err
+		}
+		p.conn = conn
+		p.connDone = make(chan struct{})
+		if p.network != NetworkUDP {
+			go watchConn(conn, p.connDone)
+		}
+	}
+
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := p.conn.SetWriteDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	if _, err := p.conn.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// dial connects to the syslog server.
+func (p *Processor) dial(ctx context.Context) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var conn net.Conn
+	var err error
+	switch p.network {
+	case NetworkTLS:
+		d := &tls.Dialer{Config: p.tlsConfig}
+		conn, err = d.DialContext(ctx, "tcp", p.address)
+	default:
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, string(p.network), p.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", p.address, err)
+	}
+	return conn, nil
+}
+
+// closeConn closes the connection, ignoring errors as it's being discarded.
+func (p *Processor) closeConn() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// watchConn closes done once the stream connection is closed. Syslog servers
+// never send data, so a read only returns when the connection is closed or
+// broken.
+func watchConn(conn net.Conn, done chan<- struct{}) {
+	defer close(done)
+	var b [1]byte
+	for {
+		if _, err := conn.Read(b[:]); err != nil {
+			return
+		}
+	}
+}
+
+// isDone reports whether the channel is closed.
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 678901234, time.UTC)
+	h := &header{
+		facility: FacilityLocal0,
+		hostname: "host",
+		appName:  "app",
+		procID:   "42",
+		sdID:     DefaultStructuredDataID,
+	}
+
+	cases := []struct {
+		name   string
+		header *header
+		logReq *api.AuditLogRequest
+		want   string
+	}{
+		{
+			name:   "success",
+			header: h,
+			logReq: testutil.NewRequest(),
+			want: `<134>1 2026-01-02T03:04:05.678901Z host app 42 DATA_ACCESS ` +
+				`[audit@32473 principal="user@example.com" method="test-method" resource="test-resource" service="test-service"] ` +
+				bom + `{"serviceName":"test-service","methodName":"test-method","resourceName":"test-resource","authenticationInfo":{"principalEmail":"user@example.com"}}`,
+		},
+		{
+			name:   "failure_with_escaping",
+			header: h,
+			logReq: func() *api.AuditLogRequest {
+				r := testutil.NewRequest(testutil.WithPrincipal(`a"b]c\d@example.com`))
+				r.Payload.Status = &rpcstatus.Status{Code: int32(code.Code_PERMISSION_DENIED), Message: "denied"}
+				r.Timestamp = timestamppb.New(time.Date(2025, 12, 31, 23, 0, 0, 0, time.FixedZone("X", 3600)))
+				return r
+			}(),
+			want: `<132>1 2025-12-31T22:00:00.000000Z host app 42 DATA_ACCESS ` +
+				`[audit@32473 principal="a\"b\]c\\d@example.com" method="test-method" resource="test-resource" service="test-service" status_code="PERMISSION_DENIED" status_message="denied"] ` +
+				bom + `{"serviceName":"test-service","methodName":"test-method","resourceName":"test-resource","status":{"code":7,"message":"denied"},"authenticationInfo":{"principalEmail":"a\"b]c\\d@example.com"}}`,
+		},
+		{
+			name: "nil_header_fields",
+			header: &header{
+				facility: FacilityAuth,
+				appName:  "my app",
+				sdID:     "x@1",
+			},
+			logReq: &api.AuditLogRequest{Type: api.AuditLogRequest_ADMIN_ACTIVITY},
+			want:   `<38>1 2026-01-02T03:04:05.678901Z - my_app - ADMIN_ACTIVITY [x@1]`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tc.header.format(tc.logReq, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// protojson randomizes its whitespace, so compare without spaces
+			// after the separators.
+			normalized := strings.NewReplacer(`": `, `":`, `, "`, `,"`).Replace(string(got))
+			if diff := cmp.Diff(tc.want, normalized); diff != "" {
+				t.Errorf("format() got diff (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseFacility(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		in            string
+		want          Facility
+		wantErrSubstr string
+	}{
+		{name: "lower", in: "local0", want: FacilityLocal0},
+		{name: "upper", in: "AUTHPRIV", want: FacilityAuthPriv},
+		{name: "unknown", in: "local8", wantErrSubstr: `unknown syslog facility "local8"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseFacility(tc.in)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Fatal(diff)
+			}
+			if got != tc.want {
+				t.Errorf("ParseFacility(%q) got %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		network       Network
+		address       string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name:    "tls",
+			network: NetworkTLS,
+			address: "syslog.example.com:6514",
+			opts:    []Option{WithFacility(FacilityLocal7), WithAppName("app"), WithStructuredDataID("audit@1.2.3")},
+		},
+		{
+			name:          "unsupported_network",
+			network:       "unix",
+			address:       "/dev/log",
+			wantErrSubstr: `unsupported syslog network "unix"`,
+		},
+		{
+			name:          "missing_port",
+			network:       NetworkUDP,
+			address:       "syslog.example.com",
+			wantErrSubstr: "invalid syslog address",
+		},
+		{
+			name:          "invalid_facility",
+			network:       NetworkUDP,
+			address:       "syslog.example.com:514",
+			opts:          []Option{WithFacility(24)},
+			wantErrSubstr: "invalid syslog facility 24",
+		},
+		{
+			name:          "invalid_sd_id",
+			network:       NetworkUDP,
+			address:       "syslog.example.com:514",
+			opts:          []Option{WithStructuredDataID("audit")},
+			wantErrSubstr: `invalid structured data ID "audit"`,
+		},
+		{
+			name:          "invalid_timeout",
+			network:       NetworkUDP,
+			address:       "syslog.example.com:514",
+			opts:          []Option{WithTimeout(0)},
+			wantErrSubstr: "timeout must be positive",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewProcessor(tc.network, tc.address, tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_UDP(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	p := newTestProcessor(t, NetworkUDP, conn.LocalAddr().String())
+	for _, m := range []string{"m1", "m2"} {
+		if err := p.Process(t.Context(), testutil.NewRequest(testutil.WithMethodName(m))); err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+	}
+
+	var got []string
+	buf := make([]byte, 64*1024)
+	for range 2 {
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, methodOf(t, string(buf[:n])))
+	}
+	if diff := cmp.Diff([]string{"m1", "m2"}, got); diff != "" {
+		t.Errorf("received methods got diff (-want, +got): %v", diff)
+	}
+}
+
+func TestProcessor_TCP(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := serveStream(t, ln)
+
+	p := newTestProcessor(t, NetworkTCP, ln.Addr().String())
+	if err := p.Process(t.Context(), testutil.NewRequest(testutil.WithMethodName("m1"))); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got, want := methodOf(t, srv.next(t)), "m1"; got != want {
+		t.Errorf("received method got %q, want %q", got, want)
+	}
+
+	// The server closes the connection, the processor must reconnect.
+	srv.closeConns()
+	waitDisconnect(t, p)
+	if err := p.Process(t.Context(), testutil.NewRequest(testutil.WithMethodName("m2"))); err != nil {
+		t.Fatalf("Process() after disconnect unexpected error: %v", err)
+	}
+	if got, want := methodOf(t, srv.next(t)), "m2"; got != want {
+		t.Errorf("received method got %q, want %q", got, want)
+	}
+	if got, want := srv.connCount(), 2; got != want {
+		t.Errorf("connections got %d, want %d", got, want)
+	}
+
+	// Once the server is gone, Process fails with a retryable error.
+	ln.Close()
+	srv.closeConns()
+	waitDisconnect(t, p)
+	err = p.Process(t.Context(), testutil.NewRequest())
+	if got, want := status.Code(err), codes.Unavailable; got != want {
+		t.Errorf("Process() with server down got code %v, want %v (error: %v)", got, want, err)
+	}
+}
+
+func TestProcessor_TLS(t *testing.T) {
+	t.Parallel()
+
+	// Borrow the certificate of an httptest server, which is valid for
+	// 127.0.0.1.
+	hs := httptest.NewTLSServer(nil)
+	hs.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: hs.TLS.Certificates,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := serveStream(t, ln)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(hs.Certificate())
+	p := newTestProcessor(t, NetworkTLS, ln.Addr().String(),
+		WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}))
+	if err := p.Process(t.Context(), testutil.NewRequest(testutil.WithMethodName("m1"))); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got, want := methodOf(t, srv.next(t)), "m1"; got != want {
+		t.Errorf("received method got %q, want %q", got, want)
+	}
+
+	// The server certificate isn't trusted by default.
+	untrusted := newTestProcessor(t, NetworkTLS, ln.Addr().String())
+	err = untrusted.Process(t.Context(), testutil.NewRequest())
+	if diff := pkgtestutil.DiffErrString(err, "certificate"); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func newTestProcessor(tb testing.TB, network Network, address string, opts ...Option) *Processor {
+	tb.Helper()
+
+	p, err := NewProcessor(network, address, append([]Option{WithTimeout(5 * time.Second)}, opts...)...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := p.Stop(); err != nil {
+			tb.Errorf("Stop() unexpected error: %v", err)
+		}
+	})
+	return p
+}
+
+// waitDisconnect waits until the processor noticed that the server closed the
+// connection.
+func waitDisconnect(tb testing.TB, p *Processor) {
+	tb.Helper()
+
+	select {
+	case <-p.connDone:
+	case <-time.After(5 * time.Second):
+		tb.Fatal("timed out waiting for the processor to notice the disconnect")
+	}
+}
+
+// methodOf returns the method parameter of the structured data of the
+// message.
+func methodOf(tb testing.TB, msg string) string {
+	tb.Helper()
+
+	_, rest, ok := strings.Cut(msg, ` method="`)
+	if !ok {
+		tb.Fatalf("message %q has no method", msg)
+	}
+	method, _, _ := strings.Cut(rest, `"`)
+	return method
+}
+
+// streamServer receives octet counted syslog messages.
+type streamServer struct {
+	msgs chan string
+
+	mu    sync.Mutex
+	conns []net.Conn
+	count int
+}
+
+// serveStream serves the listener until the test ends.
+func serveStream(tb testing.TB, ln net.Listener) *streamServer {
+	tb.Helper()
+
+	s := &streamServer{
+		msgs: make(chan string, 16),
+	}
+	tb.Cleanup(func() {
+		ln.Close()
+		s.closeConns()
+	})
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.count++
+			s.mu.Unlock()
+			go s.read(conn)
+		}
+	}()
+	return s
+}
+
+func (s *streamServer) read(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		l, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(l, " "))
+		if err != nil {
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		s.msgs <- string(msg)
+	}
+}
+
+func (s *streamServer) next(tb testing.TB) string {
+	tb.Helper()
+
+	select {
+	case msg := <-s.msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		tb.Fatal("timed out waiting for a message")
+		return ""
+	}
+}
+
+func (s *streamServer) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *streamServer) connCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
@@ -84,3 +84,12 @@ func WithMode(mode api.AuditLogRequest_LogMode) RequestOptions {
 		return r
 	}
 }
+
+func WithTrace(trace, spanID string, sampled bool) RequestOptions {
+	return func(r *api.AuditLogRequest) *api.AuditLogRequest {
+		r.Trace = trace
+		r.SpanId = spanID
+		r.TraceSampled = sampled
+		return r
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook provides a backend that sends audit logs to an HTTP
+// endpoint as JSON.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/internal/batcher"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/logentry"
+)
+
+const (
+	// SignatureHeader is the header holding the request signature when an
+	// HMAC secret is set: "sha256=" followed by the hex encoded HMAC-SHA256
+	// of the timestamp header value, a dot, and the request body.
+	SignatureHeader = "X-Lumberjack-Signature"
+
+	// TimestampHeader is the header holding the Unix time at which a signed
+	// request was sent, so that receivers can reject replayed requests.
+	TimestampHeader = "X-Lumberjack-Timestamp"
+
+	// DefaultTimeout is the default timeout of a webhook request.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxBatchSize is the default maximum number of audit logs sent in
+	// a single webhook request.
+	DefaultMaxBatchSize = 100
+
+	// maxErrorBodySize bounds how much of an error response body is included
+	// in the returned error.
+	maxErrorBodySize = 512
+)
+
+// Processor is a backend that POSTs audit log requests to a webhook URL. The
+// request body is a JSON array of Cloud Logging LogEntry objects, see
+// logentry.FromRequest, with the "application/json" content type.
+//
+// Failures are returned as gRPC status errors, so that the resilience
+// package retries the transient ones: see CodeFromHTTPStatus for how the HTTP
+// status codes are mapped.
+type Processor struct {
+	url        string
+	insecure   bool
+	headers    map[string]string
+	secret     []byte
+	timeout    time.Duration
+	httpClient *http.Client
+
+	// Batching settings, see WithBatching.
+	maxBatchSize int
+	batchDelay   time.Duration
+
+	// now returns the current time, it's replaced in tests.
+	now func() time.Time
+
+	// batcher collects the batches if batching is enabled.
+	batcher *batcher.Batcher[*pendingLog]
+	// stopped is set once Stop is called.
+	stopped atomic.Bool
+}
+
+// pendingLog is a log request waiting to be sent in a batch.
+type pendingLog struct {
+	entry []byte
+	done  chan error
+}
+
+var _ audit.BatchLogProcessor = (*Processor)(nil)
+
+// Option is a configuration option for NewProcessor.
+type Option func(p *Processor) error
+
+// WithHeaders adds the given headers to the webhook requests, e.g. for
+// authorization.
+func WithHeaders(headers map[string]string) Option {
+	return func(p *Processor) error {
+		for k, v := range headers {
+			p.headers[k] = v
+		}
+		return nil
+	}
+}
+
+// WithHMACSecret signs the webhook requests with the given secret, see
+// SignatureHeader.
+func WithHMACSecret(secret []byte) Option {
+	return func(p *Processor) error {
+		if len(secret) == 0 {
+			return fmt.Errorf("HMAC secret must not be empty")
+		}
+		p.secret = secret
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each webhook request. The default is
+// DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Processor) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+		p.timeout = d
+		return nil
+	}
+}
+
+// WithBatching coalesces the log requests processed concurrently into a
+// single webhook request: a batch is sent once it holds maxSize log requests,
+// or maxDelay after its first log request. Each Process call waits for the
+// result of its batch. A log request whose context is done is removed from the
+// batch if it wasn't sent yet, and otherwise waits for the batch result, so
+// that an error means it wasn't delivered. Without batching, each log request
+// is sent on its own.
+//
+// maxSize also bounds the size of the webhook requests sent by ProcessBatch.
+// The default is DefaultMaxBatchSize.
+func WithBatching(maxSize int, maxDelay time.Duration) Option {
+	return func(p *Processor) error {
+		if maxSize < 1 {
+			return fmt.Errorf("max batch size must be positive")
+		}
+		if maxDelay <= 0 {
+			return fmt.Errorf("batch delay must be positive")
+		}
+		p.maxBatchSize, p.batchDelay = maxSize, maxDelay
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client sending the webhook requests, e.g. to
+// customize TLS. The default is http.DefaultClient.
+func WithHTTPClient(c *http.Client) Option {
+	return func(p *Processor) error {
+		if c == nil {
+			return fmt.Errorf("HTTP client must not be nil")
+		}
+		p.httpClient = c
+		return nil
+	}
+}
+
+// WithInsecure allows a plain HTTP webhook URL. This should only be used for
+// testing.
+func WithInsecure() Option {
+	return func(p *Processor) error {
+		p.insecure = true
+		return nil
+	}
+}
+
+// NewProcessor creates a new webhook processor sending audit logs to the
+// given URL, which must be HTTPS unless WithInsecure is used.
+func NewProcessor(webhookURL string, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		url:          webhookURL,
+		headers:      make(map[string]string),
+		timeout:      DefaultTimeout,
+		httpClient:   http.DefaultClient,
+		maxBatchSize: DefaultMaxBatchSize,
+		now:          time.Now,
+	}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, fmt.Errorf("failed to apply webhook processor options: %w", err)
+		}
+	}
+
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook URL: %w", err)
+	}
+	switch {
+	case u.Host == "":
+		return nil, fmt.Errorf("webhook URL %q has no host", webhookURL)
+	case u.Scheme == "https":
+	case u.Scheme == "http" && p.insecure:
+	default:
+		return nil, fmt.Errorf("webhook URL %q must use https", webhookURL)
+	}
+	if p.batchDelay > 0 {
+		p.batcher = batcher.New(p.maxBatchSize, p.batchDelay, p.flush)
+	}
+	return p, nil
+}
+
+// Process sends the log request to the webhook, in a batch if batching is
+// enabled.
+func (p *Processor) Process(ctx context.Context, logReq *api.AuditLogRequest) error {
+	entry, err := marshalEntry(logReq)
+	if err != nil {
+		return err
+	}
+	if p.batcher == nil {
+		if p.stopped.Load() {
+			return fmt.Errorf("webhook processor is stopped")
+		}
+		return p.send(ctx, [][]byte{entry})
+	}
+
+	l := &pendingLog{entry: entry, done: make(chan error, 1)}
+	if !p.batcher.Add(l) {
+		return fmt.Errorf("webhook processor is stopped")
+	}
+	select {
+	case err := <-l.done:
+		return err
+	case <-ctx.Done():
+		if p.batcher.Remove(l) {
+			return fmt.Errorf("failed to wait for webhook batch: %w", ctx.Err())
+		}
+		// The batch is being sent, so wait for its result, which is bounded
+		// by the timeout, rather than fail a log request that may be
+		// delivered and then retried.
+		return <-l.done
+	}
+}
+
+// ProcessBatch sends the log requests to the webhook, in as many webhook
+// requests as needed to respect the max batch size.
+func (p *Processor) ProcessBatch(ctx context.Context, logReqs []*api.AuditLogRequest) error {
+	if p.stopped.Load() {
+		return fmt.Errorf("webhook processor is stopped")
+	}
+
+	errs := make([]error, len(logReqs))
+	var failed bool
+	for start := 0; start < len(logReqs); start += p.maxBatchSize {
+		end := min(start+p.maxBatchSize, len(logReqs))
+
+		// The indexes and entries of the chunk that could be marshaled.
+		var idx []int
+		var entries [][]byte
+		for i := start; i < end; i++ {
+			entry, err := marshalEntry(logReqs[i])
+			if err != nil {
+				errs[i], failed = err, true
+				continue
+			}
+			idx = append(idx, i)
+			entries = append(entries, entry)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if err := p.send(ctx, entries); err != nil {
+			for _, i := range idx {
+				errs[i] = err
+			}
+			failed = true
+		}
+	}
+
+	if failed {
+		return &audit.BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// Stop sends the pending batch, waits for the batches being sent, and stops
+// the processor. The processor can't be used afterwards.
+func (p *Processor) Stop() error {
+	p.stopped.Store(true)
+	if p.batcher != nil {
+		p.batcher.Stop()
+	}
+	return nil
+}
+
+// flush sends the batch and notifies its log requests of the result. The
+// batch outlives the callers, so it's sent without their context.
+func (p *Processor) flush(batch []*pendingLog) {
+	entries := make([][]byte, 0, len(batch))
+	for _, l := range batch {
+		entries = append(entries, l.entry)
+	}
+	err := p.send(context.Background(), entries)
+	for _, l := range batch {
+		l.done <- err
+	}
+}
+
+// send POSTs the entries to the webhook as a JSON array.
+func (p *Processor) send(ctx context.Context, entries [][]byte) error {
+	body := make([]byte, 0, 2+len(entries)*1024)
+	body = append(body, '[')
+	body = append(body, bytes.Join(entries, []byte(","))...)
+	body = append(body, ']')
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.secret != nil {
+		ts := strconv.FormatInt(p.now().Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader, Sign(p.secret, ts, body))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook request: %w", status.Error(codeFromError(ctx, err), err.Error()))
+	}
+	defer resp.Body.Close()
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	// Drain the rest of the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return status.Errorf(CodeFromHTTPStatus(resp.StatusCode), "webhook responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+}
+
+// Sign returns the value of the SignatureHeader for the given secret,
+// timestamp header value and request body.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CodeFromHTTPStatus maps the HTTP status code of a failed webhook request
+// to a gRPC code. The codes that the resilience package retries by default
+// are returned for the transient failures: request timeouts, conflicts, rate
+// limiting and server errors other than 501.
+func CodeFromHTTPStatus(code int) codes.Code {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	}
+	switch {
+	case code >= 500:
+		return codes.Unavailable
+	case code >= 400:
+		return codes.InvalidArgument
+	default:
+		// E.g. an unfollowed redirect.
+		return codes.Unknown
+	}
+}
+
+// codeFromError maps the error of a webhook request that got no response to
+// a gRPC code.
+func codeFromError(ctx context.Context, err error) codes.Code {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(ctx.Err(), context.Canceled):
+		return codes.Canceled
+	default:
+		// E.g. the connection was refused or reset.
+		return codes.Unavailable
+	}
+}
+
+// marshalEntry encodes the log request as a LogEntry JSON object.
+func marshalEntry(logReq *api.AuditLogRequest) ([]byte, error) {
+	entry, err := logentry.FromRequest(logReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode log entry: %w", err)
+	}
+	b, err := protojson.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,410 @@
+// Copyright 2026 Lumberjack authors (see AUTHORS file)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/testutil"
+	"github.com/abcxyz/lumberjack/pkg/validation"
+	pkgtestutil "github.com/abcxyz/pkg/testutil"
+)
+
+// fakeWebhook records the requests it receives, and responds with the next
+// status code of statusCodes, or 200 once they are exhausted.
+type fakeWebhook struct {
+	mu          sync.Mutex
+	statusCodes []int
+	headers     []http.Header
+	bodies      [][]byte
+	batches     [][]json.RawMessage
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headers = append(f.headers, r.Header.Clone())
+	f.bodies = append(f.bodies, body)
+	f.batches = append(f.batches, batch)
+	if len(f.statusCodes) > 0 {
+		code := f.statusCodes[0]
+		f.statusCodes = f.statusCodes[1:]
+		http.Error(w, "fake failure", code)
+	}
+}
+
+func (f *fakeWebhook) batchSizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var sizes []int
+	for _, b := range f.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+// newTestProcessor starts a TLS server with the fake webhook and returns a
+// processor sending to it.
+func newTestProcessor(tb testing.TB, f *fakeWebhook, opts ...Option) *Processor {
+	tb.Helper()
+
+	srv := httptest.NewTLSServer(f)
+	tb.Cleanup(srv.Close)
+
+	p, err := NewProcessor(srv.URL, append([]Option{WithHTTPClient(srv.Client())}, opts...)...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := p.Stop(); err != nil {
+			tb.Errorf("Stop() unexpected error: %v", err)
+		}
+	})
+	return p
+}
+
+func TestNewProcessor(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		url           string
+		opts          []Option
+		wantErrSubstr string
+	}{
+		{
+			name: "https",
+			url:  "https://collector.example.com/audit",
+		},
+		{
+			name: "insecure_http",
+			url:  "http://localhost:8080/audit",
+			opts: []Option{WithInsecure()},
+		},
+		{
+			name:          "http_not_allowed",
+			url:           "http://collector.example.com/audit",
+			wantErrSubstr: "must use https",
+		},
+		{
+			name:          "no_host",
+			url:           "https:///audit",
+			wantErrSubstr: "has no host",
+		},
+		{
+			name:          "empty_hmac_secret",
+			url:           "https://collector.example.com/audit",
+			opts:          []Option{WithHMACSecret(nil)},
+			wantErrSubstr: "HMAC secret must not be empty",
+		},
+		{
+			name:          "invalid_timeout",
+			url:           "https://collector.example.com/audit",
+			opts:          []Option{WithTimeout(0)},
+			wantErrSubstr: "timeout must be positive",
+		},
+		{
+			name:          "invalid_batch_size",
+			url:           "https://collector.example.com/audit",
+			opts:          []Option{WithBatching(0, time.Second)},
+			wantErrSubstr: "max batch size must be positive",
+		},
+		{
+			name:          "invalid_batch_delay",
+			url:           "https://collector.example.com/audit",
+			opts:          []Option{WithBatching(10, 0)},
+			wantErrSubstr: "batch delay must be positive",
+		},
+		{
+			name:          "nil_http_client",
+			url:           "https://collector.example.com/audit",
+			opts:          []Option{WithHTTPClient(nil)},
+			wantErrSubstr: "HTTP client must not be nil",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewProcessor(tc.url, tc.opts...)
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("NewProcessor() got unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		statusCodes   []int
+		wantCode      codes.Code
+		wantErrSubstr string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:          "retryable_status",
+			statusCodes:   []int{http.StatusServiceUnavailable},
+			wantCode:      codes.Unavailable,
+			wantErrSubstr: "webhook responded with 503 Service Unavailable: fake failure",
+		},
+		{
+			name:          "non_retryable_status",
+			statusCodes:   []int{http.StatusBadRequest},
+			wantCode:      codes.InvalidArgument,
+			wantErrSubstr: "webhook responded with 400 Bad Request",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeWebhook{statusCodes: tc.statusCodes}
+			secret := []byte("test-secret")
+			now := time.Unix(1700000000, 0)
+			p := newTestProcessor(t, f,
+				WithHeaders(map[string]string{"Authorization": "Bearer token"}),
+				WithHMACSecret(secret))
+			p.now = func() time.Time { return now }
+
+			err := p.Process(t.Context(), testutil.NewRequest())
+			if diff := pkgtestutil.DiffErrString(err, tc.wantErrSubstr); diff != "" {
+				t.Errorf("Process() got unexpected error: %s", diff)
+			}
+			if err != nil {
+				if got := status.Code(err); got != tc.wantCode {
+					t.Errorf("Process() got code %v, want %v", got, tc.wantCode)
+				}
+			}
+
+			if got, want := f.batchSizes(), []int{1}; !cmp.Equal(got, want) {
+				t.Fatalf("webhook got batch sizes %v, want %v", got, want)
+			}
+			if err := validation.Validate(string(f.batches[0][0])); err != nil {
+				t.Errorf("webhook got invalid log entry %s: %v", f.batches[0][0], err)
+			}
+
+			h := f.headers[0]
+			wantHeaders := map[string]string{
+				"Authorization": "Bearer token",
+				"Content-Type":  "application/json",
+				TimestampHeader: "1700000000",
+				SignatureHeader: Sign(secret, "1700000000", f.bodies[0]),
+			}
+			for k, want := range wantHeaders {
+				if got := h.Get(k); got != want {
+					t.Errorf("webhook got header %s=%q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessor_Batching(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full_batch", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeWebhook{}
+		p := newTestProcessor(t, f, WithBatching(3, time.Hour))
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := p.Process(t.Context(), testutil.NewRequest()); err != nil {
+					t.Errorf("Process() unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, want := f.batchSizes(), []int{3}; !cmp.Equal(got, want) {
+			t.Errorf("webhook got batch sizes %v, want %v", got, want)
+		}
+	})
+
+	t.Run("batch_delay", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeWebhook{statusCodes: []int{http.StatusTooManyRequests}}
+		p := newTestProcessor(t, f, WithBatching(10, 10*time.Millisecond))
+
+		err := p.Process(t.Context(), testutil.NewRequest())
+		if got, want := status.Code(err), codes.ResourceExhausted; got != want {
+			t.Errorf("Process() got code %v, want %v", got, want)
+		}
+		if got, want := f.batchSizes(), []int{1}; !cmp.Equal(got, want) {
+			t.Errorf("webhook got batch sizes %v, want %v", got, want)
+		}
+	})
+
+	t.Run("stop_sends_pending_batch", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeWebhook{}
+		p := newTestProcessor(t, f, WithBatching(10, time.Hour))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- p.Process(t.Context(), testutil.NewRequest())
+		}()
+		for {
+			if p.batcher.Len() == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := p.Stop(); err != nil {
+			t.Fatalf("Stop() unexpected error: %v", err)
+		}
+		if err := <-errCh; err != nil {
+			t.Errorf("Process() unexpected error: %v", err)
+		}
+		if got, want := f.batchSizes(), []int{1}; !cmp.Equal(got, want) {
+			t.Errorf("webhook got batch sizes %v, want %v", got, want)
+		}
+		if err := p.Process(t.Context(), testutil.NewRequest()); err == nil {
+			t.Errorf("Process() after Stop() got no error")
+		}
+	})
+	t.Run("cancel_removes_pending_log", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeWebhook{}
+		p := newTestProcessor(t, f, WithBatching(10, time.Hour))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- p.Process(ctx, testutil.NewRequest())
+		}()
+		for {
+			if p.batcher.Len() == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Errorf("Process() got error %v, want %v", err, context.Canceled)
+		}
+		if err := p.Stop(); err != nil {
+			t.Fatalf("Stop() unexpected error: %v", err)
+		}
+		// The cancelled log request is not delivered.
+		if got := f.batchSizes(); len(got) != 0 {
+			t.Errorf("webhook got batch sizes %v, want none", got)
+		}
+	})
+}
+
+func TestProcessor_ProcessBatch(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeWebhook{statusCodes: []int{http.StatusOK, http.StatusBadGateway}}
+	p := newTestProcessor(t, f, WithBatching(2, time.Hour))
+
+	logReqs := make([]*api.AuditLogRequest, 5)
+	for i := range logReqs {
+		logReqs[i] = testutil.NewRequest()
+	}
+	err := p.ProcessBatch(t.Context(), logReqs)
+
+	var batchErr *audit.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("ProcessBatch() got error %v, want a *audit.BatchError", err)
+	}
+	var gotCodes []codes.Code
+	for _, err := range batchErr.Errs {
+		gotCodes = append(gotCodes, status.Code(err))
+	}
+	wantCodes := []codes.Code{codes.OK, codes.OK, codes.Unavailable, codes.Unavailable, codes.OK}
+	if diff := cmp.Diff(wantCodes, gotCodes); diff != "" {
+		t.Errorf("ProcessBatch() got codes diff (-want, +got): %v", diff)
+	}
+	if got, want := f.batchSizes(), []int{2, 2, 1}; !cmp.Equal(got, want) {
+		t.Errorf("webhook got batch sizes %v, want %v", got, want)
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		status int
+		want   codes.Code
+	}{
+		{status: http.StatusBadRequest, want: codes.InvalidArgument},
+		{status: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{status: http.StatusForbidden, want: codes.PermissionDenied},
+		{status: http.StatusNotFound, want: codes.NotFound},
+		{status: http.StatusRequestTimeout, want: codes.DeadlineExceeded},
+		{status: http.StatusConflict, want: codes.Aborted},
+		{status: http.StatusRequestEntityTooLarge, want: codes.InvalidArgument},
+		{status: http.StatusTooManyRequests, want: codes.ResourceExhausted},
+		{status: http.StatusInternalServerError, want: codes.Unavailable},
+		{status: http.StatusNotImplemented, want: codes.Unimplemented},
+		{status: http.StatusBadGateway, want: codes.Unavailable},
+		{status: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{status: http.StatusGatewayTimeout, want: codes.DeadlineExceeded},
+		{status: http.StatusFound, want: codes.Unknown},
+	}
+
+	for _, tc := range cases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			t.Parallel()
+
+			if got := CodeFromHTTPStatus(tc.status); got != tc.want {
+				t.Errorf("CodeFromHTTPStatus(%d) got %v, want %v", tc.status, got, tc.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	cloudloggingsdk "cloud.google.com/go/logging"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -30,6 +31,7 @@ import (
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/cloudlogging"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/metrics"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/trace"
 	"github.com/abcxyz/lumberjack/internal/version"
 	"github.com/abcxyz/lumberjack/pkg/server"
@@ -78,6 +80,19 @@ func realMain(ctx context.Context) (retErr error) {
 		}
 	}()
 
+	// The audit client and the gRPC stats handler record metrics with the
+	// global meter provider.
+	if cfg.MetricsExportInterval > 0 {
+		if err := metrics.Init(cfg.MetricsExportInterval); err != nil {
+			return fmt.Errorf("failed to init metrics: %w", err)
+		}
+		defer func() {
+			if err := metrics.Shutdown(); err != nil {
+				retErr = errors.Join(retErr, fmt.Errorf("failed to shutdown metrics: %w", err))
+			}
+		}()
+	}
+
 	// Set up other log processors as we add more.
 	// TODO(b/202328178): Allow setting other log processor(s) via config.
 	// E.g. We can have a stdout log processor that write audit logs to stdout.
@@ -90,7 +105,11 @@ func realMain(ctx context.Context) (retErr error) {
 		return fmt.Errorf("failed to create audit client: %w", err)
 	}
 	defer func() {
-		if err := client.Stop(); err != nil {
+		// Bound the flush of the buffered logs, so that a hung backend doesn't
+		// block the termination.
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := client.StopContext(stopCtx); err != nil {
 			retErr = errors.Join(retErr, fmt.Errorf("failed to stop audit client: %w", err))
 		}
 	}()
@@ -17,6 +17,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sethvargo/go-envconfig"
 )
@@ -28,6 +29,10 @@ type Config struct {
 	// If trace ratio is >= 1 will trace all requests.
 	// If trace ratio <= 0 will not trace at all.
 	TraceRatio float64 `env:"TRACE_RATIO, default=0.001"`
+
+	// MetricsExportInterval is how often metrics are exported. If zero or
+	// negative, metrics are not exported.
+	MetricsExportInterval time.Duration `env:"METRICS_EXPORT_INTERVAL, default=60s"`
 }
 
 // NewConfig initializes a server config from environment vars.
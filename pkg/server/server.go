@@ -21,10 +21,14 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/auditerrors"
+	"github.com/abcxyz/lumberjack/clients/go/pkg/diagnostics"
 )
 
 // AuditLogAgent is the implementation of the audit log agent server.
@@ -39,17 +43,74 @@ func NewAuditLogAgent(client *audit.Client) (*AuditLogAgent, error) {
 	return &AuditLogAgent{client: client}, nil
 }
 
-// ProcessLog processes the log requests by calling the internal client.
+// ProcessLog processes the log requests by calling the internal client. The
+// response masks the fields that the client changed, and holds the
+// diagnostics that its processors reported.
 func (a *AuditLogAgent) ProcessLog(ctx context.Context, logReq *api.AuditLogRequest) (*api.AuditLogResponse, error) {
+	ctx, diags := diagnostics.NewContext(ctx)
+	orig := proto.Clone(logReq)
 	if err := a.client.Log(ctx, logReq); err != nil {
 		return nil, codifyErr(err)
 	}
 
 	return &api.AuditLogResponse{
-		Result: logReq,
+		Result:      logReq,
+		UpdateMask:  updateMask(orig, logReq),
+		Diagnostics: diags.Get(logReq),
 	}, nil
 }
 
+// ProcessLogs processes the batch of log requests by calling the internal
+// client, and returns the result of each log request.
+func (a *AuditLogAgent) ProcessLogs(ctx context.Context, req *api.ProcessLogsRequest) (*api.ProcessLogsResponse, error) {
+	ctx, diags := diagnostics.NewContext(ctx)
+	logReqs := req.GetRequests()
+	origs := make([]proto.Message, len(logReqs))
+	for i, logReq := range logReqs {
+		origs[i] = proto.Clone(logReq)
+	}
+	errs := a.client.LogBatch(ctx, logReqs)
+
+	results := make([]*api.ProcessLogsResult, len(logReqs))
+	for i, logReq := range logReqs {
+		results[i] = &api.ProcessLogsResult{Diagnostics: diags.Get(logReq)}
+		if errs[i] != nil {
+			results[i].Status = status.Convert(codifyErr(errs[i])).Proto()
+			continue
+		}
+		results[i].Result = logReq
+		results[i].UpdateMask = updateMask(origs[i], logReq)
+	}
+	return &api.ProcessLogsResponse{Results: results}, nil
+}
+
+// updateMask returns the mask of the top-level fields of the log request that
+// differ from the original.
+func updateMask(orig proto.Message, logReq *api.AuditLogRequest) *fieldmaskpb.FieldMask {
+	o, m := orig.ProtoReflect(), logReq.ProtoReflect()
+	mask := &fieldmaskpb.FieldMask{}
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !fieldEqual(o, m, fd) {
+			mask.Paths = append(mask.Paths, string(fd.Name()))
+		}
+	}
+	return mask
+}
+
+// fieldEqual reports whether the field is equal in both messages.
+func fieldEqual(a, b protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	x, y := a.Type().New(), b.Type().New()
+	if a.Has(fd) {
+		x.Set(fd, a.Get(fd))
+	}
+	if b.Has(fd) {
+		y.Set(fd, b.Get(fd))
+	}
+	return proto.Equal(x.Interface(), y.Interface())
+}
+
 func codifyErr(err error) error {
 	if errors.Is(err, auditerrors.ErrInvalidRequest) {
 		return status.Error(codes.InvalidArgument, err.Error())
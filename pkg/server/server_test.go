@@ -22,12 +22,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	api "github.com/abcxyz/lumberjack/clients/go/apis/v1alpha1"
 	"github.com/abcxyz/lumberjack/clients/go/pkg/audit"
@@ -65,6 +68,7 @@ func TestAuditLogAgent_ProcessLog(t *testing.T) {
 		name        string
 		req         *api.AuditLogRequest
 		p           *fakeLogProcessor
+		tracing     bool
 		wantSentReq *api.AuditLogRequest
 		wantResp    *api.AuditLogResponse
 		wantErr     error
@@ -74,7 +78,8 @@ func TestAuditLogAgent_ProcessLog(t *testing.T) {
 		p:           &fakeLogProcessor{},
 		wantSentReq: testutil.NewRequest(testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
 		wantResp: &api.AuditLogResponse{
-			Result: testutil.NewRequest(testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+			Result:     testutil.NewRequest(testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mode"}},
 		},
 	}, {
 		name: "success_with_update",
@@ -84,7 +89,19 @@ func TestAuditLogAgent_ProcessLog(t *testing.T) {
 		},
 		wantSentReq: testutil.NewRequest(testutil.WithServiceName("test-service"), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
 		wantResp: &api.AuditLogResponse{
-			Result: testutil.NewRequest(testutil.WithServiceName("bar"), testutil.WithMethodName("Do"), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+			Result:     testutil.NewRequest(testutil.WithServiceName("bar"), testutil.WithMethodName("Do"), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"payload", "mode"}},
+		},
+	}, {
+		// The agent's own span must not replace the trace of the client.
+		name:        "success_preserves_trace",
+		req:         testutil.NewRequest(testutil.WithTrace("06796866738c859f2f19b7cfb3214824", "00f067aa0ba902b7", true)),
+		p:           &fakeLogProcessor{},
+		tracing:     true,
+		wantSentReq: testutil.NewRequest(testutil.WithTrace("06796866738c859f2f19b7cfb3214824", "00f067aa0ba902b7", true), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+		wantResp: &api.AuditLogResponse{
+			Result:     testutil.NewRequest(testutil.WithTrace("06796866738c859f2f19b7cfb3214824", "00f067aa0ba902b7", true), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mode"}},
 		},
 	}, {
 		name: "internal_failure",
@@ -110,9 +127,13 @@ func TestAuditLogAgent_ProcessLog(t *testing.T) {
 		},
 		wantSentReq: testutil.NewRequest(testutil.WithServiceName("test-service"), testutil.WithMode(api.AuditLogRequest_BEST_EFFORT)),
 		wantResp: &api.AuditLogResponse{
-			// TODO: We want to swallow errors in the client on best effort, but that means the server will return
-			// with this output. Do we want any indication in the response that the audit log hasn't occurred?
-			Result: testutil.NewRequest(testutil.WithServiceName("test-service"), testutil.WithMode(api.AuditLogRequest_BEST_EFFORT)),
+			// The error is swallowed on best effort, and reported as a warning.
+			Result:     testutil.NewRequest(testutil.WithServiceName("test-service"), testutil.WithMode(api.AuditLogRequest_BEST_EFFORT)),
+			UpdateMask: &fieldmaskpb.FieldMask{},
+			Diagnostics: []*api.Diagnostic{{
+				Severity: api.Diagnostic_WARNING,
+				Message:  "failed to audit log; continuing without audit logging: failed to execute backend *server.fakeLogProcessor: injected: invalid audit log request",
+			}},
 		},
 	}}
 
@@ -120,7 +141,12 @@ func TestAuditLogAgent_ProcessLog(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			s := grpc.NewServer()
+			var serverOpts []grpc.ServerOption
+			if tc.tracing {
+				serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler(
+					otelgrpc.WithTracerProvider(sdktrace.NewTracerProvider()))))
+			}
+			s := grpc.NewServer(serverOpts...)
 			defer s.Stop()
 
 			ac, err := audit.NewClient(ctx,
@@ -167,3 +193,62 @@ func TestAuditLogAgent_ProcessLog(t *testing.T) {
 		})
 	}
 }
+
+// methodLogProcessor fails the log requests whose method is in failMethods.
+type methodLogProcessor struct {
+	failMethods map[string]error
+}
+
+func (p *methodLogProcessor) Process(_ context.Context, logReq *api.AuditLogRequest) error {
+	return p.failMethods[logReq.GetPayload().GetMethodName()]
+}
+
+func TestAuditLogAgent_ProcessLogs(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.WithLogger(t.Context(), logging.TestLogger(t))
+
+	ac, err := audit.NewClient(ctx,
+		audit.WithBackend(&methodLogProcessor{failMethods: map[string]error{
+			"invalid":  fmt.Errorf("injected: %w", auditerrors.ErrInvalidRequest),
+			"internal": fmt.Errorf("injected err"),
+		}}),
+		audit.WithLogMode(api.AuditLogRequest_FAIL_CLOSE),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create audit client: %v", err)
+	}
+	server, err := NewAuditLogAgent(ac)
+	if err != nil {
+		t.Fatalf("Failed to create audit log agent server: %v", err)
+	}
+	_, conn := testutil.TestFakeGRPCServer(t, func(s *grpc.Server) {
+		api.RegisterAuditLogAgentServer(s, server)
+	})
+
+	client := api.NewAuditLogAgentClient(conn)
+	gotResp, err := client.ProcessLogs(t.Context(), &api.ProcessLogsRequest{
+		Requests: []*api.AuditLogRequest{
+			testutil.NewRequest(testutil.WithMethodName("ok")),
+			testutil.NewRequest(testutil.WithMethodName("invalid")),
+			testutil.NewRequest(testutil.WithMethodName("internal")),
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessLogs() unexpected error: %v", err)
+	}
+
+	wantResp := &api.ProcessLogsResponse{
+		Results: []*api.ProcessLogsResult{
+			{
+				Result:     testutil.NewRequest(testutil.WithMethodName("ok"), testutil.WithMode(api.AuditLogRequest_FAIL_CLOSE)),
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mode"}},
+			},
+			{Status: status.New(codes.InvalidArgument, "failed to execute backend *server.methodLogProcessor: injected: invalid audit log request").Proto()},
+			{Status: status.New(codes.Internal, "failed to execute backend *server.methodLogProcessor: injected err").Proto()},
+		},
+	}
+	if diff := cmp.Diff(wantResp, gotResp, protocmp.Transform()); diff != "" {
+		t.Errorf("ProcessLogs() response (-want,+got):\n%s", diff)
+	}
+}